@@ -0,0 +1,10 @@
+// Package api embeds the OpenAPI document and the documentation page.
+package api
+
+import _ "embed"
+
+//go:embed openapi.json
+var OpenAPISpec []byte
+
+//go:embed docs.html
+var DocsPage []byte
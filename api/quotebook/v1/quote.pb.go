@@ -0,0 +1,859 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: quotebook/v1/quote.proto
+
+package quotebookv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type QuoteEvent_Type int32
+
+const (
+	QuoteEvent_TYPE_UNSPECIFIED QuoteEvent_Type = 0
+	QuoteEvent_TYPE_CREATED     QuoteEvent_Type = 1
+	QuoteEvent_TYPE_DELETED     QuoteEvent_Type = 2
+	QuoteEvent_TYPE_UPDATED     QuoteEvent_Type = 3
+	// The quote was removed to stay within the storage limit.
+	QuoteEvent_TYPE_EVICTED QuoteEvent_Type = 4
+	// The publication window of a scheduled quote started or ended, expired
+	// quotes are removed.
+	QuoteEvent_TYPE_PUBLISHED QuoteEvent_Type = 5
+	QuoteEvent_TYPE_EXPIRED   QuoteEvent_Type = 6
+)
+
+// Enum value maps for QuoteEvent_Type.
+var (
+	QuoteEvent_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_CREATED",
+		2: "TYPE_DELETED",
+		3: "TYPE_UPDATED",
+		4: "TYPE_EVICTED",
+		5: "TYPE_PUBLISHED",
+		6: "TYPE_EXPIRED",
+	}
+	QuoteEvent_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_CREATED":     1,
+		"TYPE_DELETED":     2,
+		"TYPE_UPDATED":     3,
+		"TYPE_EVICTED":     4,
+		"TYPE_PUBLISHED":   5,
+		"TYPE_EXPIRED":     6,
+	}
+)
+
+func (x QuoteEvent_Type) Enum() *QuoteEvent_Type {
+	p := new(QuoteEvent_Type)
+	*p = x
+	return p
+}
+
+func (x QuoteEvent_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QuoteEvent_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_quotebook_v1_quote_proto_enumTypes[0].Descriptor()
+}
+
+func (QuoteEvent_Type) Type() protoreflect.EnumType {
+	return &file_quotebook_v1_quote_proto_enumTypes[0]
+}
+
+func (x QuoteEvent_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QuoteEvent_Type.Descriptor instead.
+func (QuoteEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return file_quotebook_v1_quote_proto_rawDescGZIP(), []int{10, 0}
+}
+
+type Quote struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Author string                 `protobuf:"bytes,2,opt,name=author,proto3" json:"author,omitempty"`
+	Quote  string                 `protobuf:"bytes,3,opt,name=quote,proto3" json:"quote,omitempty"`
+	Tags   []string               `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	// ISO 639-1 language code, empty when unknown.
+	Lang string `protobuf:"bytes,5,opt,name=lang,proto3" json:"lang,omitempty"`
+	// Publication window, unset bounds are open.
+	PublishAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=publish_at,json=publishAt,proto3" json:"publish_at,omitempty"`
+	ExpireAt      *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=expire_at,json=expireAt,proto3" json:"expire_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Quote) Reset() {
+	*x = Quote{}
+	mi := &file_quotebook_v1_quote_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Quote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Quote) ProtoMessage() {}
+
+func (x *Quote) ProtoReflect() protoreflect.Message {
+	mi := &file_quotebook_v1_quote_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Quote.ProtoReflect.Descriptor instead.
+func (*Quote) Descriptor() ([]byte, []int) {
+	return file_quotebook_v1_quote_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Quote) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Quote) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *Quote) GetQuote() string {
+	if x != nil {
+		return x.Quote
+	}
+	return ""
+}
+
+func (x *Quote) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Quote) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *Quote) GetPublishAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishAt
+	}
+	return nil
+}
+
+func (x *Quote) GetExpireAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpireAt
+	}
+	return nil
+}
+
+type CreateQuoteRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Author string                 `protobuf:"bytes,1,opt,name=author,proto3" json:"author,omitempty"`
+	Quote  string                 `protobuf:"bytes,2,opt,name=quote,proto3" json:"quote,omitempty"`
+	Tags   []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	// ISO 639-1 language code, detected from the quote when empty.
+	Lang string `protobuf:"bytes,4,opt,name=lang,proto3" json:"lang,omitempty"`
+	// The quote is served from publish_at until expire_at, either may be
+	// left unset.
+	PublishAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=publish_at,json=publishAt,proto3" json:"publish_at,omitempty"`
+	ExpireAt      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=expire_at,json=expireAt,proto3" json:"expire_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateQuoteRequest) Reset() {
+	*x = CreateQuoteRequest{}
+	mi := &file_quotebook_v1_quote_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateQuoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateQuoteRequest) ProtoMessage() {}
+
+func (x *CreateQuoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotebook_v1_quote_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateQuoteRequest.ProtoReflect.Descriptor instead.
+func (*CreateQuoteRequest) Descriptor() ([]byte, []int) {
+	return file_quotebook_v1_quote_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateQuoteRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *CreateQuoteRequest) GetQuote() string {
+	if x != nil {
+		return x.Quote
+	}
+	return ""
+}
+
+func (x *CreateQuoteRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *CreateQuoteRequest) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *CreateQuoteRequest) GetPublishAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishAt
+	}
+	return nil
+}
+
+func (x *CreateQuoteRequest) GetExpireAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpireAt
+	}
+	return nil
+}
+
+type GetQuoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuoteRequest) Reset() {
+	*x = GetQuoteRequest{}
+	mi := &file_quotebook_v1_quote_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuoteRequest) ProtoMessage() {}
+
+func (x *GetQuoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotebook_v1_quote_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuoteRequest.ProtoReflect.Descriptor instead.
+func (*GetQuoteRequest) Descriptor() ([]byte, []int) {
+	return file_quotebook_v1_quote_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetQuoteRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListQuotesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Maximum number of quotes to return, the server caps it at 1000.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Opaque token from a previous ListQuotesResponse.
+	PageToken     string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListQuotesRequest) Reset() {
+	*x = ListQuotesRequest{}
+	mi := &file_quotebook_v1_quote_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListQuotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListQuotesRequest) ProtoMessage() {}
+
+func (x *ListQuotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotebook_v1_quote_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListQuotesRequest.ProtoReflect.Descriptor instead.
+func (*ListQuotesRequest) Descriptor() ([]byte, []int) {
+	return file_quotebook_v1_quote_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListQuotesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListQuotesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListQuotesResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Quotes []*Quote               `protobuf:"bytes,1,rep,name=quotes,proto3" json:"quotes,omitempty"`
+	// Empty when there are no more pages.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListQuotesResponse) Reset() {
+	*x = ListQuotesResponse{}
+	mi := &file_quotebook_v1_quote_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListQuotesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListQuotesResponse) ProtoMessage() {}
+
+func (x *ListQuotesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_quotebook_v1_quote_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListQuotesResponse.ProtoReflect.Descriptor instead.
+func (*ListQuotesResponse) Descriptor() ([]byte, []int) {
+	return file_quotebook_v1_quote_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListQuotesResponse) GetQuotes() []*Quote {
+	if x != nil {
+		return x.Quotes
+	}
+	return nil
+}
+
+func (x *ListQuotesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type GetRandomQuoteRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only quotes in this ISO 639-1 language when set.
+	Lang          string `protobuf:"bytes,1,opt,name=lang,proto3" json:"lang,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRandomQuoteRequest) Reset() {
+	*x = GetRandomQuoteRequest{}
+	mi := &file_quotebook_v1_quote_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRandomQuoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRandomQuoteRequest) ProtoMessage() {}
+
+func (x *GetRandomQuoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotebook_v1_quote_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRandomQuoteRequest.ProtoReflect.Descriptor instead.
+func (*GetRandomQuoteRequest) Descriptor() ([]byte, []int) {
+	return file_quotebook_v1_quote_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetRandomQuoteRequest) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+type ListQuotesByAuthorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Author        string                 `protobuf:"bytes,1,opt,name=author,proto3" json:"author,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListQuotesByAuthorRequest) Reset() {
+	*x = ListQuotesByAuthorRequest{}
+	mi := &file_quotebook_v1_quote_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListQuotesByAuthorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListQuotesByAuthorRequest) ProtoMessage() {}
+
+func (x *ListQuotesByAuthorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotebook_v1_quote_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListQuotesByAuthorRequest.ProtoReflect.Descriptor instead.
+func (*ListQuotesByAuthorRequest) Descriptor() ([]byte, []int) {
+	return file_quotebook_v1_quote_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListQuotesByAuthorRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+type DeleteQuoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteQuoteRequest) Reset() {
+	*x = DeleteQuoteRequest{}
+	mi := &file_quotebook_v1_quote_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteQuoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteQuoteRequest) ProtoMessage() {}
+
+func (x *DeleteQuoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotebook_v1_quote_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteQuoteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteQuoteRequest) Descriptor() ([]byte, []int) {
+	return file_quotebook_v1_quote_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteQuoteRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteQuoteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteQuoteResponse) Reset() {
+	*x = DeleteQuoteResponse{}
+	mi := &file_quotebook_v1_quote_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteQuoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteQuoteResponse) ProtoMessage() {}
+
+func (x *DeleteQuoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_quotebook_v1_quote_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteQuoteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteQuoteResponse) Descriptor() ([]byte, []int) {
+	return file_quotebook_v1_quote_proto_rawDescGZIP(), []int{8}
+}
+
+type WatchQuotesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only stream events for this author (case-insensitive) when set.
+	Author        string `protobuf:"bytes,1,opt,name=author,proto3" json:"author,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchQuotesRequest) Reset() {
+	*x = WatchQuotesRequest{}
+	mi := &file_quotebook_v1_quote_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchQuotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchQuotesRequest) ProtoMessage() {}
+
+func (x *WatchQuotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotebook_v1_quote_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchQuotesRequest.ProtoReflect.Descriptor instead.
+func (*WatchQuotesRequest) Descriptor() ([]byte, []int) {
+	return file_quotebook_v1_quote_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WatchQuotesRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+type QuoteEvent struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type    QuoteEvent_Type        `protobuf:"varint,2,opt,name=type,proto3,enum=quotebook.v1.QuoteEvent_Type" json:"type,omitempty"`
+	QuoteId int64                  `protobuf:"varint,3,opt,name=quote_id,json=quoteId,proto3" json:"quote_id,omitempty"`
+	// State of the quote after the change, before it for deleted quotes.
+	Quote         *Quote                 `protobuf:"bytes,4,opt,name=quote,proto3" json:"quote,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuoteEvent) Reset() {
+	*x = QuoteEvent{}
+	mi := &file_quotebook_v1_quote_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuoteEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuoteEvent) ProtoMessage() {}
+
+func (x *QuoteEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_quotebook_v1_quote_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuoteEvent.ProtoReflect.Descriptor instead.
+func (*QuoteEvent) Descriptor() ([]byte, []int) {
+	return file_quotebook_v1_quote_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *QuoteEvent) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *QuoteEvent) GetType() QuoteEvent_Type {
+	if x != nil {
+		return x.Type
+	}
+	return QuoteEvent_TYPE_UNSPECIFIED
+}
+
+func (x *QuoteEvent) GetQuoteId() int64 {
+	if x != nil {
+		return x.QuoteId
+	}
+	return 0
+}
+
+func (x *QuoteEvent) GetQuote() *Quote {
+	if x != nil {
+		return x.Quote
+	}
+	return nil
+}
+
+func (x *QuoteEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+var File_quotebook_v1_quote_proto protoreflect.FileDescriptor
+
+const file_quotebook_v1_quote_proto_rawDesc = "" +
+	"\n" +
+	"\x18quotebook/v1/quote.proto\x12\fquotebook.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xe1\x01\n" +
+	"\x05Quote\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x16\n" +
+	"\x06author\x18\x02 \x01(\tR\x06author\x12\x14\n" +
+	"\x05quote\x18\x03 \x01(\tR\x05quote\x12\x12\n" +
+	"\x04tags\x18\x04 \x03(\tR\x04tags\x12\x12\n" +
+	"\x04lang\x18\x05 \x01(\tR\x04lang\x129\n" +
+	"\n" +
+	"publish_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tpublishAt\x127\n" +
+	"\texpire_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\bexpireAt\"\xde\x01\n" +
+	"\x12CreateQuoteRequest\x12\x16\n" +
+	"\x06author\x18\x01 \x01(\tR\x06author\x12\x14\n" +
+	"\x05quote\x18\x02 \x01(\tR\x05quote\x12\x12\n" +
+	"\x04tags\x18\x03 \x03(\tR\x04tags\x12\x12\n" +
+	"\x04lang\x18\x04 \x01(\tR\x04lang\x129\n" +
+	"\n" +
+	"publish_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tpublishAt\x127\n" +
+	"\texpire_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\bexpireAt\"!\n" +
+	"\x0fGetQuoteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"O\n" +
+	"\x11ListQuotesRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\"i\n" +
+	"\x12ListQuotesResponse\x12+\n" +
+	"\x06quotes\x18\x01 \x03(\v2\x13.quotebook.v1.QuoteR\x06quotes\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"+\n" +
+	"\x15GetRandomQuoteRequest\x12\x12\n" +
+	"\x04lang\x18\x01 \x01(\tR\x04lang\"3\n" +
+	"\x19ListQuotesByAuthorRequest\x12\x16\n" +
+	"\x06author\x18\x01 \x01(\tR\x06author\"$\n" +
+	"\x12DeleteQuoteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x15\n" +
+	"\x13DeleteQuoteResponse\",\n" +
+	"\x12WatchQuotesRequest\x12\x16\n" +
+	"\x06author\x18\x01 \x01(\tR\x06author\"\xd2\x02\n" +
+	"\n" +
+	"QuoteEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x121\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x1d.quotebook.v1.QuoteEvent.TypeR\x04type\x12\x19\n" +
+	"\bquote_id\x18\x03 \x01(\x03R\aquoteId\x12)\n" +
+	"\x05quote\x18\x04 \x01(\v2\x13.quotebook.v1.QuoteR\x05quote\x12.\n" +
+	"\x04time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"\x8a\x01\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fTYPE_CREATED\x10\x01\x12\x10\n" +
+	"\fTYPE_DELETED\x10\x02\x12\x10\n" +
+	"\fTYPE_UPDATED\x10\x03\x12\x10\n" +
+	"\fTYPE_EVICTED\x10\x04\x12\x12\n" +
+	"\x0eTYPE_PUBLISHED\x10\x05\x12\x10\n" +
+	"\fTYPE_EXPIRED\x10\x062\xb3\x04\n" +
+	"\fQuoteService\x12D\n" +
+	"\vCreateQuote\x12 .quotebook.v1.CreateQuoteRequest\x1a\x13.quotebook.v1.Quote\x12>\n" +
+	"\bGetQuote\x12\x1d.quotebook.v1.GetQuoteRequest\x1a\x13.quotebook.v1.Quote\x12O\n" +
+	"\n" +
+	"ListQuotes\x12\x1f.quotebook.v1.ListQuotesRequest\x1a .quotebook.v1.ListQuotesResponse\x12J\n" +
+	"\x0eGetRandomQuote\x12#.quotebook.v1.GetRandomQuoteRequest\x1a\x13.quotebook.v1.Quote\x12_\n" +
+	"\x12ListQuotesByAuthor\x12'.quotebook.v1.ListQuotesByAuthorRequest\x1a .quotebook.v1.ListQuotesResponse\x12R\n" +
+	"\vDeleteQuote\x12 .quotebook.v1.DeleteQuoteRequest\x1a!.quotebook.v1.DeleteQuoteResponse\x12K\n" +
+	"\vWatchQuotes\x12 .quotebook.v1.WatchQuotesRequest\x1a\x18.quotebook.v1.QuoteEvent0\x01BJZHgithub.com/zonder12120/brandscout-quotebook/api/quotebook/v1;quotebookv1b\x06proto3"
+
+var (
+	file_quotebook_v1_quote_proto_rawDescOnce sync.Once
+	file_quotebook_v1_quote_proto_rawDescData []byte
+)
+
+func file_quotebook_v1_quote_proto_rawDescGZIP() []byte {
+	file_quotebook_v1_quote_proto_rawDescOnce.Do(func() {
+		file_quotebook_v1_quote_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_quotebook_v1_quote_proto_rawDesc), len(file_quotebook_v1_quote_proto_rawDesc)))
+	})
+	return file_quotebook_v1_quote_proto_rawDescData
+}
+
+var file_quotebook_v1_quote_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_quotebook_v1_quote_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_quotebook_v1_quote_proto_goTypes = []any{
+	(QuoteEvent_Type)(0),              // 0: quotebook.v1.QuoteEvent.Type
+	(*Quote)(nil),                     // 1: quotebook.v1.Quote
+	(*CreateQuoteRequest)(nil),        // 2: quotebook.v1.CreateQuoteRequest
+	(*GetQuoteRequest)(nil),           // 3: quotebook.v1.GetQuoteRequest
+	(*ListQuotesRequest)(nil),         // 4: quotebook.v1.ListQuotesRequest
+	(*ListQuotesResponse)(nil),        // 5: quotebook.v1.ListQuotesResponse
+	(*GetRandomQuoteRequest)(nil),     // 6: quotebook.v1.GetRandomQuoteRequest
+	(*ListQuotesByAuthorRequest)(nil), // 7: quotebook.v1.ListQuotesByAuthorRequest
+	(*DeleteQuoteRequest)(nil),        // 8: quotebook.v1.DeleteQuoteRequest
+	(*DeleteQuoteResponse)(nil),       // 9: quotebook.v1.DeleteQuoteResponse
+	(*WatchQuotesRequest)(nil),        // 10: quotebook.v1.WatchQuotesRequest
+	(*QuoteEvent)(nil),                // 11: quotebook.v1.QuoteEvent
+	(*timestamppb.Timestamp)(nil),     // 12: google.protobuf.Timestamp
+}
+var file_quotebook_v1_quote_proto_depIdxs = []int32{
+	12, // 0: quotebook.v1.Quote.publish_at:type_name -> google.protobuf.Timestamp
+	12, // 1: quotebook.v1.Quote.expire_at:type_name -> google.protobuf.Timestamp
+	12, // 2: quotebook.v1.CreateQuoteRequest.publish_at:type_name -> google.protobuf.Timestamp
+	12, // 3: quotebook.v1.CreateQuoteRequest.expire_at:type_name -> google.protobuf.Timestamp
+	1,  // 4: quotebook.v1.ListQuotesResponse.quotes:type_name -> quotebook.v1.Quote
+	0,  // 5: quotebook.v1.QuoteEvent.type:type_name -> quotebook.v1.QuoteEvent.Type
+	1,  // 6: quotebook.v1.QuoteEvent.quote:type_name -> quotebook.v1.Quote
+	12, // 7: quotebook.v1.QuoteEvent.time:type_name -> google.protobuf.Timestamp
+	2,  // 8: quotebook.v1.QuoteService.CreateQuote:input_type -> quotebook.v1.CreateQuoteRequest
+	3,  // 9: quotebook.v1.QuoteService.GetQuote:input_type -> quotebook.v1.GetQuoteRequest
+	4,  // 10: quotebook.v1.QuoteService.ListQuotes:input_type -> quotebook.v1.ListQuotesRequest
+	6,  // 11: quotebook.v1.QuoteService.GetRandomQuote:input_type -> quotebook.v1.GetRandomQuoteRequest
+	7,  // 12: quotebook.v1.QuoteService.ListQuotesByAuthor:input_type -> quotebook.v1.ListQuotesByAuthorRequest
+	8,  // 13: quotebook.v1.QuoteService.DeleteQuote:input_type -> quotebook.v1.DeleteQuoteRequest
+	10, // 14: quotebook.v1.QuoteService.WatchQuotes:input_type -> quotebook.v1.WatchQuotesRequest
+	1,  // 15: quotebook.v1.QuoteService.CreateQuote:output_type -> quotebook.v1.Quote
+	1,  // 16: quotebook.v1.QuoteService.GetQuote:output_type -> quotebook.v1.Quote
+	5,  // 17: quotebook.v1.QuoteService.ListQuotes:output_type -> quotebook.v1.ListQuotesResponse
+	1,  // 18: quotebook.v1.QuoteService.GetRandomQuote:output_type -> quotebook.v1.Quote
+	5,  // 19: quotebook.v1.QuoteService.ListQuotesByAuthor:output_type -> quotebook.v1.ListQuotesResponse
+	9,  // 20: quotebook.v1.QuoteService.DeleteQuote:output_type -> quotebook.v1.DeleteQuoteResponse
+	11, // 21: quotebook.v1.QuoteService.WatchQuotes:output_type -> quotebook.v1.QuoteEvent
+	15, // [15:22] is the sub-list for method output_type
+	8,  // [8:15] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_quotebook_v1_quote_proto_init() }
+func file_quotebook_v1_quote_proto_init() {
+	if File_quotebook_v1_quote_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_quotebook_v1_quote_proto_rawDesc), len(file_quotebook_v1_quote_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_quotebook_v1_quote_proto_goTypes,
+		DependencyIndexes: file_quotebook_v1_quote_proto_depIdxs,
+		EnumInfos:         file_quotebook_v1_quote_proto_enumTypes,
+		MessageInfos:      file_quotebook_v1_quote_proto_msgTypes,
+	}.Build()
+	File_quotebook_v1_quote_proto = out.File
+	file_quotebook_v1_quote_proto_goTypes = nil
+	file_quotebook_v1_quote_proto_depIdxs = nil
+}
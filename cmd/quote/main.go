@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,11 +11,19 @@ import (
 	"syscall"
 	"time"
 
+	"google.golang.org/grpc"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/card"
 	"github.com/zonder12120/brandscout-quotebook/internal/config"
+	"github.com/zonder12120/brandscout-quotebook/internal/contentfilter"
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
+	"github.com/zonder12120/brandscout-quotebook/internal/gql"
 	"github.com/zonder12120/brandscout-quotebook/internal/rest"
 	"github.com/zonder12120/brandscout-quotebook/internal/rest/handler"
+	"github.com/zonder12120/brandscout-quotebook/internal/rpc"
 	"github.com/zonder12120/brandscout-quotebook/internal/service"
 	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/internal/webhook"
 	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
 )
 
@@ -22,27 +31,141 @@ const gracefulShutdownTimeout = 5 * time.Second
 
 func main() {
 	cfg := config.MustLoad()
-	log := logger.New(cfg.LogLevel)
+	log := logger.New(cfg.LogLevel, cfg.LogFormat)
 
+	bus := events.NewBus(events.WithReplaySize(cfg.EventsReplaySize))
+	limits := service.WithLimits(service.Limits{
+		MaxAuthorLength: cfg.MaxAuthorLength,
+		MaxQuoteLength:  cfg.MaxQuoteLength,
+		MaxTags:         cfg.MaxTags,
+		MaxTagLength:    cfg.MaxTagLength,
+	})
 	quoteStorage := storage.NewInMemory(cfg.QuotesLimit)
-	quoteService := service.NewQuoteService(quoteStorage)
-	quoteHandler := handler.New(quoteService, log)
+	quoteOpts := []service.Option{
+		limits,
+		service.WithPublisher(bus),
+		service.WithMaxBatchSize(cfg.BatchMaxSize),
+		service.WithPopularityHalfLife(time.Duration(cfg.PopularityHalfLifeHours) * time.Hour),
+	}
+	// Without a reviewer nobody could approve submissions, so quotes are
+	// only held for review when the key is set.
+	if cfg.ReviewerAPIKey != "" {
+		quoteOpts = append(quoteOpts, service.WithModeration(cfg.ReviewerAPIKey))
+	}
+	// Rules are read from the file at start-up and reloaded when it changes,
+	// see the watcher started below.
+	var filterFile *contentfilter.File
+	if cfg.ContentFilterFile != "" {
+		var err error
+		filterFile, err = contentfilter.Open(cfg.ContentFilterFile)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to load content filter rules")
+			os.Exit(1)
+		}
+		quoteOpts = append(quoteOpts, service.WithContentFilter(filterFile))
+		if cfg.ReviewerAPIKey == "" {
+			log.Warn().Msg("REVIEWER_API_KEY is not set, quotes flagged by the content filter are published with their flags instead of being held for review")
+		}
+	}
+	// Playlists read quotes from the same storage and are told when quotes
+	// leave it. They only hold quotes of the default collection, so the
+	// collections below get the options without the hook.
+	playlistService := service.NewPlaylistService(storage.NewPlaylistInMemory(), quoteStorage)
+	quoteService := service.NewQuoteService(quoteStorage, append(quoteOpts, service.WithRemovalHook(playlistService.RemoveQuotes))...)
+	// Collections can only be managed with the admin key, without one they
+	// are not served at all. Their events carry the collection name.
+	var collectionService *service.CollectionService
+	var schedulerOpts []service.SchedulerOption
+	if cfg.AdminAPIKey != "" {
+		collectionService = service.NewCollectionService(storage.NewCollectionInMemory(),
+			service.WithAdminKey(cfg.AdminAPIKey),
+			service.WithDefaultQuotesLimit(cfg.QuotesLimit),
+			service.WithQuoteOptions(quoteOpts...),
+		)
+		schedulerOpts = append(schedulerOpts, service.WithCollectionSchedules(collectionService))
+	}
+	scheduler := service.NewScheduler(quoteService, log, time.Duration(cfg.ScheduleIntervalSeconds)*time.Second, schedulerOpts...)
+	scheduler.Start()
+	webhookStorage := storage.NewWebhookInMemory(storage.DefaultMaxDeadLetters)
+	dispatcher := webhook.NewDispatcher(webhookStorage, log,
+		webhook.WithWorkers(cfg.WebhookWorkers),
+		webhook.WithQueueSize(cfg.WebhookQueueSize),
+		webhook.WithRetry(cfg.WebhookMaxAttempts, time.Duration(cfg.WebhookBackoffMs)*time.Millisecond),
+	)
+	// A dropped subscription would end deliveries for good, so events the
+	// dispatcher has not taken yet are queued by the bus instead.
+	webhookEvents, _ := bus.SubscribeReliable(cfg.WebhookQueueSize)
+	dispatcher.Start(webhookEvents)
+	webhookService := service.NewWebhookService(webhookStorage, dispatcher, service.WithWebhookAdminKey(cfg.AdminAPIKey))
 
-	router := rest.NewRouter(quoteHandler, log)
+	quoteHandler := handler.New(quoteService, log, handler.WithMaxBodyBytes(int64(cfg.MaxBodyBytes)))
 
-	addr := cfg.Port
-	if !strings.HasPrefix(addr, ":") {
-		addr = ":" + addr
+	graphqlHandler, err := gql.New(quoteService, log,
+		gql.WithLimits(gql.Limits{
+			MaxDepth:      cfg.GraphQLMaxDepth,
+			MaxComplexity: cfg.GraphQLMaxComplexity,
+		}),
+		gql.WithMaxBodyBytes(int64(cfg.MaxBodyBytes)),
+	)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to build GraphQL schema")
+		os.Exit(1)
 	}
 
+	eventsHandler := handler.NewEvents(bus, log)
+	pagesHandler := handler.NewPages(quoteService, log)
+
+	renderer, err := card.NewRenderer(card.WithCacheSize(cfg.ImageCacheSize))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load card fonts")
+		os.Exit(1)
+	}
+	imageHandler := handler.NewImages(quoteService, renderer, log)
+	feedHandler := handler.NewFeeds(quoteService, log, handler.WithFeedSize(cfg.FeedSize))
+	webhookHandler := handler.NewWebhooks(webhookService, log, handler.WithMaxBodyBytes(int64(cfg.MaxBodyBytes)))
+	playlistHandler := handler.NewPlaylists(playlistService, log, handler.WithMaxBodyBytes(int64(cfg.MaxBodyBytes)))
+	moderationHandler := handler.NewModeration(quoteService, log, handler.WithMaxBodyBytes(int64(cfg.MaxBodyBytes)))
+
+	routerOpts := []rest.Option{
+		rest.WithGraphQL(graphqlHandler),
+		rest.WithEvents(eventsHandler),
+		rest.WithWebhooks(webhookHandler),
+		rest.WithFeeds(feedHandler),
+		rest.WithPages(pagesHandler),
+		rest.WithImages(imageHandler),
+		rest.WithModeration(moderationHandler),
+		rest.WithPlaylists(playlistHandler),
+	}
+	if collectionService != nil {
+		collectionHandler := handler.NewCollections(collectionService, log, handler.WithMaxBodyBytes(int64(cfg.MaxBodyBytes)))
+		routerOpts = append(routerOpts, rest.WithCollections(collectionHandler))
+	}
+
+	router := rest.NewRouter(quoteHandler, log, routerOpts...)
+	grpcServer := rpc.NewServer(quoteService, bus, log)
+
+	addr := listenAddr(cfg.Port)
+	grpcAddr := listenAddr(cfg.GRPCPort)
+
+	// Cancelled if graceful shutdown times out, aborting in-flight requests.
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
 	server := &http.Server{
 		Addr:    addr,
 		Handler: router,
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
 	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	if filterFile != nil && cfg.ContentFilterReloadSeconds > 0 {
+		go filterFile.Watch(ctx, time.Duration(cfg.ContentFilterReloadSeconds)*time.Second, log)
+	}
+
 	go func() {
 		log.Info().Msgf("Starting server on %s", addr)
 		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -50,15 +173,73 @@ func main() {
 		}
 	}()
 
+	go func() {
+		log.Info().Msgf("Starting gRPC server on %s", grpcAddr)
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to listen for gRPC")
+			return
+		}
+		if err := grpcServer.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			log.Error().Err(err).Msg("Failed to start gRPC server")
+		}
+	}()
+
 	<-ctx.Done()
 	log.Info().Msg("Shutting down server ...")
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), gracefulShutdownTimeout)
 	defer cancel()
 
+	if err := scheduler.Stop(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("Scheduler forced to stop")
+	}
+
+	// Closing the bus ends SSE, WebSocket and gRPC event streams, otherwise
+	// they would hold shutdown.
+	bus.Close()
+
 	if err := server.Shutdown(shutdownCtx); err != nil {
+		cancelRequests()
 		log.Error().Err(err).Msg("Server forced to shutdown")
 	} else {
 		log.Info().Msg("Server stopped gracefully")
 	}
+
+	if stopGRPC(shutdownCtx, grpcServer) {
+		log.Info().Msg("gRPC server stopped gracefully")
+	} else {
+		log.Error().Msg("gRPC server forced to shutdown")
+	}
+
+	// Deliveries still failing when time runs out are kept as dead letters.
+	if err := dispatcher.Shutdown(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("Webhook deliveries interrupted")
+	} else {
+		log.Info().Msg("Webhook dispatcher stopped gracefully")
+	}
+}
+
+func listenAddr(port string) string {
+	if !strings.HasPrefix(port, ":") {
+		return ":" + port
+	}
+	return port
+}
+
+// stopGRPC waits for in-flight RPCs until ctx is done, then stops forcibly.
+func stopGRPC(ctx context.Context, srv *grpc.Server) bool {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		srv.Stop()
+		return false
+	}
 }
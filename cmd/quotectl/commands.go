@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/zonder12120/brandscout-quotebook/pkg/quoteclient"
+)
+
+const (
+	defaultLimit = 20
+	exportLimit  = 100
+)
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(ctx context.Context, c *cli, args []string) error
+}
+
+var commands []*command
+
+// Commands look themselves up to print their help, so the table is filled
+// in init to avoid an initialization cycle.
+func init() {
+	commands = []*command{
+		{name: "add", usage: "-author NAME [-tags a,b] [-lang CODE] [text | -]", summary: "add a quote, the text is read from stdin when omitted", run: runAdd},
+		{name: "get", usage: "[-format table|json|csv] ID", summary: "show a quote", run: runGet},
+		{name: "list", usage: "[-limit N] [-after CURSOR] [-all] [-author NAME] [-tag TAG] [-lang CODE] [-format table|json|csv]", summary: "list quotes page by page", run: runList},
+		{name: "random", usage: "[-lang CODE] [-format table|json|csv]", summary: "show a random quote", run: runRandom},
+		{name: "search", usage: "[-limit N] [-after CURSOR] [-all] [-tag TAG] [-lang CODE] [-format table|json|csv] TEXT", summary: "find quotes containing text", run: runSearch},
+		{name: "delete", usage: "ID...", summary: "delete quotes", run: runDelete},
+		{name: "import", usage: "[-format json|jsonl|csv] FILE | -", summary: "add quotes from a file", run: runImport},
+		{name: "export", usage: "[-format json|jsonl|csv] [-out FILE] [-tag TAG]", summary: "write all quotes to a file", run: runExport},
+	}
+}
+
+func lookupCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// flags returns a flag set that reports errors through run instead of
+// printing them itself.
+func (c *cli) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+func (c *cli) parse(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		cmd := lookupCommand(fs.Name())
+		fmt.Fprintf(c.stdout, "usage: quotectl %s %s\n\n%s\n", cmd.name, cmd.usage, cmd.summary)
+		fs.SetOutput(c.stdout)
+		fs.PrintDefaults()
+		return err
+	}
+	if err != nil {
+		return usagef("%v", err)
+	}
+	return nil
+}
+
+func formatFlag(fs *flag.FlagSet) *string {
+	return fs.String("format", formatTable, "output format: table, json or csv")
+}
+
+func runAdd(ctx context.Context, c *cli, args []string) error {
+	fs := c.flags("add")
+	author := fs.String("author", "", "author of the quote")
+	tags := fs.String("tags", "", "comma-separated tags")
+	lang := fs.String("lang", "", "ISO 639-1 language code, detected by the server when omitted")
+	format := formatFlag(fs)
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*author) == "" {
+		return usagef("-author is required")
+	}
+	if err := checkFormat(*format, formatTable, formatJSON, formatCSV); err != nil {
+		return err
+	}
+
+	text := strings.Join(fs.Args(), " ")
+	if text == "" || text == "-" {
+		b, err := io.ReadAll(c.stdin)
+		if err != nil {
+			return err
+		}
+		text = string(b)
+	}
+
+	q, err := c.client.Create(ctx, quoteclient.NewQuote{Author: *author, Quote: strings.TrimSpace(text), Tags: splitTags(*tags), Lang: *lang})
+	if err != nil {
+		return err
+	}
+	return writeQuote(c.stdout, *format, q)
+}
+
+func runGet(ctx context.Context, c *cli, args []string) error {
+	fs := c.flags("get")
+	format := formatFlag(fs)
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usagef("expected one quote ID")
+	}
+	id, err := parseID(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	q, err := c.client.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return writeQuote(c.stdout, *format, q)
+}
+
+func runRandom(ctx context.Context, c *cli, args []string) error {
+	fs := c.flags("random")
+	lang := fs.String("lang", "", "only quotes in this ISO 639-1 language")
+	format := formatFlag(fs)
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	var q *quoteclient.Quote
+	var err error
+	if *lang != "" {
+		q, err = c.client.RandomIn(ctx, *lang)
+	} else {
+		q, err = c.client.Random(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	return writeQuote(c.stdout, *format, q)
+}
+
+// pageFlags are shared by list and search.
+type pageFlags struct {
+	limit  *int
+	after  *string
+	all    *bool
+	tag    *string
+	lang   *string
+	format *string
+}
+
+func newPageFlags(fs *flag.FlagSet) pageFlags {
+	return pageFlags{
+		limit:  fs.Int("limit", defaultLimit, "quotes per page"),
+		after:  fs.String("after", "", "cursor of the page to show, printed after the previous page"),
+		all:    fs.Bool("all", false, "fetch every page"),
+		tag:    fs.String("tag", "", "only quotes with this tag"),
+		lang:   fs.String("lang", "", "only quotes in this ISO 639-1 language"),
+		format: formatFlag(fs),
+	}
+}
+
+func runList(ctx context.Context, c *cli, args []string) error {
+	fs := c.flags("list")
+	pf := newPageFlags(fs)
+	author := fs.String("author", "", "all quotes of an author, not paged")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return usagef("unexpected arguments %q", fs.Args())
+	}
+
+	if *author != "" {
+		quotes, err := c.client.ByAuthor(ctx, *author)
+		if err != nil {
+			return err
+		}
+		return writeQuotes(c.stdout, *pf.format, quotes)
+	}
+	return c.page(ctx, "list", pf, "")
+}
+
+func runSearch(ctx context.Context, c *cli, args []string) error {
+	fs := c.flags("search")
+	pf := newPageFlags(fs)
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	query := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if query == "" {
+		return usagef("expected text to search for")
+	}
+	return c.page(ctx, "search", pf, query)
+}
+
+// page prints one page, or all of them with -all, and tells how to get the
+// next one.
+func (c *cli) page(ctx context.Context, name string, pf pageFlags, query string) error {
+	if *pf.limit < 1 {
+		return usagef("-limit must be positive")
+	}
+	if err := checkFormat(*pf.format, formatTable, formatJSON, formatCSV); err != nil {
+		return err
+	}
+
+	opts := quoteclient.ListOptions{Limit: *pf.limit, After: *pf.after, Query: query, Tag: *pf.tag, Lang: *pf.lang}
+	var quotes []quoteclient.Quote
+	for {
+		page, err := c.client.List(ctx, opts)
+		if err != nil {
+			return err
+		}
+		quotes = append(quotes, page.Quotes...)
+
+		if page.Next == "" {
+			break
+		}
+		if !*pf.all {
+			fmt.Fprintf(c.stderr, "next page: quotectl %s -after %s\n", name, page.Next)
+			break
+		}
+		opts.After = page.Next
+	}
+	return writeQuotes(c.stdout, *pf.format, quotes)
+}
+
+func runDelete(ctx context.Context, c *cli, args []string) error {
+	fs := c.flags("delete")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return usagef("expected quote IDs")
+	}
+
+	ids := make([]int, fs.NArg())
+	for i, arg := range fs.Args() {
+		id, err := parseID(arg)
+		if err != nil {
+			return err
+		}
+		ids[i] = id
+	}
+
+	for _, id := range ids {
+		if err := c.client.Delete(ctx, id); err != nil {
+			return fmt.Errorf("quote %d: %w", id, err)
+		}
+		fmt.Fprintf(c.stdout, "deleted %d\n", id)
+	}
+	return nil
+}
+
+func parseID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 1 {
+		return 0, usagef("invalid quote ID %q", s)
+	}
+	return id, nil
+}
+
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zonder12120/brandscout-quotebook/pkg/quoteclient"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatJSONL = "jsonl"
+	formatCSV   = "csv"
+
+	// maxTableQuote keeps table rows on one terminal line.
+	maxTableQuote = 60
+)
+
+var csvHeader = []string{"id", "author", "quote", "tags", "created_at", "updated_at"}
+
+func checkFormat(format string, allowed ...string) error {
+	if !slices.Contains(allowed, format) {
+		return usagef("unknown format %q, expected one of %s", format, strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+// writeQuote prints a single quote, as a table it is shown in full.
+func writeQuote(w io.Writer, format string, q *quoteclient.Quote) error {
+	switch format {
+	case formatTable:
+		fmt.Fprintf(w, "%s\n— %s\n", q.Quote, q.Author)
+		fmt.Fprintf(w, "\nid: %d", q.ID)
+		if len(q.Tags) > 0 {
+			fmt.Fprintf(w, "  tags: %s", strings.Join(q.Tags, ", "))
+		}
+		_, err := fmt.Fprintln(w)
+		return err
+	case formatJSON:
+		return writeJSON(w, q)
+	default:
+		return writeQuotes(w, format, []quoteclient.Quote{*q})
+	}
+}
+
+func writeQuotes(w io.Writer, format string, quotes []quoteclient.Quote) error {
+	switch format {
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tAUTHOR\tTAGS\tQUOTE")
+		for _, q := range quotes {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", q.ID, q.Author, strings.Join(q.Tags, ","), shorten(q.Quote, maxTableQuote))
+		}
+		return tw.Flush()
+	case formatJSON:
+		if quotes == nil {
+			quotes = []quoteclient.Quote{}
+		}
+		return writeJSON(w, quotes)
+	case formatJSONL:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		for _, q := range quotes {
+			if err := enc.Encode(q); err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write(csvHeader)
+		for _, q := range quotes {
+			_ = cw.Write([]string{
+				strconv.Itoa(q.ID), q.Author, q.Quote, strings.Join(q.Tags, ","),
+				formatTime(q.CreatedAt), formatTime(q.UpdatedAt),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return usagef("unknown format %q", format)
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// shorten cuts s to n runes with an ellipsis and flattens line breaks.
+func shorten(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,199 @@
+// Command quotectl manages quotes of a quotebook server from the shell.
+//
+// The server URL, API key and collection are taken from the -url, -api-key
+// and -collection flags, then the QUOTECTL_URL, QUOTECTL_API_KEY and
+// QUOTECTL_COLLECTION environment variables, then the config file (-config,
+// QUOTECTL_CONFIG or $XDG_CONFIG_HOME/quotectl/config) holding the same
+// variables as KEY=value lines.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/zonder12120/brandscout-quotebook/pkg/quoteclient"
+)
+
+const (
+	defaultURL     = "http://localhost:8080"
+	defaultTimeout = 10 * time.Second
+
+	envURL        = "QUOTECTL_URL"
+	envAPIKey     = "QUOTECTL_API_KEY"
+	envCollection = "QUOTECTL_COLLECTION"
+	envConfig     = "QUOTECTL_CONFIG"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Getenv, os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// cli is what commands need to talk to the server and the user.
+type cli struct {
+	client *quoteclient.Client
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// usageError makes run print the command usage and exit with status 2.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+func run(ctx context.Context, args []string, getenv func(string) string, stdin io.Reader, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("quotectl", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.Usage = func() { printUsage(stderr, global) }
+
+	serverURL := global.String("url", "", "server URL (default "+defaultURL+")")
+	apiKey := global.String("api-key", "", "API key sent as a bearer token")
+	collection := global.String("collection", "", "work on the quotes of this collection instead of the default ones")
+	configPath := global.String("config", "", "config file")
+	timeout := global.Duration("timeout", defaultTimeout, "timeout of a single request")
+
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		return 2
+	}
+
+	cmd := lookupCommand(global.Arg(0))
+	if cmd == nil {
+		fmt.Fprintf(stderr, "quotectl: unknown command %q\n", global.Arg(0))
+		global.Usage()
+		return 2
+	}
+
+	settings, err := loadSettings(*configPath, getenv)
+	if err != nil {
+		fmt.Fprintf(stderr, "quotectl: %v\n", err)
+		return 1
+	}
+	if *serverURL != "" {
+		settings[envURL] = *serverURL
+	}
+	if *apiKey != "" {
+		settings[envAPIKey] = *apiKey
+	}
+	if *collection != "" {
+		settings[envCollection] = *collection
+	}
+
+	client, err := quoteclient.New(settings[envURL],
+		quoteclient.WithAPIKey(settings[envAPIKey]),
+		quoteclient.WithUserAgent("quotectl"),
+		quoteclient.WithHTTPClient(&http.Client{Timeout: *timeout}),
+	)
+	if err != nil {
+		fmt.Fprintf(stderr, "quotectl: %v\n", err)
+		return 1
+	}
+	if name := settings[envCollection]; name != "" {
+		client = client.Collection(name)
+	}
+
+	c := &cli{client: client, stdin: stdin, stdout: stdout, stderr: stderr}
+	err = cmd.run(ctx, c, global.Args()[1:])
+
+	var usageErr *usageError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(stderr, "quotectl %s: %v\nusage: quotectl %s %s\n", cmd.name, err, cmd.name, cmd.usage)
+		return 2
+	default:
+		fmt.Fprintf(stderr, "quotectl %s: %v\n", cmd.name, err)
+		return 1
+	}
+}
+
+func printUsage(w io.Writer, global *flag.FlagSet) {
+	fmt.Fprintf(w, "usage: quotectl [flags] <command> [command flags] [args]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nflags:\n")
+	global.PrintDefaults()
+}
+
+// loadSettings merges the config file under the environment. A missing
+// default config file is fine, an explicitly given one must exist.
+func loadSettings(path string, getenv func(string) string) (map[string]string, error) {
+	explicit := path != ""
+	if !explicit {
+		path = getenv(envConfig)
+		explicit = path != ""
+	}
+	if !explicit {
+		if dir, err := os.UserConfigDir(); err == nil {
+			path = filepath.Join(dir, "quotectl", "config")
+		}
+	}
+
+	settings := map[string]string{envURL: defaultURL}
+	if path != "" {
+		if err := readConfig(path, settings); err != nil && (explicit || !errors.Is(err, os.ErrNotExist)) {
+			return nil, err
+		}
+	}
+
+	for _, key := range []string{envURL, envAPIKey, envCollection} {
+		if v := getenv(key); v != "" {
+			settings[key] = v
+		}
+	}
+	return settings, nil
+}
+
+// readConfig reads KEY=value lines in the format of config/.env, blank
+// lines and # comments are skipped.
+func readConfig(path string, settings map[string]string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected KEY=value", path, n)
+		}
+		settings[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/rest"
+	"github.com/zonder12120/brandscout-quotebook/internal/rest/handler"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+	"github.com/zonder12120/brandscout-quotebook/pkg/quoteclient"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	log := logger.New("error", "console")
+	svc := service.NewQuoteService(storage.NewInMemory(100))
+	srv := httptest.NewServer(rest.NewRouter(handler.New(svc, log), log))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+type result struct {
+	code   int
+	stdout string
+	stderr string
+}
+
+func runCLI(t *testing.T, url, stdin string, args ...string) result {
+	t.Helper()
+
+	// Keep a config file of the developer out of the way.
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	getenv := func(key string) string {
+		if key == envURL {
+			return url
+		}
+		return ""
+	}
+	code := run(context.Background(), args, getenv, strings.NewReader(stdin), &stdout, &stderr)
+	return result{code: code, stdout: stdout.String(), stderr: stderr.String()}
+}
+
+func TestCommands(t *testing.T) {
+	srv := newTestServer(t)
+
+	res := runCLI(t, srv.URL, "Пока мы учим, мы учимся.\n", "add", "-author", "Сенека", "-tags", "learning, wisdom", "-format", "json")
+	if res.code != 0 {
+		t.Fatalf("add failed: %s", res.stderr)
+	}
+	var added quoteclient.Quote
+	if err := json.Unmarshal([]byte(res.stdout), &added); err != nil {
+		t.Fatal(err)
+	}
+	if added.ID != 1 || added.Quote != "Пока мы учим, мы учимся." || len(added.Tags) != 2 {
+		t.Fatalf("unexpected quote %+v", added)
+	}
+
+	for _, text := range []string{"Learning never exhausts the mind", "Simplicity is the ultimate sophistication"} {
+		if res := runCLI(t, srv.URL, "", "add", "-author", "Leonardo", text); res.code != 0 {
+			t.Fatalf("add failed: %s", res.stderr)
+		}
+	}
+
+	t.Run("List pages", func(t *testing.T) {
+		res := runCLI(t, srv.URL, "", "list", "-limit", "2")
+		if res.code != 0 || strings.Count(res.stdout, "\n") != 3 {
+			t.Fatalf("expected a header and 2 rows, got %q", res.stdout)
+		}
+		cursor := strings.TrimSpace(strings.TrimPrefix(res.stderr, "next page: quotectl list -after "))
+
+		res = runCLI(t, srv.URL, "", "list", "-limit", "2", "-after", cursor, "-format", "csv")
+		if res.code != 0 || !strings.Contains(res.stdout, "3,Leonardo,Simplicity") || res.stderr != "" {
+			t.Errorf("expected the last page as CSV, got %q %q", res.stdout, res.stderr)
+		}
+
+		res = runCLI(t, srv.URL, "", "list", "-limit", "1", "-all")
+		if res.code != 0 || strings.Count(res.stdout, "\n") != 4 {
+			t.Errorf("expected all 3 quotes, got %q", res.stdout)
+		}
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		res := runCLI(t, srv.URL, "", "search", "-format", "json", "learning")
+		var found []quoteclient.Quote
+		if err := json.Unmarshal([]byte(res.stdout), &found); err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != 1 || found[0].ID != 2 {
+			t.Errorf("expected quote 2, got %+v", found)
+		}
+	})
+
+	t.Run("Export and import round trip", func(t *testing.T) {
+		for _, format := range []string{formatJSON, formatJSONL, formatCSV} {
+			file := filepath.Join(t.TempDir(), "quotes."+format)
+			if res := runCLI(t, srv.URL, "", "export", "-format", format, "-out", file); res.code != 0 {
+				t.Fatalf("export failed: %s", res.stderr)
+			}
+
+			target := newTestServer(t)
+			res := runCLI(t, target.URL, "", "import", file)
+			if res.code != 0 || res.stdout != "imported 3 of 3 quotes\n" {
+				t.Fatalf("%s: import failed: %q %q", format, res.stdout, res.stderr)
+			}
+
+			res = runCLI(t, target.URL, "", "get", "-format", "json", "1")
+			var q quoteclient.Quote
+			_ = json.Unmarshal([]byte(res.stdout), &q)
+			if q.Author != added.Author || q.Quote != added.Quote || len(q.Tags) != 2 {
+				t.Errorf("%s: expected %+v, got %+v", format, added, q)
+			}
+		}
+	})
+
+	t.Run("Import reports rejected quotes", func(t *testing.T) {
+		res := runCLI(t, srv.URL, `[{"author": "A", "quote": "Q"}, {"author": "", "quote": "Q"}]`, "import", "-")
+		if res.code != 1 || !strings.Contains(res.stdout, "imported 1 of 2") || !strings.Contains(res.stderr, "quote 2") {
+			t.Errorf("unexpected result %+v", res)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		res := runCLI(t, srv.URL, "", "delete", "1", "1")
+		if res.code != 1 || res.stdout != "deleted 1\n" || !strings.Contains(res.stderr, "404 Resource not found") {
+			t.Errorf("expected the second delete to fail, got %+v", res)
+		}
+
+		for _, args := range [][]string{{"get"}, {"get", "abc"}, {"list", "-bogus"}, {"bogus"}, {"add", "text"}} {
+			if res := runCLI(t, srv.URL, "", args...); res.code != 2 {
+				t.Errorf("%v: expected usage error, got %+v", args, res)
+			}
+		}
+	})
+}
+
+func TestLoadSettings(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(config, []byte("# quotectl\nQUOTECTL_URL=http://config:8080\nQUOTECTL_API_KEY=\"from-config\"\nQUOTECTL_COLLECTION=team-a\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{envAPIKey: "from-env"}
+	settings, err := loadSettings(config, func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings[envURL] != "http://config:8080" || settings[envAPIKey] != "from-env" || settings[envCollection] != "team-a" {
+		t.Errorf("expected env over config file, got %v", settings)
+	}
+
+	if _, err := loadSettings(filepath.Join(t.TempDir(), "missing"), func(string) string { return "" }); err == nil {
+		t.Error("expected an explicit missing config file to fail")
+	}
+}
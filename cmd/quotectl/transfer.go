@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/zonder12120/brandscout-quotebook/pkg/quoteclient"
+)
+
+// runImport adds every quote of a file. The file is parsed completely
+// first, so a malformed file adds nothing; rejected quotes are reported
+// and skipped.
+func runImport(ctx context.Context, c *cli, args []string) error {
+	fs := c.flags("import")
+	format := fs.String("format", "", "json, jsonl or csv, guessed from the file extension by default")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usagef("expected a file name or - for stdin")
+	}
+
+	name := fs.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(name), ".")
+		if name == "-" || *format == "" {
+			*format = formatJSON
+		}
+	}
+	if err := checkFormat(*format, formatJSON, formatJSONL, formatCSV); err != nil {
+		return err
+	}
+
+	r := c.stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	quotes, err := readQuotes(r, *format)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", name, err)
+	}
+
+	failed := 0
+	for i, q := range quotes {
+		if _, err := c.client.Create(ctx, q); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			failed++
+			fmt.Fprintf(c.stderr, "quote %d (%s): %v\n", i+1, shorten(q.Quote, 30), err)
+		}
+	}
+
+	fmt.Fprintf(c.stdout, "imported %d of %d quotes\n", len(quotes)-failed, len(quotes))
+	if failed > 0 {
+		return fmt.Errorf("%d quotes were rejected", failed)
+	}
+	return nil
+}
+
+// readQuotes reads quotes in the formats export writes. IDs and times are
+// ignored, the server assigns new ones. Drafts stay drafts, other quotes go
+// through moderation again.
+func readQuotes(r io.Reader, format string) ([]quoteclient.NewQuote, error) {
+	var quotes []quoteclient.NewQuote
+
+	switch format {
+	case formatJSON:
+		if err := json.NewDecoder(r).Decode(&quotes); err != nil {
+			return nil, err
+		}
+	case formatJSONL:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, 1<<20)
+		for n := 1; scanner.Scan(); n++ {
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+			var q quoteclient.NewQuote
+			if err := json.Unmarshal(scanner.Bytes(), &q); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			quotes = append(quotes, q)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	case formatCSV:
+		return readCSV(r)
+	}
+
+	for i := range quotes {
+		if quotes[i].Status != quoteclient.StatusDraft {
+			quotes[i].Status = ""
+		}
+	}
+	return quotes, nil
+}
+
+// readCSV finds columns by the header, so only author and quote are needed
+// and their order does not matter.
+func readCSV(r io.Reader) ([]quoteclient.NewQuote, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	author, text, tags := slices.Index(header, "author"), slices.Index(header, "quote"), slices.Index(header, "tags")
+	if author < 0 || text < 0 {
+		return nil, errors.New("header must have author and quote columns")
+	}
+
+	var quotes []quoteclient.NewQuote
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return quotes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		q := quoteclient.NewQuote{Author: record[author], Quote: record[text]}
+		if tags >= 0 {
+			q.Tags = splitTags(record[tags])
+		}
+		quotes = append(quotes, q)
+	}
+}
+
+func runExport(ctx context.Context, c *cli, args []string) error {
+	fs := c.flags("export")
+	format := fs.String("format", formatJSON, "json, jsonl or csv")
+	out := fs.String("out", "", "output file, stdout by default")
+	tag := fs.String("tag", "", "only quotes with this tag")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format, formatJSON, formatJSONL, formatCSV); err != nil {
+		return err
+	}
+
+	var quotes []quoteclient.Quote
+	for q, err := range c.client.All(ctx, quoteclient.ListOptions{Limit: exportLimit, Tag: *tag}) {
+		if err != nil {
+			return err
+		}
+		quotes = append(quotes, q)
+	}
+
+	if *out == "" {
+		return writeQuotes(c.stdout, *format, quotes)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := writeQuotes(f, *format, quotes); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stderr, "exported %d quotes to %s\n", len(quotes), *out)
+	return nil
+}
@@ -0,0 +1,245 @@
+// Package card renders quotes as shareable image cards. SVG and PNG share
+// one layout computed from the embedded Go fonts, which cover Latin, Greek
+// and Cyrillic, so both formats wrap lines identically.
+package card
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+)
+
+type Format string
+
+const (
+	FormatSVG Format = "svg"
+	FormatPNG Format = "png"
+)
+
+const (
+	DefaultWidth     = 1200
+	DefaultHeight    = 630
+	DefaultTheme     = "light"
+	DefaultFont      = "sans"
+	DefaultCacheSize = 256
+
+	MinSize = 200
+	MaxSize = 2400
+)
+
+// Options control the card appearance, see DefaultOptions.
+type Options struct {
+	Width  int
+	Height int
+	Theme  string
+	Font   string
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Width:  DefaultWidth,
+		Height: DefaultHeight,
+		Theme:  DefaultTheme,
+		Font:   DefaultFont,
+	}
+}
+
+// Validate reports the first unsupported option.
+func (o Options) Validate() error {
+	if o.Width < MinSize || o.Width > MaxSize {
+		return fmt.Errorf("width must be between %d and %d", MinSize, MaxSize)
+	}
+	if o.Height < MinSize || o.Height > MaxSize {
+		return fmt.Errorf("height must be between %d and %d", MinSize, MaxSize)
+	}
+	if _, ok := themes[o.Theme]; !ok {
+		return fmt.Errorf("theme must be one of light, dark")
+	}
+	if _, ok := fonts[o.Font]; !ok {
+		return fmt.Errorf("font must be one of sans, italic, mono")
+	}
+	return nil
+}
+
+type theme struct {
+	background color.RGBA
+	text       color.RGBA
+	muted      color.RGBA
+	accent     color.RGBA
+}
+
+var themes = map[string]theme{
+	"light": {
+		background: color.RGBA{0xfb, 0xfb, 0xfd, 0xff},
+		text:       color.RGBA{0x1d, 0x1d, 0x1f, 0xff},
+		muted:      color.RGBA{0x6e, 0x6e, 0x73, 0xff},
+		accent:     color.RGBA{0x0a, 0x66, 0xc2, 0xff},
+	},
+	"dark": {
+		background: color.RGBA{0x1c, 0x1c, 0x1e, 0xff},
+		text:       color.RGBA{0xf5, 0xf5, 0xf7, 0xff},
+		muted:      color.RGBA{0xa1, 0xa1, 0xa6, 0xff},
+		accent:     color.RGBA{0x4a, 0xa3, 0xff, 0xff},
+	},
+}
+
+var fonts = map[string][]byte{
+	"sans":   goregular.TTF,
+	"italic": goitalic.TTF,
+	"mono":   gomono.TTF,
+}
+
+// Renderer renders cards and keeps the most recently rendered ones in a
+// bounded cache keyed by quote content and options, so edits invalidate
+// old renderings and unchanged quotes are never rendered twice.
+type Renderer struct {
+	fonts map[string]*opentype.Font
+	cache *cache
+}
+
+type Option func(*Renderer)
+
+// WithCacheSize sets the number of cached images, 0 disables the cache.
+func WithCacheSize(n int) Option {
+	return func(r *Renderer) {
+		r.cache = newCache(n)
+	}
+}
+
+func NewRenderer(opts ...Option) (*Renderer, error) {
+	r := &Renderer{
+		fonts: make(map[string]*opentype.Font, len(fonts)),
+		cache: newCache(DefaultCacheSize),
+	}
+	for name, ttf := range fonts {
+		f, err := opentype.Parse(ttf)
+		if err != nil {
+			return nil, fmt.Errorf("parse font %s: %w", name, err)
+		}
+		r.fonts[name] = f
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
+}
+
+// Image is a rendered card with its entity tag.
+type Image struct {
+	Data []byte
+	ETag string
+}
+
+// Render returns the card of q in format, from the cache when possible.
+func (r *Renderer) Render(q *model.Quote, format Format, o Options) (*Image, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	key := cacheKey(q, format, o)
+	if img, ok := r.cache.get(key); ok {
+		return img, nil
+	}
+
+	l, err := r.layout(q, o)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	switch format {
+	case FormatSVG:
+		data = l.svg(fonts[o.Font])
+	case FormatPNG:
+		if data, err = l.png(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+
+	img := &Image{Data: data, ETag: strconv.Quote(key)}
+	r.cache.add(key, img)
+	return img, nil
+}
+
+// ContentHash identifies what a card shows, the quote ID is not part of it
+// so identical quotes share renderings.
+func ContentHash(q *model.Quote) string {
+	sum := sha256.Sum256([]byte(q.Author + "\x00" + q.Quote))
+	return hex.EncodeToString(sum[:])
+}
+
+func cacheKey(q *model.Quote, format Format, o Options) string {
+	raw := fmt.Sprintf("%s|%s|%dx%d|%s|%s", ContentHash(q), format, o.Width, o.Height, o.Theme, o.Font)
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:16])
+}
+
+// svg draws the layout as SVG text, embedding the font so viewers render
+// the same glyphs the lines were measured with.
+func (l *layout) svg(ttf []byte) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, l.width, l.height, l.width, l.height)
+	fmt.Fprintf(&b, `<style>@font-face{font-family:"Quotebook";src:url(data:font/ttf;base64,%s) format("truetype")}text{font-family:"Quotebook",sans-serif}</style>`,
+		base64.StdEncoding.EncodeToString(ttf))
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`, hexColor(l.theme.background))
+	fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`,
+		l.accent.Min.X, l.accent.Min.Y, l.accent.Dx(), l.accent.Dy(), hexColor(l.theme.accent))
+
+	fmt.Fprintf(&b, `<text font-size="%.1f" fill="%s">`, l.textSize, hexColor(l.theme.text))
+	for i, line := range l.lines {
+		fmt.Fprintf(&b, `<tspan x="%d" y="%d">`, l.x, l.baselines[i])
+		_ = xml.EscapeText(&b, []byte(line))
+		b.WriteString(`</tspan>`)
+	}
+	b.WriteString(`</text>`)
+
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="%.1f" fill="%s">`, l.x, l.authorBaseline, l.authorSize, hexColor(l.theme.muted))
+	_ = xml.EscapeText(&b, []byte(l.author))
+	b.WriteString(`</text></svg>`)
+	return b.Bytes()
+}
+
+func (l *layout) png() ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, l.width, l.height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(l.theme.background), image.Point{}, draw.Src)
+	draw.Draw(img, l.accent, image.NewUniform(l.theme.accent), image.Point{}, draw.Src)
+
+	d := font.Drawer{Dst: img, Src: image.NewUniform(l.theme.text), Face: l.textFace}
+	for i, line := range l.lines {
+		d.Dot = fixed.P(l.x, l.baselines[i])
+		d.DrawString(line)
+	}
+
+	d = font.Drawer{Dst: img, Src: image.NewUniform(l.theme.muted), Face: l.authorFace}
+	d.Dot = fixed.P(l.x, l.authorBaseline)
+	d.DrawString(l.author)
+
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
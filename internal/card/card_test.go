@@ -0,0 +1,164 @@
+package card
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+)
+
+func newTestRenderer(t *testing.T, opts ...Option) *Renderer {
+	t.Helper()
+
+	r, err := NewRenderer(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRender(t *testing.T) {
+	quote := &model.Quote{ID: 1, Author: "Лев Толстой", Quote: "Все счастливые семьи похожи друг на друга, каждая несчастливая семья несчастлива по-своему."}
+
+	t.Run("PNG has the requested size", func(t *testing.T) {
+		o := DefaultOptions()
+		o.Width, o.Height, o.Theme = 800, 400, "dark"
+
+		img, err := newTestRenderer(t).Render(quote, FormatPNG, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := png.Decode(bytes.NewReader(img.Data))
+		if err != nil {
+			t.Fatalf("invalid PNG: %v", err)
+		}
+		if b := decoded.Bounds(); b.Dx() != 800 || b.Dy() != 400 {
+			t.Errorf("expected 800x400, got %v", b)
+		}
+	})
+
+	t.Run("SVG is well-formed and embeds the font", func(t *testing.T) {
+		img, err := newTestRenderer(t).Render(&model.Quote{Author: "A <b>", Quote: quote.Quote}, FormatSVG, DefaultOptions())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dec := xml.NewDecoder(bytes.NewReader(img.Data))
+		var text strings.Builder
+		for {
+			tok, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("invalid SVG: %v", err)
+			}
+			if cd, ok := tok.(xml.CharData); ok {
+				text.Write(cd)
+			}
+		}
+
+		if !strings.Contains(text.String(), "— A <b>") || !strings.Contains(text.String(), "счастливые") {
+			t.Errorf("expected escaped author and Cyrillic text, got %q", text.String())
+		}
+		if !bytes.Contains(img.Data, []byte("data:font/ttf;base64,")) {
+			t.Error("expected an embedded font")
+		}
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		r := newTestRenderer(t)
+		for _, o := range []Options{
+			{Width: 100, Height: 630, Theme: "light", Font: "sans"},
+			{Width: 1200, Height: 5000, Theme: "light", Font: "sans"},
+			{Width: 1200, Height: 630, Theme: "neon", Font: "sans"},
+			{Width: 1200, Height: 630, Theme: "light", Font: "comic"},
+		} {
+			if _, err := r.Render(quote, FormatPNG, o); err == nil {
+				t.Errorf("expected %+v to be rejected", o)
+			}
+		}
+	})
+
+	t.Run("Cache is keyed by content and options", func(t *testing.T) {
+		r := newTestRenderer(t, WithCacheSize(2))
+
+		first, _ := r.Render(quote, FormatSVG, DefaultOptions())
+		same, _ := r.Render(&model.Quote{ID: 7, Author: quote.Author, Quote: quote.Quote}, FormatSVG, DefaultOptions())
+		if first != same || r.cache.len() != 1 {
+			t.Errorf("expected identical content to hit the cache")
+		}
+
+		edited, _ := r.Render(&model.Quote{ID: 1, Author: quote.Author, Quote: "Другой текст"}, FormatSVG, DefaultOptions())
+		if edited.ETag == first.ETag {
+			t.Error("expected a new ETag for edited content")
+		}
+
+		_, _ = r.Render(quote, FormatPNG, DefaultOptions())
+		if r.cache.len() != 2 {
+			t.Errorf("expected cache to be bounded to 2, got %d", r.cache.len())
+		}
+		if _, ok := r.cache.get(cacheKey(quote, FormatSVG, DefaultOptions())); ok {
+			t.Error("expected the least recently used image to be evicted")
+		}
+	})
+}
+
+func TestLayout(t *testing.T) {
+	r := newTestRenderer(t)
+
+	t.Run("Lines fit the card and shrink for long quotes", func(t *testing.T) {
+		short, err := r.layout(&model.Quote{Author: "A", Quote: "Коротко."}, DefaultOptions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		long, err := r.layout(&model.Quote{Author: "A", Quote: strings.Repeat("Длинная цитата о жизни. ", 40)}, DefaultOptions())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if long.textSize >= short.textSize || len(long.lines) < 2 {
+			t.Errorf("expected long quote to wrap at a smaller size, got %.0f vs %.0f", long.textSize, short.textSize)
+		}
+		for _, line := range long.lines {
+			if w := font.MeasureString(long.textFace, line).Ceil(); long.x+w > long.width {
+				t.Errorf("line %q overflows the card: %d px", line, long.x+w)
+			}
+		}
+		if last := long.baselines[len(long.baselines)-1]; last >= long.authorBaseline {
+			t.Errorf("text overlaps the author line: %d >= %d", last, long.authorBaseline)
+		}
+	})
+
+	t.Run("Words wider than a line are split", func(t *testing.T) {
+		o := DefaultOptions()
+		o.Width, o.Height = MinSize, MinSize
+
+		l, err := r.layout(&model.Quote{Author: "A", Quote: strings.Repeat("Ж", 60)}, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(l.lines) < 2 || strings.Join(l.lines, "") != strings.Repeat("Ж", 60) {
+			t.Errorf("unexpected lines %q", l.lines)
+		}
+	})
+
+	t.Run("Overlong quotes are cut with an ellipsis", func(t *testing.T) {
+		o := DefaultOptions()
+		o.Width, o.Height = MinSize, MinSize
+
+		l, err := r.layout(&model.Quote{Author: "A", Quote: strings.Repeat("word ", 500)}, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.textSize != minTextSize || !strings.HasSuffix(l.lines[len(l.lines)-1], ellipsis) {
+			t.Errorf("expected truncated text at minimum size, got %.0f %q", l.textSize, l.lines)
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package card
+
+import (
+	"image"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+)
+
+const (
+	lineSpacing = 1.3
+	minTextSize = 12.0
+	ellipsis    = "…"
+)
+
+// layout positions every element of a card in pixels. Faces are kept for
+// PNG drawing, SVG only needs sizes and positions.
+type layout struct {
+	width  int
+	height int
+	theme  theme
+	accent image.Rectangle
+	x      int
+
+	textSize  float64
+	textFace  font.Face
+	lines     []string
+	baselines []int
+
+	authorSize     float64
+	authorFace     font.Face
+	author         string
+	authorBaseline int
+}
+
+// layout picks the largest text size at which the wrapped quote fits above
+// the author line. Quotes too long even for the minimum size are cut with
+// an ellipsis.
+func (r *Renderer) layout(q *model.Quote, o Options) (*layout, error) {
+	f := r.fonts[o.Font]
+	short := min(o.Width, o.Height)
+	pad := short / 10
+	bar := max(pad/6, 4)
+
+	l := &layout{
+		width:  o.Width,
+		height: o.Height,
+		theme:  themes[o.Theme],
+		accent: image.Rect(pad, pad, pad+bar, o.Height-pad),
+		x:      pad + bar + pad/2,
+	}
+	maxWidth := fixed.I(o.Width - l.x - pad)
+
+	var err error
+	l.authorSize = max(float64(short)/20, minTextSize)
+	if l.authorFace, err = newFace(f, l.authorSize); err != nil {
+		return nil, err
+	}
+	l.author = truncate(l.authorFace, "— "+q.Author, maxWidth)
+	l.authorBaseline = o.Height - pad - l.authorFace.Metrics().Descent.Ceil()
+
+	available := float64(o.Height-2*pad) - l.authorSize*2.2
+
+	for size := max(float64(short)/6, minTextSize); ; size = max(size-2, minTextSize) {
+		face, err := newFace(f, size)
+		if err != nil {
+			return nil, err
+		}
+
+		lines := wrap(face, q.Quote, maxWidth)
+		lineHeight := size * lineSpacing
+		maxLines := max(int(available/lineHeight), 1)
+		if len(lines) > maxLines && size > minTextSize {
+			continue
+		}
+
+		if len(lines) > maxLines {
+			lines = lines[:maxLines]
+			lines[maxLines-1] = truncate(face, lines[maxLines-1]+ellipsis, maxWidth)
+		}
+
+		l.textSize, l.textFace, l.lines = size, face, lines
+
+		// Center the text block in the space above the author.
+		top := float64(pad) + (available-float64(len(lines))*lineHeight)/2
+		ascent := face.Metrics().Ascent.Ceil()
+		for i := range lines {
+			l.baselines = append(l.baselines, int(top+float64(i)*lineHeight)+ascent)
+		}
+		return l, nil
+	}
+}
+
+func newFace(f *opentype.Font, size float64) (font.Face, error) {
+	// Unhinted advances match what SVG viewers measure for the same font.
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+}
+
+// wrap breaks text into lines no wider than limit, words wider than a line
+// are split between runes.
+func wrap(face font.Face, text string, limit fixed.Int26_6) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if font.MeasureString(face, candidate) <= limit {
+			line = candidate
+			continue
+		}
+
+		if line != "" {
+			lines = append(lines, line)
+		}
+		for font.MeasureString(face, word) > limit {
+			n := fitPrefix(face, word, limit)
+			lines = append(lines, word[:n])
+			word = word[n:]
+		}
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fitPrefix returns the byte length of the longest prefix of s narrower than
+// limit, at least one rune so wrapping always makes progress.
+func fitPrefix(face font.Face, s string, limit fixed.Int26_6) int {
+	n := 0
+	for i, r := range s {
+		next := i + utf8.RuneLen(r)
+		if n > 0 && font.MeasureString(face, s[:next]) > limit {
+			break
+		}
+		n = next
+	}
+	return n
+}
+
+// truncate shortens s with an ellipsis until it fits into limit.
+func truncate(face font.Face, s string, limit fixed.Int26_6) string {
+	if font.MeasureString(face, s) <= limit {
+		return s
+	}
+
+	s = strings.TrimSuffix(s, ellipsis)
+	for s != "" {
+		_, size := utf8.DecodeLastRuneInString(s)
+		s = strings.TrimRight(s[:len(s)-size], " ")
+		if font.MeasureString(face, s+ellipsis) <= limit {
+			break
+		}
+	}
+	return s + ellipsis
+}
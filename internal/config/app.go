@@ -1,7 +1,39 @@
 package config
 
 type App struct {
-	QuotesLimit int    `env:"QUOTES_LIMIT"`
-	Port        string `env:"PORT"`
-	LogLevel    string `env:"LOG_LEVEL"`
+	QuotesLimit     int    `env:"QUOTES_LIMIT"`
+	Port            string `env:"PORT"`
+	GRPCPort        string `env:"GRPC_PORT"`
+	LogLevel        string `env:"LOG_LEVEL"`
+	LogFormat       string `env:"LOG_FORMAT"`
+	MaxAuthorLength int    `env:"MAX_AUTHOR_LENGTH"`
+	MaxQuoteLength  int    `env:"MAX_QUOTE_LENGTH"`
+	MaxTags         int    `env:"MAX_TAGS"`
+	MaxTagLength    int    `env:"MAX_TAG_LENGTH"`
+	MaxBodyBytes    int    `env:"MAX_BODY_BYTES"`
+	BatchMaxSize    int    `env:"BATCH_MAX_SIZE"`
+
+	EventsReplaySize int `env:"EVENTS_REPLAY_SIZE"`
+
+	FeedSize int `env:"FEED_SIZE"`
+
+	ImageCacheSize int `env:"IMAGE_CACHE_SIZE"`
+
+	PopularityHalfLifeHours int `env:"POPULARITY_HALF_LIFE_HOURS"`
+
+	WebhookWorkers     int `env:"WEBHOOK_WORKERS"`
+	WebhookQueueSize   int `env:"WEBHOOK_QUEUE_SIZE"`
+	WebhookMaxAttempts int `env:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookBackoffMs   int `env:"WEBHOOK_BACKOFF_MS"`
+
+	GraphQLMaxDepth      int `env:"GRAPHQL_MAX_DEPTH"`
+	GraphQLMaxComplexity int `env:"GRAPHQL_MAX_COMPLEXITY"`
+
+	ScheduleIntervalSeconds int `env:"SCHEDULE_INTERVAL_SECONDS"`
+
+	ContentFilterFile          string `env:"CONTENT_FILTER_FILE"`
+	ContentFilterReloadSeconds int    `env:"CONTENT_FILTER_RELOAD_SECONDS"`
+
+	AdminAPIKey    string `env:"ADMIN_API_KEY"`
+	ReviewerAPIKey string `env:"REVIEWER_API_KEY"`
 }
@@ -9,8 +9,34 @@ import (
 )
 
 const (
-	envFilePath        = "config/.env"
-	defaultQuotesLimit = 1000
+	envFilePath            = "config/.env"
+	defaultQuotesLimit     = 1000
+	defaultMaxAuthorLength = 200
+	defaultMaxQuoteLength  = 2000
+	defaultMaxTags         = 10
+	defaultMaxTagLength    = 50
+	defaultMaxBodyBytes    = 64 << 10
+	defaultBatchMaxSize    = 100
+
+	defaultEventsReplaySize = 256
+
+	defaultFeedSize = 20
+
+	defaultImageCacheSize = 256
+
+	defaultPopularityHalfLifeHours = 7 * 24
+
+	defaultWebhookWorkers     = 4
+	defaultWebhookQueueSize   = 256
+	defaultWebhookMaxAttempts = 5
+	defaultWebhookBackoffMs   = 1000
+
+	defaultGraphQLMaxDepth      = 8
+	defaultGraphQLMaxComplexity = 500
+
+	defaultScheduleIntervalSeconds = 5
+
+	defaultContentFilterReloadSeconds = 10
 )
 
 func MustLoad() *App {
@@ -25,25 +51,65 @@ func parseConfig(filePath string) (*App, error) {
 	_ = env.LoadEnv(filePath)
 
 	port := os.Getenv("PORT")
+	grpcPort := os.Getenv("GRPC_PORT")
 	logLevel := os.Getenv("LOG_LEVEL")
+	logFormat := os.Getenv("LOG_FORMAT")
 
 	if port == "" {
 		port = "8080"
 	}
+	if grpcPort == "" {
+		grpcPort = "9090"
+	}
 	if logLevel == "" {
 		logLevel = "info"
 	}
-
-	quotesLimit := defaultQuotesLimit
-	if envLimit := os.Getenv("QUOTES_LIMIT"); envLimit != "" {
-		if v, err := strconv.Atoi(envLimit); err == nil {
-			quotesLimit = v
-		}
+	if logFormat == "" {
+		logFormat = "console"
 	}
 
 	return &App{
-		Port:        port,
-		QuotesLimit: quotesLimit,
-		LogLevel:    logLevel,
+		Port:            port,
+		GRPCPort:        grpcPort,
+		QuotesLimit:     intFromEnv("QUOTES_LIMIT", defaultQuotesLimit),
+		LogLevel:        logLevel,
+		LogFormat:       logFormat,
+		MaxAuthorLength: intFromEnv("MAX_AUTHOR_LENGTH", defaultMaxAuthorLength),
+		MaxQuoteLength:  intFromEnv("MAX_QUOTE_LENGTH", defaultMaxQuoteLength),
+		MaxTags:         intFromEnv("MAX_TAGS", defaultMaxTags),
+		MaxTagLength:    intFromEnv("MAX_TAG_LENGTH", defaultMaxTagLength),
+		MaxBodyBytes:    intFromEnv("MAX_BODY_BYTES", defaultMaxBodyBytes),
+		BatchMaxSize:    intFromEnv("BATCH_MAX_SIZE", defaultBatchMaxSize),
+
+		EventsReplaySize: intFromEnv("EVENTS_REPLAY_SIZE", defaultEventsReplaySize),
+
+		FeedSize: intFromEnv("FEED_SIZE", defaultFeedSize),
+
+		ImageCacheSize: intFromEnv("IMAGE_CACHE_SIZE", defaultImageCacheSize),
+
+		PopularityHalfLifeHours: intFromEnv("POPULARITY_HALF_LIFE_HOURS", defaultPopularityHalfLifeHours),
+
+		WebhookWorkers:     intFromEnv("WEBHOOK_WORKERS", defaultWebhookWorkers),
+		WebhookQueueSize:   intFromEnv("WEBHOOK_QUEUE_SIZE", defaultWebhookQueueSize),
+		WebhookMaxAttempts: intFromEnv("WEBHOOK_MAX_ATTEMPTS", defaultWebhookMaxAttempts),
+		WebhookBackoffMs:   intFromEnv("WEBHOOK_BACKOFF_MS", defaultWebhookBackoffMs),
+
+		GraphQLMaxDepth:      intFromEnv("GRAPHQL_MAX_DEPTH", defaultGraphQLMaxDepth),
+		GraphQLMaxComplexity: intFromEnv("GRAPHQL_MAX_COMPLEXITY", defaultGraphQLMaxComplexity),
+
+		ScheduleIntervalSeconds: intFromEnv("SCHEDULE_INTERVAL_SECONDS", defaultScheduleIntervalSeconds),
+
+		ContentFilterFile:          os.Getenv("CONTENT_FILTER_FILE"),
+		ContentFilterReloadSeconds: intFromEnv("CONTENT_FILTER_RELOAD_SECONDS", defaultContentFilterReloadSeconds),
+
+		AdminAPIKey:    os.Getenv("ADMIN_API_KEY"),
+		ReviewerAPIKey: os.Getenv("REVIEWER_API_KEY"),
 	}, nil
 }
+
+func intFromEnv(key string, defaultValue int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return v
+	}
+	return defaultValue
+}
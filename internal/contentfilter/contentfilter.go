@@ -0,0 +1,271 @@
+// Package contentfilter screens quote text against banned words, phrases
+// and regular expressions.
+//
+// Words and phrases match whole words case-insensitively. Russian words
+// match in any grammatical form: both the rule and the text are reduced to
+// stems, so a rule for "дурак" also catches "дураками".
+package contentfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Action is what happens to text matching a rule.
+type Action string
+
+const (
+	// ActionMask replaces the letters of the match with asterisks.
+	ActionMask Action = "mask"
+	// ActionFlag holds the quote for review.
+	ActionFlag Action = "flag"
+	// ActionReject refuses the quote.
+	ActionReject Action = "reject"
+)
+
+// severity orders actions, the strictest matching one applies.
+func (a Action) severity() int {
+	switch a {
+	case ActionMask:
+		return 1
+	case ActionFlag:
+		return 2
+	case ActionReject:
+		return 3
+	}
+	return 0
+}
+
+// Rule is one entry of a rule file. Words holds words and phrases of
+// several words, Patterns holds regular expressions in RE2 syntax matched
+// against the text as is.
+type Rule struct {
+	Name     string   `json:"name"`
+	Action   Action   `json:"action"`
+	Words    []string `json:"words,omitempty"`
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// Match names a rule that matched.
+type Match struct {
+	Rule   string `json:"rule"`
+	Action Action `json:"action"`
+}
+
+// Result is the outcome of Check.
+type Result struct {
+	// Text is the checked text with the matches of masking rules masked.
+	Text    string
+	Matches []Match
+}
+
+// Action returns the strictest action of the matched rules, empty when
+// none matched.
+func (r Result) Action() Action {
+	var strictest Action
+	for _, m := range r.Matches {
+		if m.Action.severity() > strictest.severity() {
+			strictest = m.Action
+		}
+	}
+	return strictest
+}
+
+// Rules returns the names of the matched rules with the given action.
+func (r Result) Rules(action Action) []string {
+	var names []string
+	for _, m := range r.Matches {
+		if m.Action == action {
+			names = append(names, m.Rule)
+		}
+	}
+	return names
+}
+
+type rule struct {
+	name     string
+	action   Action
+	phrases  [][]string
+	patterns []*regexp.Regexp
+}
+
+// Filter checks text against a fixed set of rules. It is safe for
+// concurrent use.
+type Filter struct {
+	rules []rule
+}
+
+// New compiles rules, reporting the first invalid one.
+func New(rules []Rule) (*Filter, error) {
+	f := &Filter{rules: make([]rule, 0, len(rules))}
+	for i, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule %d: name is required", i+1)
+		}
+		if r.Action.severity() == 0 {
+			return nil, fmt.Errorf("rule %q: action must be mask, flag or reject, got %q", r.Name, r.Action)
+		}
+
+		compiled := rule{name: r.Name, action: r.Action}
+		for _, w := range r.Words {
+			phrase := stems(w)
+			if len(phrase) == 0 {
+				return nil, fmt.Errorf("rule %q: word %q has no letters", r.Name, w)
+			}
+			compiled.phrases = append(compiled.phrases, phrase)
+		}
+		for _, p := range r.Patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: %w", r.Name, err)
+			}
+			compiled.patterns = append(compiled.patterns, re)
+		}
+		f.rules = append(f.rules, compiled)
+	}
+	return f, nil
+}
+
+// Parse reads a rule file, a JSON object with the list of rules:
+//
+//	{"rules": [{"name": "profanity", "action": "mask", "words": ["дурак"]}]}
+func Parse(r io.Reader) (*Filter, error) {
+	var file struct {
+		Rules []Rule `json:"rules"`
+	}
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, err
+	}
+	return New(file.Rules)
+}
+
+// Check matches text against every rule.
+func (f *Filter) Check(text string) Result {
+	res := Result{Text: text}
+	if f == nil || len(f.rules) == 0 {
+		return res
+	}
+
+	words := tokenize(text)
+	var masked [][2]int
+	for _, r := range f.rules {
+		spans := r.find(text, words)
+		if len(spans) == 0 {
+			continue
+		}
+		res.Matches = append(res.Matches, Match{Rule: r.name, Action: r.action})
+		if r.action == ActionMask {
+			masked = append(masked, spans...)
+		}
+	}
+
+	res.Text = mask(text, masked)
+	return res
+}
+
+// find returns the byte ranges of text matched by the rule.
+func (r rule) find(text string, words []word) [][2]int {
+	var spans [][2]int
+	for _, phrase := range r.phrases {
+		for i := 0; i+len(phrase) <= len(words); i++ {
+			if matches(words[i:i+len(phrase)], phrase) {
+				spans = append(spans, [2]int{words[i].start, words[i+len(phrase)-1].end})
+			}
+		}
+	}
+	for _, re := range r.patterns {
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			spans = append(spans, [2]int{loc[0], loc[1]})
+		}
+	}
+	return spans
+}
+
+func matches(words []word, phrase []string) bool {
+	for i, w := range words {
+		if w.stem != phrase[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type word struct {
+	stem       string
+	start, end int
+}
+
+// tokenize splits text into words, runs of letters and digits, keeping
+// their byte offsets.
+func tokenize(text string) []word {
+	var words []word
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			words = append(words, word{stem: stem(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, word{stem: stem(text[start:]), start: start, end: len(text)})
+	}
+	return words
+}
+
+func stems(phrase string) []string {
+	words := tokenize(phrase)
+	s := make([]string, len(words))
+	for i, w := range words {
+		s[i] = w.stem
+	}
+	return s
+}
+
+// stem lower-cases a word and reduces Russian words to their stem, other
+// words must match exactly.
+func stem(w string) string {
+	w = strings.ToLower(w)
+	for _, r := range w {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return stemRussian(w)
+		}
+	}
+	return w
+}
+
+// mask replaces letters and digits within spans with asterisks.
+func mask(text string, spans [][2]int) string {
+	if len(spans) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+	for i, r := range text {
+		if (unicode.IsLetter(r) || unicode.IsDigit(r)) && within(i, spans) {
+			b.WriteByte('*')
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func within(i int, spans [][2]int) bool {
+	for _, s := range spans {
+		if i >= s[0] && i < s[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package contentfilter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+func TestStemRussian(t *testing.T) {
+	tests := []struct {
+		words []string
+		stem  string
+	}{
+		{words: []string{"дурак", "дурака", "дураку", "дураками", "дураках"}, stem: "дурак"},
+		{words: []string{"книга", "книги", "книгой", "книгами"}, stem: "книг"},
+		{words: []string{"красивый", "красивая", "красивого", "красивыми"}, stem: "красив"},
+		{words: []string{"читать", "читали", "читающий"}, stem: "чита"},
+		{words: []string{"ёлка", "елки"}, stem: "елк"},
+	}
+	for _, tc := range tests {
+		for _, w := range tc.words {
+			if got := stemRussian(w); got != tc.stem {
+				t.Errorf("stemRussian(%q) = %q, want %q", w, got, tc.stem)
+			}
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	f, err := New([]Rule{
+		{Name: "insults", Action: ActionMask, Words: []string{"дурак", "idiot"}},
+		{Name: "spam", Action: ActionReject, Patterns: []string{`(?i)https?://\S+`}},
+		{Name: "politics", Action: ActionFlag, Words: []string{"выборы президента"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		text   string
+		masked string
+		action Action
+		rules  []string
+	}{
+		{text: "Учись у мудрых.", masked: "Учись у мудрых.", action: ""},
+		{text: "Не спорь с Дураками!", masked: "Не спорь с ********!", action: ActionMask, rules: []string{"insults"}},
+		{text: "What an IDIOT, idiots", masked: "What an *****, idiots", action: ActionMask, rules: []string{"insults"}},
+		{text: "Visit HTTP://spam.example now", masked: "Visit HTTP://spam.example now", action: ActionReject, rules: []string{"spam"}},
+		{text: "Про выборах президентов, дурак", masked: "Про выборах президентов, *****", action: ActionFlag, rules: []string{"insults", "politics"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.text, func(t *testing.T) {
+			res := f.Check(tc.text)
+			if res.Text != tc.masked {
+				t.Errorf("expected %q, got %q", tc.masked, res.Text)
+			}
+			if res.Action() != tc.action {
+				t.Errorf("expected action %q, got %q", tc.action, res.Action())
+			}
+			var rules []string
+			for _, m := range res.Matches {
+				rules = append(rules, m.Rule)
+			}
+			if !slices.Equal(rules, tc.rules) {
+				t.Errorf("expected rules %v, got %v", tc.rules, rules)
+			}
+		})
+	}
+
+	invalid := [][]Rule{
+		{{Action: ActionMask, Words: []string{"a"}}},
+		{{Name: "r", Action: "delete", Words: []string{"a"}}},
+		{{Name: "r", Action: ActionMask, Words: []string{"..."}}},
+		{{Name: "r", Action: ActionMask, Patterns: []string{"("}}},
+	}
+	for _, rules := range invalid {
+		if _, err := New(rules); err == nil {
+			t.Errorf("expected %+v to be rejected", rules)
+		}
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	write := func(content string, mtime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	start := time.Now().Add(-time.Hour)
+	write(`{"rules": [{"name": "a", "action": "mask", "words": ["foo"]}]}`, start)
+	f, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.Check("foo bar").Text; got != "*** bar" {
+		t.Errorf("unexpected text %q", got)
+	}
+
+	write(`{"rules": [{"name": "a", "action": "mask", "words": ["bar"]}]}`, start.Add(time.Minute))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		f.Watch(ctx, time.Millisecond, logger.New("error", "console"))
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for f.Check("foo bar").Text != "foo ***" {
+		if time.Now().After(deadline) {
+			t.Fatal("rules were not reloaded")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	write(`{"rules": [`, start.Add(2*time.Minute))
+	if _, err := f.Reload(); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("expected a parse error naming the file, got %v", err)
+	}
+	if got := f.Check("foo bar").Text; got != "foo ***" {
+		t.Errorf("expected the previous rules to stay, got %q", got)
+	}
+}
@@ -0,0 +1,96 @@
+package contentfilter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+// File is a Filter loaded from a rule file that picks up changes to the
+// file without a restart, see Watch. Checks always use a complete rule set:
+// a file that fails to load leaves the previous rules in place.
+type File struct {
+	path    string
+	current atomic.Pointer[Filter]
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+// Open loads the rule file at path, which must be valid.
+func Open(path string) (*File, error) {
+	f := &File{path: path}
+	if _, err := f.Reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Check matches text against the rules loaded last.
+func (f *File) Check(text string) Result {
+	return f.current.Load().Check(text)
+}
+
+// Reload reads the rule file again if it changed since the last load and
+// reports whether new rules are in effect.
+func (f *File) Reload() (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return false, err
+	}
+	if f.current.Load() != nil && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return false, nil
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	filter, err := Parse(file)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", f.path, err)
+	}
+
+	f.current.Store(filter)
+	f.modTime, f.size = info.ModTime(), info.Size()
+	return true, nil
+}
+
+// Watch checks the rule file for changes every interval until ctx is done.
+// A broken file is logged once and read again on every tick until fixed.
+func (f *File) Watch(ctx context.Context, interval time.Duration, log *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastErr string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		reloaded, err := f.Reload()
+		switch {
+		case err != nil:
+			if err.Error() != lastErr {
+				log.Error().Err(err).Msg("Failed to reload content filter rules")
+			}
+			lastErr = err.Error()
+		case reloaded:
+			lastErr = ""
+			log.Info().Str("path", f.path).Msg("Content filter rules reloaded")
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package contentfilter
+
+import (
+	"slices"
+	"strings"
+)
+
+// Suffix lists of the Snowball Russian stemmer. Suffixes of the first
+// groups only match after а or я, which stays in place.
+var (
+	gerundAfterA = []string{"в", "вши", "вшись"}
+	gerund       = []string{"ив", "ивши", "ившись", "ыв", "ывши", "ывшись"}
+
+	adjective = []string{
+		"ее", "ие", "ые", "ое", "ими", "ыми", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом",
+		"его", "ого", "ему", "ому", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею",
+	}
+	participleAfterA = []string{"ем", "нн", "вш", "ющ", "щ"}
+	participle       = []string{"ивш", "ывш", "ующ"}
+
+	reflexive = []string{"ся", "сь"}
+
+	verbAfterA = []string{"ла", "на", "ете", "йте", "ли", "й", "л", "ем", "н", "ло", "но", "ет", "ют", "ны", "ть", "ешь", "нно"}
+	verb       = []string{
+		"ила", "ыла", "ена", "ейте", "уйте", "ите", "или", "ыли", "ей", "уй", "ил", "ыл", "им", "ым", "ен",
+		"ило", "ыло", "ено", "ят", "ует", "уют", "ит", "ыт", "ены", "ить", "ыть", "ишь", "ую", "ю",
+	}
+
+	noun = []string{
+		"а", "ев", "ов", "ие", "ье", "е", "иями", "ями", "ами", "еи", "ии", "и", "ией", "ей", "ой", "ий", "й",
+		"иям", "ям", "ием", "ем", "ам", "ом", "о", "у", "ах", "иях", "ях", "ы", "ь", "ию", "ью", "ю", "ия", "ья", "я",
+	}
+
+	superlative   = []string{"ейше", "ейш"}
+	derivational  = []string{"ость", "ост"}
+	russianVowels = "аеиоуыэюя"
+)
+
+// stemRussian cuts the inflectional ending off a lower-case Russian word
+// with the Snowball algorithm, so that the forms of a word share a stem:
+// "дурак", "дурака" and "дураками" all become "дурак".
+func stemRussian(word string) string {
+	w := []rune(strings.ReplaceAll(word, "ё", "е"))
+	rv, r2 := russianRegions(w)
+	if rv >= len(w) {
+		return string(w)
+	}
+
+	// Endings are only removed from RV, the part after the first vowel.
+	head, tail := w[:rv], w[rv:]
+
+	// Step 1.
+	if t, ok := cut(tail, gerundAfterA, gerund); ok {
+		tail = t
+	} else {
+		tail, _ = cut(tail, nil, reflexive)
+		if t, ok := cut(tail, nil, adjective); ok {
+			tail, _ = cut(t, participleAfterA, participle)
+		} else if t, ok := cut(tail, verbAfterA, verb); ok {
+			tail = t
+		} else {
+			tail, _ = cut(tail, nil, noun)
+		}
+	}
+
+	// Step 2.
+	tail, _ = cut(tail, nil, []string{"и"})
+
+	// Step 3: derivational endings must also lie within R2.
+	if s := longestSuffix(tail, derivational); s != "" && rv+len(tail)-len([]rune(s)) >= r2 {
+		tail = tail[:len(tail)-len([]rune(s))]
+	}
+
+	// Step 4.
+	if t, ok := cut(tail, nil, superlative); ok {
+		tail = undouble(t)
+	} else if hasSuffix(tail, "нн") {
+		tail = undouble(tail)
+	} else {
+		tail, _ = cut(tail, nil, []string{"ь"})
+	}
+
+	return string(head) + string(tail)
+}
+
+// cut removes the longest of the suffixes of both groups from w, those
+// of afterA only when а or я precedes them.
+func cut(w []rune, afterA, plain []string) ([]rune, bool) {
+	s := longestSuffix(w, slices.Concat(afterA, plain))
+	if s == "" {
+		return w, false
+	}
+
+	n := len([]rune(s))
+	if slices.Contains(afterA, s) {
+		if len(w) <= n || (w[len(w)-n-1] != 'а' && w[len(w)-n-1] != 'я') {
+			return w, false
+		}
+	}
+	return w[:len(w)-n], true
+}
+
+func undouble(w []rune) []rune {
+	if hasSuffix(w, "нн") {
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+func longestSuffix(w []rune, suffixes []string) string {
+	longest := ""
+	for _, s := range suffixes {
+		if len(s) > len(longest) && hasSuffix(w, s) {
+			longest = s
+		}
+	}
+	return longest
+}
+
+func hasSuffix(w []rune, s string) bool {
+	return strings.HasSuffix(string(w), s)
+}
+
+// russianRegions returns the starts of RV, the part after the first vowel,
+// and R2, the part after the first consonant following a vowel in R1 which
+// is defined the same way for the whole word.
+func russianRegions(w []rune) (rv, r2 int) {
+	rv = len(w)
+	for i, r := range w {
+		if isRussianVowel(r) {
+			rv = i + 1
+			break
+		}
+	}
+
+	r1 := regionAfter(w, 0)
+	return rv, regionAfter(w, r1)
+}
+
+func regionAfter(w []rune, from int) int {
+	for i := from + 1; i < len(w); i++ {
+		if !isRussianVowel(w[i]) && isRussianVowel(w[i-1]) {
+			return i + 1
+		}
+	}
+	return len(w)
+}
+
+func isRussianVowel(r rune) bool {
+	return strings.ContainsRune(russianVowels, r)
+}
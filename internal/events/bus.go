@@ -0,0 +1,274 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+)
+
+type Type string
+
+const (
+	QuoteCreated Type = "created"
+	QuoteUpdated Type = "updated"
+	QuoteDeleted Type = "deleted"
+	QuoteEvicted Type = "evicted"
+	// QuotePublished and QuoteExpired mark the start and the end of the
+	// publication window of a scheduled quote.
+	QuotePublished Type = "published"
+	QuoteExpired   Type = "expired"
+)
+
+const (
+	DefaultSubscriberBuffer = 64
+	DefaultReplaySize       = 256
+)
+
+// Event describes a change of a single quote. Quote holds a snapshot of the
+// quote at the time of the change, Collection names the collection of the
+// quote and is empty for the default one.
+type Event struct {
+	ID         uint64      `json:"id"`
+	Type       Type        `json:"type"`
+	QuoteID    int         `json:"quote_id"`
+	Quote      model.Quote `json:"quote"`
+	Collection string      `json:"collection,omitempty"`
+	Time       time.Time   `json:"time"`
+}
+
+// Bus fans events out to subscribers. Publishing never waits for them: a
+// subscriber that cannot keep up is dropped and its channel closed, while
+// reliable subscribers queue what they have not read yet. The most recent
+// events are kept so reconnecting subscribers can resume where they left off.
+type Bus struct {
+	mu         sync.Mutex
+	subs       map[chan Event]struct{}
+	relays     map[*relay]struct{}
+	lastID     uint64
+	closed     bool
+	replay     []Event
+	replaySize int
+}
+
+type Option func(*Bus)
+
+// WithReplaySize sets how many recent events are kept for SubscribeSince.
+func WithReplaySize(n int) Option {
+	return func(b *Bus) {
+		b.replaySize = n
+	}
+}
+
+func NewBus(opts ...Option) *Bus {
+	b := &Bus{
+		subs:       make(map[chan Event]struct{}),
+		relays:     make(map[*relay]struct{}),
+		replaySize: DefaultReplaySize,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+func (b *Bus) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.lastID++
+	e.ID = b.lastID
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	if b.replaySize > 0 {
+		if len(b.replay) >= b.replaySize {
+			b.replay = append(b.replay[:0], b.replay[len(b.replay)-b.replaySize+1:]...)
+		}
+		b.replay = append(b.replay, e)
+	}
+
+	for r := range b.relays {
+		r.push(e)
+	}
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns a channel receiving every event published from now on
+// and a function that cancels the subscription.
+func (b *Bus) Subscribe(buffer int) (<-chan Event, func()) {
+	_, ch, cancel, _ := b.SubscribeSince(0, buffer)
+	return ch, cancel
+}
+
+// SubscribeReliable works like Subscribe, but the subscription is never
+// dropped: events the subscriber has not read yet wait in a queue of its
+// own, which has no bound. It is meant for internal consumers such as the
+// webhook dispatcher, not for clients. Close delivers the queued events
+// before closing the channel, cancel drops them.
+func (b *Bus) SubscribeReliable(buffer int) (<-chan Event, func()) {
+	if buffer <= 0 {
+		buffer = DefaultSubscriberBuffer
+	}
+	r := newRelay(buffer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		r.close()
+	} else {
+		b.relays[r] = struct{}{}
+	}
+	go r.run()
+
+	return r.out, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.relays[r]; ok {
+			delete(b.relays, r)
+			close(r.stop)
+		}
+	}
+}
+
+// SubscribeSince works like Subscribe and also returns the buffered events
+// published after lastID. complete is false when some of those events have
+// already left the replay buffer, the subscriber then missed changes.
+func (b *Bus) SubscribeSince(lastID uint64, buffer int) (replay []Event, ch <-chan Event, cancel func(), complete bool) {
+	if buffer <= 0 {
+		buffer = DefaultSubscriberBuffer
+	}
+	sub := make(chan Event, buffer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(sub)
+		return nil, sub, func() {}, true
+	}
+	b.subs[sub] = struct{}{}
+
+	// An ID ahead of ours comes from before a restart and is never complete.
+	complete = true
+	if lastID > 0 && lastID != b.lastID {
+		complete = lastID < b.lastID && len(b.replay) > 0 && b.replay[0].ID <= lastID+1
+
+		for _, e := range b.replay {
+			if e.ID > lastID {
+				replay = append(replay, e)
+			}
+		}
+	}
+
+	return replay, sub, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subs[sub]; ok {
+			delete(b.subs, sub)
+			close(sub)
+		}
+	}, complete
+}
+
+// Close closes every subscription, used during graceful shutdown.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for ch := range b.subs {
+		delete(b.subs, ch)
+		close(ch)
+	}
+	for r := range b.relays {
+		delete(b.relays, r)
+		r.close()
+	}
+}
+
+// relay feeds a reliable subscriber from an unbounded queue, so Publish
+// only appends to it and never blocks on the subscriber.
+type relay struct {
+	mu     sync.Mutex
+	queue  []Event
+	closed bool
+
+	wake chan struct{}
+	stop chan struct{}
+	out  chan Event
+}
+
+func newRelay(buffer int) *relay {
+	return &relay{
+		wake: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+		out:  make(chan Event, buffer),
+	}
+}
+
+func (r *relay) push(e Event) {
+	r.mu.Lock()
+	r.queue = append(r.queue, e)
+	r.mu.Unlock()
+	r.signal()
+}
+
+// close ends the relay once the queued events are delivered.
+func (r *relay) close() {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+	r.signal()
+}
+
+func (r *relay) signal() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers the queue until the relay is closed or stopped.
+func (r *relay) run() {
+	defer close(r.out)
+
+	for {
+		r.mu.Lock()
+		queue, closed := r.queue, r.closed
+		r.queue = nil
+		r.mu.Unlock()
+
+		for _, e := range queue {
+			select {
+			case r.out <- e:
+			case <-r.stop:
+				return
+			}
+		}
+		if closed {
+			return
+		}
+
+		select {
+		case <-r.wake:
+		case <-r.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package events
+
+import (
+	"testing"
+)
+
+func TestBus(t *testing.T) {
+	t.Run("Publish fans out to subscribers", func(t *testing.T) {
+		b := NewBus()
+		first, cancelFirst := b.Subscribe(1)
+		second, cancelSecond := b.Subscribe(1)
+		defer cancelFirst()
+		defer cancelSecond()
+
+		b.Publish(Event{Type: QuoteCreated, QuoteID: 1})
+
+		for _, ch := range []<-chan Event{first, second} {
+			e := <-ch
+			if e.ID != 1 || e.Type != QuoteCreated || e.Time.IsZero() {
+				t.Errorf("unexpected event: %+v", e)
+			}
+		}
+	})
+
+	t.Run("Slow subscriber is dropped", func(t *testing.T) {
+		b := NewBus()
+		ch, cancel := b.Subscribe(1)
+		defer cancel()
+
+		b.Publish(Event{Type: QuoteCreated, QuoteID: 1})
+		b.Publish(Event{Type: QuoteCreated, QuoteID: 2})
+
+		if e, ok := <-ch; !ok || e.QuoteID != 1 {
+			t.Fatalf("expected buffered event, got %+v, %v", e, ok)
+		}
+		if _, ok := <-ch; ok {
+			t.Error("expected channel to be closed")
+		}
+	})
+
+	t.Run("Reliable subscriber queues what it has not read", func(t *testing.T) {
+		b := NewBus()
+		ch, cancel := b.SubscribeReliable(1)
+		defer cancel()
+
+		// Publish must not wait for the reader.
+		for id := 1; id <= 3; id++ {
+			b.Publish(Event{Type: QuoteCreated, QuoteID: id})
+		}
+		b.Close()
+
+		for id := 1; id <= 3; id++ {
+			if e, ok := <-ch; !ok || e.QuoteID != id {
+				t.Fatalf("expected event %d, got %+v, %v", id, e, ok)
+			}
+		}
+		if _, ok := <-ch; ok {
+			t.Error("expected channel to be closed after the queue")
+		}
+	})
+
+	t.Run("Canceled reliable subscriber is closed", func(t *testing.T) {
+		b := NewBus()
+		ch, cancel := b.SubscribeReliable(1)
+
+		b.Publish(Event{Type: QuoteCreated, QuoteID: 1})
+		b.Publish(Event{Type: QuoteCreated, QuoteID: 2})
+		cancel()
+		cancel()
+
+		for range ch {
+		}
+	})
+
+	t.Run("Close ends subscriptions", func(t *testing.T) {
+		b := NewBus()
+		ch, cancel := b.Subscribe(1)
+
+		b.Close()
+		cancel()
+
+		if _, ok := <-ch; ok {
+			t.Error("expected channel to be closed")
+		}
+
+		late, _ := b.Subscribe(1)
+		if _, ok := <-late; ok {
+			t.Error("expected subscription after close to be closed")
+		}
+	})
+}
+
+func TestBusReplay(t *testing.T) {
+	b := NewBus(WithReplaySize(3))
+	for i := 1; i <= 5; i++ {
+		b.Publish(Event{Type: QuoteCreated, QuoteID: i})
+	}
+
+	t.Run("Resumes from the replay buffer", func(t *testing.T) {
+		replay, _, cancel, complete := b.SubscribeSince(3, 1)
+		defer cancel()
+
+		if !complete || len(replay) != 2 || replay[0].ID != 4 || replay[1].ID != 5 {
+			t.Errorf("unexpected replay %+v, complete %v", replay, complete)
+		}
+	})
+
+	t.Run("Reports events that left the buffer", func(t *testing.T) {
+		replay, _, cancel, complete := b.SubscribeSince(1, 1)
+		defer cancel()
+
+		if complete || len(replay) != 3 || replay[0].ID != 3 {
+			t.Errorf("unexpected replay %+v, complete %v", replay, complete)
+		}
+	})
+
+	t.Run("Unknown future ID is incomplete", func(t *testing.T) {
+		replay, _, cancel, complete := b.SubscribeSince(42, 1)
+		defer cancel()
+
+		if complete || len(replay) != 0 {
+			t.Errorf("unexpected replay %+v, complete %v", replay, complete)
+		}
+	})
+
+	t.Run("Up to date subscriber gets nothing", func(t *testing.T) {
+		replay, ch, cancel, complete := b.SubscribeSince(5, 1)
+		defer cancel()
+
+		if !complete || len(replay) != 0 {
+			t.Errorf("unexpected replay %+v, complete %v", replay, complete)
+		}
+
+		b.Publish(Event{Type: QuoteDeleted, QuoteID: 5})
+		if e := <-ch; e.ID != 6 {
+			t.Errorf("unexpected live event %+v", e)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package gql
+
+import (
+	"errors"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+)
+
+// queryError is a GraphQL error whose code is exposed in the "extensions"
+// member, mirroring the problem codes of the REST API.
+type queryError struct {
+	message string
+	code    service.Code
+	fields  []service.FieldError
+}
+
+func (e *queryError) Error() string {
+	return e.message
+}
+
+func (e *queryError) Extensions() map[string]interface{} {
+	ext := map[string]interface{}{"code": string(e.code)}
+	if len(e.fields) > 0 {
+		ext["fields"] = e.fields
+	}
+	return ext
+}
+
+// toQueryError maps service errors to GraphQL errors, hiding internal details.
+func toQueryError(err error) error {
+	var svcErr *service.Error
+	if !errors.As(err, &svcErr) || svcErr.Code == service.CodeInternal {
+		return &queryError{message: "internal error", code: service.CodeInternal}
+	}
+
+	msg := string(svcErr.Code)
+	if svcErr.Detail != "" {
+		msg = svcErr.Detail
+	}
+	return &queryError{message: msg, code: svcErr.Code, fields: svcErr.Fields}
+}
@@ -0,0 +1,140 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const DefaultMaxBodyBytes = 64 << 10
+
+const codeInvalidRequest = "invalid_request"
+
+// Request is the body of a GraphQL over HTTP POST request.
+type Request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// Handler serves GraphQL queries over the quote service.
+type Handler struct {
+	service      service.Quote
+	schema       graphql.Schema
+	logger       *logger.Logger
+	limits       Limits
+	maxBodyBytes int64
+}
+
+type Option func(*Handler)
+
+// WithLimits overrides DefaultLimits.
+func WithLimits(limits Limits) Option {
+	return func(h *Handler) {
+		h.limits = limits
+	}
+}
+
+// WithMaxBodyBytes limits the size of request bodies.
+func WithMaxBodyBytes(n int64) Option {
+	return func(h *Handler) {
+		h.maxBodyBytes = n
+	}
+}
+
+func New(svc service.Quote, logger *logger.Logger, opts ...Option) (*Handler, error) {
+	schema, err := NewSchema(svc)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &Handler{
+		service:      svc,
+		schema:       schema,
+		logger:       logger,
+		limits:       DefaultLimits(),
+		maxBodyBytes: DefaultMaxBodyBytes,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	body := http.MaxBytesReader(w, r.Body, h.maxBodyBytes)
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		status := http.StatusBadRequest
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		logger.FromContext(r.Context(), h.logger).Warn().Err(err).Msg("invalid graphql request")
+		respond(w, status, errorResult(&queryError{message: "invalid request payload", code: codeInvalidRequest}))
+		return
+	}
+
+	result := h.Execute(r.Context(), req)
+	if len(result.Errors) > 0 {
+		logger.FromContext(r.Context(), h.logger).Debug().Int("errors", len(result.Errors)).Msg("graphql query failed")
+	}
+
+	// Requests rejected before execution carry no data at all.
+	status := http.StatusOK
+	if result.Data == nil && len(result.Errors) > 0 {
+		status = http.StatusBadRequest
+	}
+	respond(w, status, result)
+}
+
+// Execute parses, validates and limits the query, then runs it with a fresh
+// author loader so every request batches its own storage calls.
+func (h *Handler) Execute(ctx context.Context, req Request) *graphql.Result {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return errorResult(err)
+	}
+
+	validation := graphql.ValidateDocument(&h.schema, doc, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	if err := checkLimits(doc, req.OperationName, req.Variables, h.limits); err != nil {
+		return errorResult(err)
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoader(ctx, newAuthorLoader(h.service)),
+	})
+}
+
+func errorResult(err error) *graphql.Result {
+	formatted := gqlerrors.FormatError(err)
+	if ext, ok := err.(gqlerrors.ExtendedError); ok {
+		formatted.Extensions = ext.Extensions()
+	}
+	return &graphql.Result{Errors: []gqlerrors.FormattedError{formatted}}
+}
+
+func respond(w http.ResponseWriter, status int, result *graphql.Result) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(result)
+}
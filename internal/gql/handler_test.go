@@ -0,0 +1,240 @@
+package gql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+// countingService records how often quotes are fetched by author.
+type countingService struct {
+	service.Quote
+	byAuthorsCalls int
+}
+
+func (s *countingService) GetByAuthors(ctx context.Context, authors []string) (map[string][]*model.Quote, error) {
+	s.byAuthorsCalls++
+	return s.Quote.GetByAuthors(ctx, authors)
+}
+
+type response struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func newTestHandler(t *testing.T, opts ...Option) (*Handler, *countingService) {
+	t.Helper()
+
+	svc := &countingService{Quote: service.NewQuoteService(storage.NewInMemory(100))}
+	h, err := New(svc, logger.New("error", "console"), opts...)
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	return h, svc
+}
+
+func do(t *testing.T, h *Handler, query string, variables map[string]interface{}) (int, response) {
+	t.Helper()
+
+	body, _ := json.Marshal(Request{Query: query, Variables: variables})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+
+	var resp response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+func TestGraphQL(t *testing.T) {
+	t.Run("Mutations and queries", func(t *testing.T) {
+		h, _ := newTestHandler(t)
+
+		_, resp := do(t, h, `mutation { createQuote(input: {author: "Seneca", quote: "Luck is preparation.", tags: ["Life"]}) { id tags } }`, nil)
+		if len(resp.Errors) > 0 || string(resp.Data["createQuote"]) != `{"id":1,"tags":["life"]}` {
+			t.Fatalf("unexpected create response %+v", resp)
+		}
+
+		_, resp = do(t, h, `mutation($id: Int!) { updateQuote(id: $id, input: {author: "Seneca", quote: "Updated"}) { quote } }`,
+			map[string]interface{}{"id": 1})
+		if string(resp.Data["updateQuote"]) != `{"quote":"Updated"}` {
+			t.Errorf("unexpected update response %+v", resp)
+		}
+
+		_, resp = do(t, h, `{ quote(id: 1) { quote author { name quoteCount } } random { id } }`, nil)
+		if string(resp.Data["quote"]) != `{"author":{"name":"Seneca","quoteCount":1},"quote":"Updated"}` {
+			t.Errorf("unexpected quote %s", resp.Data["quote"])
+		}
+
+		_, resp = do(t, h, `mutation { deleteQuote(id: 1) }`, nil)
+		if string(resp.Data["deleteQuote"]) != "true" {
+			t.Errorf("unexpected delete response %+v", resp)
+		}
+
+		_, resp = do(t, h, `{ quote(id: 1) { id } random { id } }`, nil)
+		if string(resp.Data["quote"]) != "null" || string(resp.Data["random"]) != "null" {
+			t.Errorf("expected nulls for missing quotes, got %+v", resp.Data)
+		}
+	})
+
+	t.Run("Publication window is set and kept through updates", func(t *testing.T) {
+		h, _ := newTestHandler(t)
+
+		_, resp := do(t, h, `mutation { createQuote(input: {author: "Seneca", quote: "Q", expireAt: "2099-01-01T00:00:00Z"}) { expireAt publishAt } }`, nil)
+		if len(resp.Errors) > 0 || string(resp.Data["createQuote"]) != `{"expireAt":"2099-01-01T00:00:00Z","publishAt":null}` {
+			t.Fatalf("unexpected create response %+v", resp)
+		}
+
+		_, resp = do(t, h, `mutation { updateQuote(id: 1, input: {author: "Seneca", quote: "Updated", expireAt: "2099-06-01T00:00:00Z"}) { expireAt } }`, nil)
+		if string(resp.Data["updateQuote"]) != `{"expireAt":"2099-06-01T00:00:00Z"}` {
+			t.Errorf("unexpected update response %+v", resp)
+		}
+
+		_, resp = do(t, h, `mutation { createQuote(input: {author: "Seneca", quote: "Q", expireAt: "soon"}) { id } }`, nil)
+		if len(resp.Errors) == 0 {
+			t.Errorf("expected an invalid time to be refused, got %+v", resp)
+		}
+	})
+
+	t.Run("Random quote in a language serves its translation", func(t *testing.T) {
+		h, svc := newTestHandler(t)
+		ctx := context.Background()
+
+		if _, err := svc.Create(ctx, &model.Quote{Author: "Seneca", Quote: "While we wait for life, life passes."}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := svc.AddTranslation(ctx, 1, &model.Translation{Lang: "ru", Quote: "Пока мы откладываем жизнь, она проходит."}); err != nil {
+			t.Fatal(err)
+		}
+
+		_, resp := do(t, h, `{ random(lang: "ru") { quote lang translatedFrom translator } quote(id: 1) { status translations { lang } } }`, nil)
+		if string(resp.Data["random"]) != `{"lang":"ru","quote":"Пока мы откладываем жизнь, она проходит.","translatedFrom":"en","translator":null}` {
+			t.Errorf("unexpected random quote %s", resp.Data["random"])
+		}
+		if string(resp.Data["quote"]) != `{"status":"published","translations":[{"lang":"ru"}]}` {
+			t.Errorf("unexpected quote %s", resp.Data["quote"])
+		}
+	})
+
+	t.Run("Service errors carry codes", func(t *testing.T) {
+		h, _ := newTestHandler(t)
+
+		_, resp := do(t, h, `mutation { createQuote(input: {author: "", quote: ""}) { id } }`, nil)
+		if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != string(service.CodeValidation) {
+			t.Fatalf("expected validation error, got %+v", resp.Errors)
+		}
+		if fields, _ := resp.Errors[0].Extensions["fields"].([]interface{}); len(fields) != 2 {
+			t.Errorf("expected 2 field errors, got %v", resp.Errors[0].Extensions)
+		}
+
+		_, resp = do(t, h, `mutation { deleteQuote(id: 42) }`, nil)
+		if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != string(service.CodeNotFound) {
+			t.Errorf("expected not found error, got %+v", resp.Errors)
+		}
+	})
+
+	t.Run("Quotes paginate with filters", func(t *testing.T) {
+		h, _ := newTestHandler(t)
+		for _, a := range []string{"A", "B", "A", "A"} {
+			do(t, h, `mutation($a: String!) { createQuote(input: {author: $a, quote: "Q"}) { id } }`, map[string]interface{}{"a": a})
+		}
+
+		var ids []int
+		after := ""
+		for pages := 0; ; pages++ {
+			if pages > 3 {
+				t.Fatal("too many pages")
+			}
+			_, resp := do(t, h, `query($after: String) { quotes(author: "a", first: 2, after: $after) { nodes { id } pageInfo { hasNextPage endCursor } } }`,
+				map[string]interface{}{"after": after})
+
+			var conn struct {
+				Nodes    []struct{ ID int }
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   string
+				}
+			}
+			if err := json.Unmarshal(resp.Data["quotes"], &conn); err != nil {
+				t.Fatalf("decode %+v: %v", resp, err)
+			}
+			for _, n := range conn.Nodes {
+				ids = append(ids, n.ID)
+			}
+			if !conn.PageInfo.HasNextPage {
+				break
+			}
+			after = conn.PageInfo.EndCursor
+		}
+
+		if len(ids) != 3 || ids[0] != 1 || ids[1] != 3 || ids[2] != 4 {
+			t.Errorf("unexpected ids %v", ids)
+		}
+	})
+
+	t.Run("Authors are loaded in one batch", func(t *testing.T) {
+		h, svc := newTestHandler(t)
+		for _, a := range []string{"A", "B", "C", "A", "B"} {
+			do(t, h, `mutation($a: String!) { createQuote(input: {author: $a, quote: "Q"}) { id } }`, map[string]interface{}{"a": a})
+		}
+
+		_, resp := do(t, h, `{ quotes { nodes { id author { name quoteCount quotes(first: 5) { id } } } } }`, nil)
+		if len(resp.Errors) > 0 {
+			t.Fatalf("unexpected errors %+v", resp.Errors)
+		}
+		if !strings.Contains(string(resp.Data["quotes"]), `{"author":{"name":"B","quoteCount":2,"quotes":[{"id":2},{"id":5}]},"id":2}`) {
+			t.Errorf("unexpected quotes %s", resp.Data["quotes"])
+		}
+		if svc.byAuthorsCalls != 1 {
+			t.Errorf("expected 1 batched author lookup, got %d", svc.byAuthorsCalls)
+		}
+	})
+
+	t.Run("Depth and complexity limits", func(t *testing.T) {
+		h, _ := newTestHandler(t, WithLimits(Limits{MaxDepth: 4, MaxComplexity: 100}))
+
+		code, resp := do(t, h, `{ random { author { quotes { author { name } } } } }`, nil)
+		if code != http.StatusBadRequest || len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != codeQueryTooComplex {
+			t.Errorf("expected depth error, got %d %+v", code, resp)
+		}
+
+		query := `query($n: Int) { quotes(first: $n) { ...nodes } } fragment nodes on QuoteConnection { nodes { id quote } }`
+		code, resp = do(t, h, query, map[string]interface{}{"n": 50})
+		if code != http.StatusBadRequest || len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "complexity") {
+			t.Errorf("expected complexity error, got %d %+v", code, resp)
+		}
+
+		code, resp = do(t, h, query, map[string]interface{}{"n": 10})
+		if code != http.StatusOK || len(resp.Errors) > 0 {
+			t.Errorf("expected query within limits to pass, got %d %+v", code, resp)
+		}
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		h, _ := newTestHandler(t)
+
+		code, resp := do(t, h, `{ quotes { nodes { nope } } }`, nil)
+		if code != http.StatusBadRequest || len(resp.Errors) == 0 {
+			t.Errorf("expected validation error, got %d %+v", code, resp)
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("{")))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 for malformed body, got %d", rec.Code)
+		}
+	})
+}
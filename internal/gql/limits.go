@@ -0,0 +1,142 @@
+package gql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	DefaultMaxDepth      = 8
+	DefaultMaxComplexity = 500
+)
+
+const codeQueryTooComplex = "query_too_complex"
+
+// Limits bounds the shape of accepted queries, zero values disable a check.
+// Complexity counts one per field, multiplied by the page size of every
+// enclosing list field that takes a "first" argument.
+type Limits struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+func DefaultLimits() Limits {
+	return Limits{
+		MaxDepth:      DefaultMaxDepth,
+		MaxComplexity: DefaultMaxComplexity,
+	}
+}
+
+// analysis walks a single operation, following fragment spreads.
+type analysis struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// checkLimits measures the selected operation of an already validated
+// document, so fragment cycles are impossible here.
+func checkLimits(doc *ast.Document, operationName string, variables map[string]interface{}, limits Limits) error {
+	a := &analysis{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+	}
+
+	var op *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			a.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				op = def
+			}
+		}
+	}
+	if op == nil {
+		return nil
+	}
+
+	depth, complexity := a.measure(op.SelectionSet)
+	if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+		return &queryError{
+			message: fmt.Sprintf("query depth %d exceeds the limit of %d", depth, limits.MaxDepth),
+			code:    codeQueryTooComplex,
+		}
+	}
+	if limits.MaxComplexity > 0 && complexity > limits.MaxComplexity {
+		return &queryError{
+			message: fmt.Sprintf("query complexity %d exceeds the limit of %d", complexity, limits.MaxComplexity),
+			code:    codeQueryTooComplex,
+		}
+	}
+	return nil
+}
+
+// measure returns the depth and complexity of a selection set.
+func (a *analysis) measure(set *ast.SelectionSet) (depth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	for _, sel := range set.Selections {
+		var d, c int
+		switch sel := sel.(type) {
+		case *ast.Field:
+			// Introspection is bounded by the schema, not by the data.
+			if strings.HasPrefix(sel.Name.Value, "__") {
+				continue
+			}
+			childDepth, childComplexity := a.measure(sel.SelectionSet)
+			d = childDepth + 1
+			c = 1 + childComplexity*a.multiplier(sel)
+		case *ast.InlineFragment:
+			d, c = a.measure(sel.SelectionSet)
+		case *ast.FragmentSpread:
+			if frag, ok := a.fragments[sel.Name.Value]; ok {
+				d, c = a.measure(frag.SelectionSet)
+			}
+		}
+
+		depth = max(depth, d)
+		complexity += c
+	}
+	return depth, complexity
+}
+
+// multiplier is the number of items a list field may return.
+func (a *analysis) multiplier(field *ast.Field) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil && n > 0 {
+				return n
+			}
+		case *ast.Variable:
+			if n, ok := toInt(a.variables[v.Name.Value]); ok && n > 0 {
+				return n
+			}
+		}
+		return defaultPageSize
+	}
+
+	if field.Name.Value == "quotes" {
+		return defaultPageSize
+	}
+	return 1
+}
+
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,83 @@
+package gql
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+)
+
+type loaderKey struct{}
+
+// authorLoader batches quote lookups by author within one request. Resolvers
+// queue an author and return a thunk; the executor runs thunks level by
+// level, so the first thunk of a level fetches every queued author at once.
+type authorLoader struct {
+	service service.Quote
+
+	mu      sync.Mutex
+	pending []string
+	loaded  map[string][]*model.Quote
+	err     error
+}
+
+func newAuthorLoader(svc service.Quote) *authorLoader {
+	return &authorLoader{
+		service: svc,
+		loaded:  make(map[string][]*model.Quote),
+	}
+}
+
+func withLoader(ctx context.Context, l *authorLoader) context.Context {
+	return context.WithValue(ctx, loaderKey{}, l)
+}
+
+func loaderFromContext(ctx context.Context) *authorLoader {
+	l, _ := ctx.Value(loaderKey{}).(*authorLoader)
+	return l
+}
+
+// load queues author and returns a thunk resolving to its quotes.
+func (l *authorLoader) load(ctx context.Context, author string) func() ([]*model.Quote, error) {
+	key := strings.ToLower(author)
+
+	l.mu.Lock()
+	if _, ok := l.loaded[key]; !ok {
+		l.pending = append(l.pending, author)
+	}
+	l.mu.Unlock()
+
+	return func() ([]*model.Quote, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if quotes, ok := l.loaded[key]; ok {
+			return quotes, nil
+		}
+		if len(l.pending) > 0 {
+			l.flush(ctx)
+		}
+		if l.err != nil {
+			return nil, l.err
+		}
+		return l.loaded[key], nil
+	}
+}
+
+func (l *authorLoader) flush(ctx context.Context) {
+	authors := l.pending
+	l.pending = nil
+
+	quotes, err := l.service.GetByAuthors(ctx, authors)
+	if err != nil {
+		l.err = err
+		return
+	}
+
+	for _, a := range authors {
+		key := strings.ToLower(a)
+		l.loaded[key] = quotes[key]
+	}
+}
@@ -0,0 +1,424 @@
+package gql
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// author is the value behind the Author type, its quotes come from the loader.
+type author struct {
+	name string
+}
+
+type pageInfo struct {
+	HasNextPage bool
+	EndCursor   string
+}
+
+type connection struct {
+	Nodes    []*model.Quote
+	PageInfo pageInfo
+}
+
+// NewSchema builds the GraphQL schema over the quote service.
+func NewSchema(svc service.Quote) (graphql.Schema, error) {
+	r := &resolver{service: svc}
+
+	authorType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Author",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"name": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.String),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(*author).name, nil
+					},
+				},
+				"quotes": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(r.quoteType))),
+					Args: graphql.FieldConfigArgument{
+						"first": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+					},
+					Resolve: r.authorQuotes,
+				},
+				"quoteCount": &graphql.Field{
+					Type:    graphql.NewNonNull(graphql.Int),
+					Resolve: r.authorQuoteCount,
+				},
+			}
+		}),
+	})
+
+	translationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Translation",
+		Fields: graphql.Fields{
+			"lang":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"quote": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"translator": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nullable(p.Source.(model.Translation).Translator), nil
+				},
+			},
+		},
+	})
+
+	r.quoteType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Quote",
+		Fields: graphql.Fields{
+			"id":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"quote": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"author": &graphql.Field{
+				Type: graphql.NewNonNull(authorType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return &author{name: p.Source.(*model.Quote).Author}, nil
+				},
+			},
+			"tags": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if tags := p.Source.(*model.Quote).Tags; tags != nil {
+						return tags, nil
+					}
+					return []string{}, nil
+				},
+			},
+			"lang": &graphql.Field{
+				Type:        graphql.String,
+				Description: "ISO 639-1 language code, null when unknown",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nullable(p.Source.(*model.Quote).Lang), nil
+				},
+			},
+			"status": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Moderation state: draft, pending, published or rejected",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nullable(string(p.Source.(*model.Quote).Status)), nil
+				},
+			},
+			"publishAt": &graphql.Field{
+				Type:        graphql.DateTime,
+				Description: "Start of the publication window, null when the quote is served at once",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nullableTime(p.Source.(*model.Quote).PublishAt), nil
+				},
+			},
+			"expireAt": &graphql.Field{
+				Type:        graphql.DateTime,
+				Description: "End of the publication window, null when the quote does not expire",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nullableTime(p.Source.(*model.Quote).ExpireAt), nil
+				},
+			},
+			"translations": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(translationType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if translations := p.Source.(*model.Quote).Translations; translations != nil {
+						return translations, nil
+					}
+					return []model.Translation{}, nil
+				},
+			},
+			"translatedFrom": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Language of the original when the quote is served translated, see random(lang)",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nullable(p.Source.(*model.Quote).TranslatedFrom), nil
+				},
+			},
+			"translator": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nullable(p.Source.(*model.Quote).Translator), nil
+				},
+			},
+		},
+	})
+
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"endCursor":   &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "QuoteConnection",
+		Fields: graphql.Fields{
+			"nodes":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(r.quoteType)))},
+			"pageInfo": &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+		},
+	})
+
+	inputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "QuoteInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"author": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"quote":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"tags":   &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"lang":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"publishAt": &graphql.InputObjectFieldConfig{
+				Type:        graphql.DateTime,
+				Description: "Start of the publication window, an update without it serves the quote at once",
+			},
+			"expireAt": &graphql.InputObjectFieldConfig{
+				Type:        graphql.DateTime,
+				Description: "End of the publication window, an update without it keeps the quote for good",
+			},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"quote": &graphql.Field{
+				Type: r.quoteType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: r.quote,
+			},
+			"quotes": &graphql.Field{
+				Type: graphql.NewNonNull(connectionType),
+				Args: graphql.FieldConfigArgument{
+					"author": &graphql.ArgumentConfig{Type: graphql.String},
+					"tag":    &graphql.ArgumentConfig{Type: graphql.String},
+					"lang":   &graphql.ArgumentConfig{Type: graphql.String},
+					"first":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+					"after":  &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: r.quotes,
+			},
+			"random": &graphql.Field{
+				Type: r.quoteType,
+				Args: graphql.FieldConfigArgument{
+					"lang": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: r.random,
+			},
+			"author": &graphql.Field{
+				Type: authorType,
+				Args: graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: r.author,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createQuote": &graphql.Field{
+				Type: graphql.NewNonNull(r.quoteType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(inputType)},
+				},
+				Resolve: r.createQuote,
+			},
+			"updateQuote": &graphql.Field{
+				Type: graphql.NewNonNull(r.quoteType),
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(inputType)},
+				},
+				Resolve: r.updateQuote,
+			},
+			"deleteQuote": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: r.deleteQuote,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+type resolver struct {
+	service   service.Quote
+	quoteType *graphql.Object
+}
+
+func (r *resolver) quote(p graphql.ResolveParams) (interface{}, error) {
+	q, err := r.service.Get(p.Context, p.Args["id"].(int))
+	if errors.Is(err, service.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+	return q, nil
+}
+
+func (r *resolver) quotes(p graphql.ResolveParams) (interface{}, error) {
+	first, err := pageSize(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	after, _ := p.Args["after"].(string)
+	afterID, err := service.DecodeCursor(after)
+	if err != nil {
+		return nil, toQueryError(service.NewValidationError(service.FieldError{
+			Field: "after", Code: "invalid", Message: "must be a cursor returned by a previous page",
+		}))
+	}
+
+	filter := storage.QuoteFilter{AfterID: afterID, Limit: first + 1}
+	filter.Author, _ = p.Args["author"].(string)
+	filter.Tag, _ = p.Args["tag"].(string)
+	filter.Lang, _ = p.Args["lang"].(string)
+
+	quotes, err := r.service.Find(p.Context, filter)
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+
+	conn := &connection{Nodes: quotes}
+	if len(quotes) > first {
+		conn.Nodes = quotes[:first]
+		conn.PageInfo.HasNextPage = true
+	}
+	if n := len(conn.Nodes); n > 0 {
+		conn.PageInfo.EndCursor = service.EncodeCursor(conn.Nodes[n-1].ID)
+	}
+	return conn, nil
+}
+
+func (r *resolver) random(p graphql.ResolveParams) (interface{}, error) {
+	lang, _ := p.Args["lang"].(string)
+	q, err := r.service.FindRandom(p.Context, storage.QuoteFilter{Lang: lang})
+	if errors.Is(err, service.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+	return q, nil
+}
+
+func (r *resolver) author(p graphql.ResolveParams) (interface{}, error) {
+	return &author{name: service.NormalizeAuthor(p.Args["name"].(string))}, nil
+}
+
+func (r *resolver) authorQuotes(p graphql.ResolveParams) (interface{}, error) {
+	first, err := pageSize(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	load := loaderFromContext(p.Context).load(p.Context, p.Source.(*author).name)
+	return func() (interface{}, error) {
+		quotes, err := load()
+		if err != nil {
+			return nil, toQueryError(err)
+		}
+		if len(quotes) > first {
+			quotes = quotes[:first]
+		}
+		return quotes, nil
+	}, nil
+}
+
+func (r *resolver) authorQuoteCount(p graphql.ResolveParams) (interface{}, error) {
+	load := loaderFromContext(p.Context).load(p.Context, p.Source.(*author).name)
+	return func() (interface{}, error) {
+		quotes, err := load()
+		if err != nil {
+			return nil, toQueryError(err)
+		}
+		return len(quotes), nil
+	}, nil
+}
+
+func (r *resolver) createQuote(p graphql.ResolveParams) (interface{}, error) {
+	created, err := r.service.Create(p.Context, quoteFromInput(p.Args["input"]))
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+	return created, nil
+}
+
+func (r *resolver) updateQuote(p graphql.ResolveParams) (interface{}, error) {
+	updated, err := r.service.Update(p.Context, p.Args["id"].(int), quoteFromInput(p.Args["input"]))
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+	return updated, nil
+}
+
+func (r *resolver) deleteQuote(p graphql.ResolveParams) (interface{}, error) {
+	if err := r.service.Delete(p.Context, p.Args["id"].(int)); err != nil {
+		return nil, toQueryError(err)
+	}
+	return true, nil
+}
+
+// nullable maps empty strings to null.
+func nullable(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func nullableTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+func quoteFromInput(v interface{}) *model.Quote {
+	input := v.(map[string]interface{})
+
+	q := &model.Quote{}
+	q.Author, _ = input["author"].(string)
+	q.Quote, _ = input["quote"].(string)
+	q.Lang, _ = input["lang"].(string)
+	q.PublishAt, _ = input["publishAt"].(time.Time)
+	q.ExpireAt, _ = input["expireAt"].(time.Time)
+	if tags, ok := input["tags"].([]interface{}); ok {
+		for _, t := range tags {
+			if tag, ok := t.(string); ok {
+				q.Tags = append(q.Tags, tag)
+			}
+		}
+	}
+	return q
+}
+
+func pageSize(args map[string]interface{}) (int, error) {
+	first, ok := args["first"].(int)
+	if !ok {
+		return defaultPageSize, nil
+	}
+	if first < 1 || first > maxPageSize {
+		return 0, toQueryError(service.NewValidationError(service.FieldError{
+			Field:   "first",
+			Code:    "out_of_range",
+			Message: fmt.Sprintf("must be between 1 and %d", maxPageSize),
+		}))
+	}
+	return first, nil
+}
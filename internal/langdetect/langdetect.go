@@ -0,0 +1,177 @@
+// Package langdetect guesses the language of short texts such as quotes.
+//
+// The model counts character n-grams of up to three letters in the
+// embedded corpus, one sample text per language named by its ISO 639-1
+// code, and scores a text with naive Bayes over the same n-grams.
+package langdetect
+
+import (
+	"embed"
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//go:embed corpus/*.txt
+var corpus embed.FS
+
+const (
+	maxN = 3
+
+	// minLetters is the shortest text worth a guess.
+	minLetters = 6
+	// minMargin is the lead in average log-likelihood per n-gram the best
+	// language needs over the runner-up, below it the text is ambiguous.
+	minMargin = 0.1
+)
+
+type profile struct {
+	lang   string
+	counts map[string]int
+	totals [maxN + 1]int
+}
+
+type model struct {
+	profiles []profile
+	vocab    [maxN + 1]int
+}
+
+var (
+	loadOnce sync.Once
+	loaded   *model
+)
+
+// Detect returns the ISO 639-1 code of the language of text, or "" when
+// the text is too short or not clearly in one of Languages.
+func Detect(text string) string {
+	return defaultModel().detect(text)
+}
+
+// Languages lists the codes Detect can return, sorted.
+func Languages() []string {
+	m := defaultModel()
+	langs := make([]string, 0, len(m.profiles))
+	for _, p := range m.profiles {
+		langs = append(langs, p.lang)
+	}
+	return langs
+}
+
+// defaultModel trains the model on first use, the corpus is part of the
+// binary so it cannot fail.
+func defaultModel() *model {
+	loadOnce.Do(func() {
+		files, err := corpus.ReadDir("corpus")
+		if err != nil {
+			panic(err)
+		}
+
+		samples := make(map[string]string, len(files))
+		for _, f := range files {
+			b, err := corpus.ReadFile(path.Join("corpus", f.Name()))
+			if err != nil {
+				panic(err)
+			}
+			samples[strings.TrimSuffix(f.Name(), ".txt")] = string(b)
+		}
+		loaded = train(samples)
+	})
+	return loaded
+}
+
+func train(samples map[string]string) *model {
+	m := &model{}
+	seen := [maxN + 1]map[string]bool{}
+	for n := 1; n <= maxN; n++ {
+		seen[n] = make(map[string]bool)
+	}
+
+	for lang, text := range samples {
+		p := profile{lang: lang, counts: make(map[string]int)}
+		for _, g := range ngrams(text) {
+			n := len([]rune(g))
+			p.counts[g]++
+			p.totals[n]++
+			seen[n][g] = true
+		}
+		m.profiles = append(m.profiles, p)
+	}
+	for n := 1; n <= maxN; n++ {
+		m.vocab[n] = len(seen[n])
+	}
+
+	slices.SortFunc(m.profiles, func(a, b profile) int {
+		return strings.Compare(a.lang, b.lang)
+	})
+	return m
+}
+
+func (m *model) detect(text string) string {
+	grams := ngrams(text)
+
+	letters := 0
+	for _, g := range grams {
+		if len([]rune(g)) == 1 {
+			letters++
+		}
+	}
+	if letters < minLetters || len(m.profiles) == 0 {
+		return ""
+	}
+
+	best, second := math.Inf(-1), math.Inf(-1)
+	bestLang := ""
+	for _, p := range m.profiles {
+		score := m.logLikelihood(p, grams)
+		switch {
+		case score > best:
+			best, second = score, best
+			bestLang = p.lang
+		case score > second:
+			second = score
+		}
+	}
+
+	if len(m.profiles) > 1 && (best-second)/float64(len(grams)) < minMargin {
+		return ""
+	}
+	return bestLang
+}
+
+// logLikelihood scores grams under p with add-one smoothing, so n-grams
+// missing from the sample are unlikely rather than impossible.
+func (m *model) logLikelihood(p profile, grams []string) float64 {
+	var score float64
+	for _, g := range grams {
+		n := len([]rune(g))
+		score += math.Log(float64(p.counts[g]+1) / float64(p.totals[n]+m.vocab[n]+1))
+	}
+	return score
+}
+
+// ngrams lower-cases text, splits it into words of letters and returns
+// their n-grams of length 1 to maxN. Words are padded with spaces so
+// prefixes and suffixes count as n-grams of their own.
+func ngrams(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	var grams []string
+	for _, w := range words {
+		runes := []rune(" " + w + " ")
+		for n := 1; n <= maxN; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				g := string(runes[i : i+n])
+				if g == " " {
+					continue
+				}
+				grams = append(grams, g)
+			}
+		}
+	}
+	return grams
+}
@@ -0,0 +1,42 @@
+package langdetect
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tt := []struct {
+		text string
+		want string
+	}{
+		{text: "Life is really simple, but we insist on making it complicated.", want: "en"},
+		{text: "To be or not to be", want: "en"},
+		{text: "Тот, кто хочет, ищет возможности.", want: "ru"},
+		{text: "Чем дальше в лес, тем больше дров.", want: "ru"},
+		{text: "Хто хоче, той шукає можливості.", want: "uk"},
+		{text: "Що маємо — не бережемо, втративши — плачемо.", want: "uk"},
+		{text: "Ich denke, also bin ich.", want: "de"},
+		{text: "Je pense, donc je suis.", want: "fr"},
+		{text: "Pienso, luego existo.", want: "es"},
+		{text: "La vita è bella.", want: "it"},
+		{text: "ok", want: ""},
+		{text: "12345 !!!", want: ""},
+		{text: "Hakuna matata", want: ""},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.text, func(t *testing.T) {
+			if got := Detect(tc.text); got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	want := []string{"de", "en", "es", "fr", "it", "ru", "uk"}
+	if got := Languages(); !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Permissions an API key can be granted on its collection.
+const (
+	PermissionRead  = "read"
+	PermissionWrite = "write"
+)
+
+// Collection is an isolated namespace of quotes with its own ID sequence
+// and quote limit.
+type Collection struct {
+	Name        string    `json:"name"`
+	QuotesLimit int       `json:"quotes_limit"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// APIKey grants access to one collection. Only the hash of the key is
+// stored, Key is set once when the key is issued.
+type APIKey struct {
+	ID          int       `json:"id"`
+	Collection  string    `json:"collection"`
+	Name        string    `json:"name,omitempty"`
+	Permissions []string  `json:"permissions"`
+	Key         string    `json:"key,omitempty"`
+	Hash        string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Allows reports whether the key was granted permission.
+func (k *APIKey) Allows(permission string) bool {
+	return slices.Contains(k.Permissions, permission)
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Playlist is a curated set of quotes in a fixed order. QuoteIDs lists the
+// members in that order, each quote at most once.
+type Playlist struct {
+	ID          int       `json:"id,omitempty"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	QuoteIDs    []int     `json:"quote_ids"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at,omitzero"`
+}
+
+// Position returns the index of the quote in the playlist, -1 when it is
+// not a member.
+func (p *Playlist) Position(quoteID int) int {
+	return slices.Index(p.QuoteIDs, quoteID)
+}
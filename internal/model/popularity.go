@@ -0,0 +1,24 @@
+package model
+
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Popularity aggregates the likes and ratings a quote received, every user
+// counts once for each.
+type Popularity struct {
+	QuoteID       int     `json:"quote_id"`
+	Likes         int     `json:"likes"`
+	Ratings       int     `json:"ratings"`
+	AverageRating float64 `json:"average_rating"`
+	// Score is the time-decayed popularity the top listing is ordered by,
+	// only set there.
+	Score float64 `json:"score,omitempty"`
+}
+
+// RankedQuote is an entry of the top listing.
+type RankedQuote struct {
+	Quote      *Quote     `json:"quote"`
+	Popularity Popularity `json:"popularity"`
+}
@@ -1,7 +1,94 @@
 package model
 
+import "time"
+
 type Quote struct {
-	ID     int    `json:"id,omitempty"`
-	Author string `json:"author"`
-	Quote  string `json:"quote"`
+	ID        int       `json:"id,omitempty"`
+	Author    string    `json:"author"`
+	Quote     string    `json:"quote"`
+	Tags      []string  `json:"tags,omitempty"`
+	Lang      string    `json:"lang,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+	// PublishAt and ExpireAt bound the time the quote is served, see Live.
+	PublishAt time.Time `json:"publish_at,omitzero"`
+	ExpireAt  time.Time `json:"expire_at,omitzero"`
+
+	Status Status `json:"status,omitempty"`
+	// RejectReason explains a rejection, ReviewedAt is the time of the last
+	// approval or rejection.
+	RejectReason string    `json:"reject_reason,omitempty"`
+	ReviewedAt   time.Time `json:"reviewed_at,omitzero"`
+	// Flags names the content filter rules that held the quote for review.
+	Flags []string `json:"flags,omitempty"`
+
+	Translations []Translation `json:"translations,omitempty"`
+	// TranslatedFrom and Translator are set on quotes served in the language
+	// of one of their translations, see Localize.
+	TranslatedFrom string `json:"translated_from,omitempty"`
+	Translator     string `json:"translator,omitempty"`
+}
+
+// Translation is the text of a quote in another language.
+type Translation struct {
+	Lang       string    `json:"lang"`
+	Quote      string    `json:"quote"`
+	Translator string    `json:"translator,omitempty"`
+	CreatedAt  time.Time `json:"created_at,omitzero"`
+}
+
+// HasTag reports whether tag is among the quote's normalised tags.
+func (q *Quote) HasTag(tag string) bool {
+	for _, t := range q.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// ModifiedAt is the time of the last change, the creation time for quotes
+// that were never updated.
+func (q *Quote) ModifiedAt() time.Time {
+	if q.UpdatedAt.After(q.CreatedAt) {
+		return q.UpdatedAt
+	}
+	return q.CreatedAt
+}
+
+// Live reports whether t lies in the publication window of the quote:
+// PublishAt, when set, has passed and ExpireAt, when set, has not.
+func (q *Quote) Live(t time.Time) bool {
+	return !t.Before(q.PublishAt) && (q.ExpireAt.IsZero() || t.Before(q.ExpireAt))
+}
+
+// Translation returns the translation of the quote into lang.
+func (q *Quote) Translation(lang string) (Translation, bool) {
+	for _, t := range q.Translations {
+		if t.Lang == lang {
+			return t, true
+		}
+	}
+	return Translation{}, false
+}
+
+// Localize returns the quote as it reads in lang: the quote itself when it
+// is written in lang or has no such translation, otherwise a copy carrying
+// the translated text.
+func (q *Quote) Localize(lang string) *Quote {
+	if q.Lang == lang {
+		return q
+	}
+	t, ok := q.Translation(lang)
+	if !ok {
+		return q
+	}
+
+	localized := *q
+	localized.Quote = t.Quote
+	localized.Lang = t.Lang
+	localized.Translations = nil
+	localized.TranslatedFrom = q.Lang
+	localized.Translator = t.Translator
+	return &localized
 }
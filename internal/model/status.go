@@ -0,0 +1,22 @@
+package model
+
+// Status is the moderation state of a quote. Drafts are submitted for
+// review, reviewers publish or reject pending quotes and rejected ones can
+// be submitted again.
+type Status string
+
+const (
+	StatusDraft     Status = "draft"
+	StatusPending   Status = "pending"
+	StatusPublished Status = "published"
+	StatusRejected  Status = "rejected"
+)
+
+// Valid reports whether s is one of the known statuses.
+func (s Status) Valid() bool {
+	switch s {
+	case StatusDraft, StatusPending, StatusPublished, StatusRejected:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook is a subscription to quote change events. Events lists the event
+// types to deliver, all of them when empty. Secret signs every delivery.
+type Webhook struct {
+	ID        int       `json:"id,omitempty"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events,omitempty"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Subscribed reports whether the webhook wants events of type t.
+func (w *Webhook) Subscribed(t string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// DeadLetter is a delivery that failed after every retry. Payload is kept
+// verbatim so a replay sends the same body.
+type DeadLetter struct {
+	ID         int             `json:"id,omitempty"`
+	WebhookID  int             `json:"webhook_id"`
+	DeliveryID string          `json:"delivery_id"`
+	EventType  string          `json:"event_type"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempts   int             `json:"attempts"`
+	LastStatus int             `json:"last_status,omitempty"`
+	LastError  string          `json:"last_error"`
+	FailedAt   time.Time       `json:"failed_at"`
+}
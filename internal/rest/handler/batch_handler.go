@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+)
+
+const (
+	errBatch   = "failed to apply batch"
+	errBatchOp = "operation failed"
+)
+
+// batchInput is the accepted request body, batches are atomic unless
+// Atomic is false.
+type batchInput struct {
+	Atomic     *bool          `json:"atomic"`
+	Operations []batchOpInput `json:"operations"`
+}
+
+type batchOpInput struct {
+	Op     service.BatchAction `json:"op"`
+	ID     int                 `json:"id"`
+	TempID string              `json:"temp_id"`
+	Quote  *model.Quote        `json:"quote"`
+}
+
+type batchResponse struct {
+	Atomic  bool          `json:"atomic"`
+	Results []batchResult `json:"results"`
+}
+
+// batchResult mirrors the response of the single operation endpoint: the
+// status it would return and either the quote or the problem.
+type batchResult struct {
+	Index  int                 `json:"index"`
+	Op     service.BatchAction `json:"op"`
+	TempID string              `json:"temp_id,omitempty"`
+	Status int                 `json:"status"`
+	Quote  *model.Quote        `json:"quote,omitempty"`
+	Error  *Problem            `json:"error,omitempty"`
+}
+
+var batchStatuses = map[service.BatchAction]int{
+	service.BatchCreate: http.StatusCreated,
+	service.BatchUpdate: http.StatusOK,
+	service.BatchDelete: http.StatusNoContent,
+}
+
+// Batch applies a list of create, update and delete operations. A failed
+// atomic batch responds with the problem of the failed operation, otherwise
+// every operation gets its own result.
+func (h *QuoteHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	var in batchInput
+	if !h.decodeJSON(w, r, &in) {
+		return
+	}
+	atomic := in.Atomic == nil || *in.Atomic
+
+	ops := make([]service.BatchOp, len(in.Operations))
+	for i, op := range in.Operations {
+		ops[i] = service.BatchOp{Action: op.Op, ID: op.ID, TempID: op.TempID, Quote: op.Quote}
+	}
+
+	results, err := h.service.Batch(r.Context(), ops, atomic)
+	if err != nil {
+		h.respondServiceError(w, r, errBatch, err)
+		return
+	}
+
+	resp := batchResponse{Atomic: atomic, Results: make([]batchResult, len(results))}
+	for i, res := range results {
+		op := in.Operations[i]
+		result := batchResult{Index: i, Op: op.Op, TempID: op.TempID, Status: batchStatuses[op.Op]}
+		switch {
+		case res.Err != nil:
+			result.Error = problemFromError(r, res.Err, errBatchOp)
+			result.Status = result.Error.Status
+		case op.Op != service.BatchDelete:
+			result.Quote = res.Quote
+		}
+		resp.Results[i] = result
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const (
+	errCreateCollection = "failed to create collection"
+	errGetCollections   = "failed to get collections"
+	errDeleteCollection = "failed to delete collection"
+	errCreateAPIKey     = "failed to create API key"
+	errGetAPIKeys       = "failed to get API keys"
+	errDeleteAPIKey     = "failed to delete API key"
+	errGetAPIKeyID      = "failed to get API key id"
+	errAuthorize        = "failed to authorize request"
+)
+
+// collectionInput is the accepted request body of collection creation,
+// a zero quotes_limit takes the server default.
+type collectionInput struct {
+	Name        string `json:"name"`
+	QuotesLimit int    `json:"quotes_limit"`
+}
+
+type apiKeyInput struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+// CollectionHandler serves collection and API key management to the admin
+// key and the quote routes of every collection to keys granted access.
+type CollectionHandler struct {
+	base
+	service service.Collection
+}
+
+func NewCollections(service service.Collection, logger *logger.Logger, opts ...Option) *CollectionHandler {
+	return &CollectionHandler{
+		base:    newBase(logger, opts),
+		service: service,
+	}
+}
+
+func (h *CollectionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	var in collectionInput
+	if !h.decodeJSON(w, r, &in) {
+		return
+	}
+
+	created, err := h.service.Create(r.Context(), &model.Collection{Name: in.Name, QuotesLimit: in.QuotesLimit})
+	if err != nil {
+		h.respondServiceError(w, r, errCreateCollection, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, created)
+}
+
+func (h *CollectionHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	collections, err := h.service.List(r.Context())
+	if err != nil {
+		h.respondServiceError(w, r, errGetCollections, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, collections)
+}
+
+func (h *CollectionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), mux.Vars(r)["collection"]); err != nil {
+		h.respondServiceError(w, r, errDeleteCollection, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateKey issues an API key, the key is in the response only once.
+func (h *CollectionHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	var in apiKeyInput
+	if !h.decodeJSON(w, r, &in) {
+		return
+	}
+
+	created, err := h.service.CreateKey(r.Context(), &model.APIKey{
+		Collection:  mux.Vars(r)["collection"],
+		Name:        in.Name,
+		Permissions: in.Permissions,
+	})
+	if err != nil {
+		h.respondServiceError(w, r, errCreateAPIKey, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, created)
+}
+
+func (h *CollectionHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	keys, err := h.service.ListKeys(r.Context(), mux.Vars(r)["collection"])
+	if err != nil {
+		h.respondServiceError(w, r, errGetAPIKeys, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, keys)
+}
+
+func (h *CollectionHandler) DeleteKey(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, errGetAPIKeyID), err)
+		return
+	}
+
+	if err := h.service.DeleteKey(r.Context(), mux.Vars(r)["collection"], id); err != nil {
+		h.respondServiceError(w, r, errDeleteAPIKey, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Quotes serves a QuoteHandler method against the collection in the path
+// once the bearer key is granted permission on it.
+func (h *CollectionHandler) Quotes(method func(*QuoteHandler, http.ResponseWriter, *http.Request), permission string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)["collection"]
+
+		if err := h.service.Authorize(r.Context(), bearerToken(r), name, permission); err != nil {
+			h.respondAuthError(w, r, err)
+			return
+		}
+
+		quotes, err := h.service.Quotes(r.Context(), name)
+		if err != nil {
+			h.respondServiceError(w, r, errAuthorize, err)
+			return
+		}
+
+		method(&QuoteHandler{base: h.base, service: quotes}, w, r)
+	}
+}
+
+// Moderation serves a ModerationHandler method against the collection in
+// the path. Submission needs permission from the bearer key, the review
+// routes pass an empty permission and are left to the reviewer key check
+// of the method itself.
+func (h *CollectionHandler) Moderation(method func(*ModerationHandler, http.ResponseWriter, *http.Request), permission string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)["collection"]
+
+		if permission != "" {
+			if err := h.service.Authorize(r.Context(), bearerToken(r), name, permission); err != nil {
+				h.respondAuthError(w, r, err)
+				return
+			}
+		}
+
+		moderation, err := h.service.Moderation(r.Context(), name)
+		if err != nil {
+			h.respondServiceError(w, r, errAuthorize, err)
+			return
+		}
+
+		method(&ModerationHandler{base: h.base, service: moderation}, w, r)
+	}
+}
+
+func (h *CollectionHandler) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if err := h.service.AuthorizeAdmin(bearerToken(r)); err != nil {
+		h.respondAuthError(w, r, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const (
+	headerLastEventID = "Last-Event-ID"
+	paramLastEventID  = "last_event_id"
+
+	// eventReset tells a resuming client that events were lost and it
+	// should reload the collection instead of applying the replay.
+	eventReset = "reset"
+
+	DefaultKeepAlive = 15 * time.Second
+
+	sseRetry          = 3 * time.Second
+	wsWriteTimeout    = 5 * time.Second
+	wsCloseGracePause = time.Second
+)
+
+// Feed is the source of quote change events, implemented by events.Bus.
+type Feed interface {
+	SubscribeSince(lastID uint64, buffer int) (replay []events.Event, ch <-chan events.Event, cancel func(), complete bool)
+}
+
+// EventsHandler streams quote changes over Server-Sent Events and WebSocket.
+// Streams end when the feed closes their subscription, which happens during
+// graceful shutdown or when the client cannot keep up.
+type EventsHandler struct {
+	feed      Feed
+	logger    *logger.Logger
+	keepAlive time.Duration
+	upgrader  websocket.Upgrader
+}
+
+type EventsOption func(*EventsHandler)
+
+// WithKeepAlive sets the interval of SSE comments and WebSocket pings that
+// keep idle connections open through proxies.
+func WithKeepAlive(d time.Duration) EventsOption {
+	return func(h *EventsHandler) {
+		h.keepAlive = d
+	}
+}
+
+func NewEvents(feed Feed, logger *logger.Logger, opts ...EventsOption) *EventsHandler {
+	h := &EventsHandler{
+		feed:      feed,
+		logger:    logger,
+		keepAlive: DefaultKeepAlive,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// eventFilter keeps events of one author and/or tag, empty fields match all.
+// Quotes of collections are private to their keys and never streamed.
+type eventFilter struct {
+	author string
+	tag    string
+}
+
+func newEventFilter(r *http.Request) eventFilter {
+	q := r.URL.Query()
+	f := eventFilter{author: service.NormalizeAuthor(q.Get("author"))}
+	if tag := q.Get("tag"); tag != "" {
+		f.tag = service.NormalizeTag(tag)
+	}
+	return f
+}
+
+func (f eventFilter) match(e events.Event) bool {
+	if e.Collection != "" {
+		return false
+	}
+	if f.author != "" && !strings.EqualFold(e.Quote.Author, f.author) {
+		return false
+	}
+	if f.tag != "" && !e.Quote.HasTag(f.tag) {
+		return false
+	}
+	return true
+}
+
+// lastEventID reads the resume position from the Last-Event-ID header sent
+// by EventSource or from a query parameter, which WebSocket clients can set.
+func lastEventID(r *http.Request) uint64 {
+	v := r.Header.Get(headerLastEventID)
+	if v == "" {
+		v = r.URL.Query().Get(paramLastEventID)
+	}
+	id, _ := strconv.ParseUint(v, 10, 64)
+	return id
+}
+
+func (h *EventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	filter := newEventFilter(r)
+	replay, ch, cancel, complete := h.feed.SubscribeSince(lastEventID(r), 0)
+	defer cancel()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+	if !complete {
+		fmt.Fprintf(w, "event: %s\ndata: {}\n\n", eventReset)
+	}
+	for _, e := range replay {
+		if filter.match(e) {
+			writeSSE(w, e)
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		h.log(r).Warn().Err(err).Msg("event stream not supported")
+		return
+	}
+
+	ticker := time.NewTicker(h.keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if !filter.match(e) {
+				continue
+			}
+			writeSSE(w, e)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, e events.Event) {
+	data, _ := json.Marshal(e)
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+}
+
+func (h *EventsHandler) WebSocket(w http.ResponseWriter, r *http.Request) {
+	filter := newEventFilter(r)
+
+	// Subscribing first means no event is missed once the client sees the
+	// handshake complete.
+	replay, ch, cancel, complete := h.feed.SubscribeSince(lastEventID(r), 0)
+	defer cancel()
+
+	// The handshake is written directly to the connection, headers set by
+	// middleware (request ID, deprecation) are passed on explicitly.
+	conn, err := h.upgrader.Upgrade(w, r, w.Header())
+	if err != nil {
+		// Upgrade has already replied to the client.
+		h.log(r).Warn().Err(err).Msg("websocket upgrade failed")
+		return
+	}
+	defer conn.Close()
+
+	// The client sends nothing, reading only processes control frames and
+	// notices when it goes away.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(v interface{}) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		return conn.WriteJSON(v) == nil
+	}
+
+	if !complete && !send(map[string]string{"type": eventReset}) {
+		return
+	}
+	for _, e := range replay {
+		if filter.match(e) && !send(e) {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(h.keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		case e, ok := <-ch:
+			if !ok {
+				h.closeWebSocket(conn, closed)
+				return
+			}
+			if filter.match(e) && !send(e) {
+				return
+			}
+		}
+	}
+}
+
+// closeWebSocket performs the closing handshake, waiting briefly for the
+// client to answer before the connection is dropped.
+func (h *EventsHandler) closeWebSocket(conn *websocket.Conn, closed <-chan struct{}) {
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "stream closed")
+	if err := conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsWriteTimeout)); err != nil {
+		return
+	}
+
+	select {
+	case <-closed:
+	case <-time.After(wsCloseGracePause):
+	}
+}
+
+func (h *EventsHandler) log(r *http.Request) *logger.Logger {
+	return logger.FromContext(r.Context(), h.logger)
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+func newEventsServer(t *testing.T, bus *events.Bus) *httptest.Server {
+	t.Helper()
+
+	h := NewEvents(bus, logger.New("error", "console"))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/quotes/events", h.Stream)
+	mux.HandleFunc("/quotes/events/ws", h.WebSocket)
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// readSSE returns the next event of the stream as a field map, skipping
+// comments and the retry preamble.
+func readSSE(t *testing.T, r *bufio.Reader) map[string]string {
+	t.Helper()
+
+	for {
+		fields := make(map[string]string)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read stream: %v", err)
+			}
+			line = strings.TrimRight(line, "\n")
+			if line == "" {
+				break
+			}
+			if name, value, ok := strings.Cut(line, ": "); ok && name != "" {
+				fields[name] = value
+			}
+		}
+		if fields["event"] != "" {
+			return fields
+		}
+	}
+}
+
+func TestEventsHandler(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("SSE resumes from Last-Event-ID and filters by author and collection", func(t *testing.T) {
+		bus := events.NewBus()
+		svc := service.NewQuoteService(storage.NewInMemory(10), service.WithPublisher(bus))
+		srv := newEventsServer(t, bus)
+
+		_, _ = svc.Create(ctx, &model.Quote{Author: "Seneca", Quote: "Q1"})
+		_, _ = svc.Create(ctx, &model.Quote{Author: "Seneca", Quote: "Q2"})
+
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/quotes/events?author=seneca", nil)
+		req.Header.Set("Last-Event-ID", "1")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("unexpected content type %q", ct)
+		}
+
+		r := bufio.NewReader(resp.Body)
+		if e := readSSE(t, r); e["id"] != "2" || e["event"] != "created" {
+			t.Errorf("expected replayed event 2, got %v", e)
+		}
+
+		_, _ = svc.Create(ctx, &model.Quote{Author: "Confucius", Quote: "Q3"})
+		bus.Publish(events.Event{Type: events.QuoteCreated, QuoteID: 1, Quote: model.Quote{ID: 1, Author: "Seneca"}, Collection: "team-a"})
+		_ = svc.Delete(ctx, 2)
+
+		e := readSSE(t, r)
+		var event events.Event
+		if err := json.Unmarshal([]byte(e["data"]), &event); err != nil {
+			t.Fatalf("decode %q: %v", e["data"], err)
+		}
+		if e["event"] != "deleted" || event.ID != 5 || event.QuoteID != 2 || event.Quote.Author != "Seneca" {
+			t.Errorf("unexpected live event %v", e)
+		}
+
+		bus.Close()
+		if _, err := io.ReadAll(r); err != nil {
+			t.Errorf("expected stream to end cleanly, got %v", err)
+		}
+	})
+
+	t.Run("SSE asks to reset when events were lost", func(t *testing.T) {
+		bus := events.NewBus(events.WithReplaySize(1))
+		srv := newEventsServer(t, bus)
+		bus.Publish(events.Event{Type: events.QuoteCreated, QuoteID: 1})
+		bus.Publish(events.Event{Type: events.QuoteCreated, QuoteID: 2})
+		bus.Publish(events.Event{Type: events.QuoteCreated, QuoteID: 3})
+
+		resp, err := http.Get(srv.URL + "/quotes/events?last_event_id=1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		r := bufio.NewReader(resp.Body)
+		if e := readSSE(t, r); e["event"] != "reset" {
+			t.Errorf("expected reset, got %v", e)
+		}
+		if e := readSSE(t, r); e["id"] != "3" {
+			t.Errorf("expected buffered event 3, got %v", e)
+		}
+		bus.Close()
+	})
+
+	t.Run("WebSocket streams tagged events and closes on shutdown", func(t *testing.T) {
+		bus := events.NewBus()
+		svc := service.NewQuoteService(storage.NewInMemory(10), service.WithPublisher(bus))
+		srv := newEventsServer(t, bus)
+
+		url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/quotes/events/ws?tag=Stoic"
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		defer conn.Close()
+
+		created, _ := svc.Create(ctx, &model.Quote{Author: "Seneca", Quote: "Q1", Tags: []string{"stoic"}})
+		_, _ = svc.Create(ctx, &model.Quote{Author: "Confucius", Quote: "Q2"})
+		_, _ = svc.Update(ctx, created.ID, &model.Quote{Author: "Seneca", Quote: "Q1 updated", Tags: []string{"stoic"}})
+
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		for _, want := range []events.Type{events.QuoteCreated, events.QuoteUpdated} {
+			var e events.Event
+			if err := conn.ReadJSON(&e); err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if e.Type != want || e.QuoteID != created.ID {
+				t.Errorf("expected %s of quote %d, got %+v", want, created.ID, e)
+			}
+		}
+
+		bus.Close()
+		_, _, err = conn.ReadMessage()
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseGoingAway {
+			t.Errorf("expected going away close, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,320 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const (
+	errGetFeed = "failed to get feed"
+
+	ContentTypeRSS  = "application/rss+xml; charset=utf-8"
+	ContentTypeAtom = "application/atom+xml; charset=utf-8"
+
+	DefaultFeedSize = 20
+
+	feedTitle      = "Quotebook"
+	feedGUIDPrefix = "urn:quotebook:quote:"
+	feedIDPrefix   = "urn:quotebook:feed:quotes"
+	atomNamespace  = "http://www.w3.org/2005/Atom"
+
+	// maxTitleRunes bounds the quote excerpt used as item title, readers show
+	// the full text from the description.
+	maxTitleRunes = 80
+)
+
+// Finder looks up quotes by filter, implemented by service.QuoteService.
+type Finder interface {
+	Find(ctx context.Context, filter storage.QuoteFilter) ([]*model.Quote, error)
+}
+
+// FeedHandler serves the most recent quotes as RSS 2.0 and Atom feeds. Both
+// carry ETag and Last-Modified so readers can poll with conditional requests.
+type FeedHandler struct {
+	base
+	finder Finder
+	size   int
+}
+
+type FeedOption func(*FeedHandler)
+
+// WithFeedSize sets the number of items in a feed.
+func WithFeedSize(n int) FeedOption {
+	return func(h *FeedHandler) {
+		h.size = n
+	}
+}
+
+func NewFeeds(finder Finder, logger *logger.Logger, opts ...FeedOption) *FeedHandler {
+	h := &FeedHandler{
+		base:   newBase(logger, nil),
+		finder: finder,
+		size:   DefaultFeedSize,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.size < 1 {
+		h.size = DefaultFeedSize
+	}
+	return h
+}
+
+// feed is the format independent content of a feed.
+type feed struct {
+	title    string
+	id       string
+	link     string
+	self     string
+	quotes   []*model.Quote
+	modified time.Time
+}
+
+func (h *FeedHandler) RSS(w http.ResponseWriter, r *http.Request) {
+	f, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+
+	doc := rssFeed{
+		Version: "2.0",
+		AtomNS:  atomNamespace,
+		Channel: rssChannel{
+			Title:       f.title,
+			Link:        f.link,
+			Self:        atomLink{Href: f.self, Rel: "self", Type: "application/rss+xml"},
+			Description: "Recently added quotes",
+		},
+	}
+	if !f.modified.IsZero() {
+		doc.Channel.LastBuildDate = f.modified.Format(time.RFC1123Z)
+	}
+	for _, q := range f.quotes {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       itemTitle(q),
+			Description: q.Quote,
+			Categories:  q.Tags,
+			GUID:        rssGUID{IsPermaLink: "false", Value: feedGUID(q)},
+			PubDate:     q.CreatedAt.Format(time.RFC1123Z),
+		})
+	}
+
+	h.write(w, r, ContentTypeRSS, f.modified, doc)
+}
+
+func (h *FeedHandler) Atom(w http.ResponseWriter, r *http.Request) {
+	f, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+
+	// Atom requires an update time, an empty feed has never changed.
+	updated := f.modified
+	if updated.IsZero() {
+		updated = time.Unix(0, 0)
+	}
+
+	doc := atomFeed{
+		Title:   f.title,
+		ID:      f.id,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.self, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.link, Rel: "alternate", Type: "application/json"},
+		},
+	}
+	for _, q := range f.quotes {
+		entry := atomEntry{
+			Title:     itemTitle(q),
+			ID:        feedGUID(q),
+			Published: q.CreatedAt.UTC().Format(time.RFC3339),
+			Updated:   q.ModifiedAt().UTC().Format(time.RFC3339),
+			Author:    atomPerson{Name: q.Author},
+			Content:   atomText{Type: "text", Value: q.Quote},
+		}
+		for _, tag := range q.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	h.write(w, r, ContentTypeAtom, f.modified, doc)
+}
+
+// load collects the newest quotes matching the author and tag parameters.
+func (h *FeedHandler) load(w http.ResponseWriter, r *http.Request) (*feed, bool) {
+	params := r.URL.Query()
+	author, tag := params.Get("author"), params.Get("tag")
+
+	quotes, err := h.finder.Find(r.Context(), storage.QuoteFilter{Author: author, Tag: tag})
+	if err != nil {
+		h.respondServiceError(w, r, errGetFeed, err)
+		return nil, false
+	}
+
+	// Quotes come in ascending ID order, which is the order they were added.
+	if len(quotes) > h.size {
+		quotes = quotes[len(quotes)-h.size:]
+	}
+	newest := make([]*model.Quote, 0, len(quotes))
+	for i := len(quotes) - 1; i >= 0; i-- {
+		newest = append(newest, quotes[i])
+	}
+
+	f := &feed{
+		title:  feedTitle,
+		id:     feedIDPrefix,
+		quotes: newest,
+	}
+	filter := url.Values{}
+	if author != "" {
+		f.title += ": " + author
+		f.id += ":author:" + url.QueryEscape(author)
+		filter.Set("author", author)
+	}
+	if tag != "" {
+		f.title += " #" + tag
+		f.id += ":tag:" + url.QueryEscape(tag)
+		filter.Set("tag", tag)
+	}
+
+	origin := requestOrigin(r)
+	f.link = origin + "/v1/quotes"
+	if len(filter) > 0 {
+		f.link += "?" + filter.Encode()
+	}
+	f.self = origin + r.URL.RequestURI()
+
+	for _, q := range newest {
+		if m := q.ModifiedAt(); m.After(f.modified) {
+			f.modified = m
+		}
+	}
+	return f, true
+}
+
+// write renders doc and serves it through http.ServeContent, which answers
+// If-None-Match and If-Modified-Since with 304. The ETag is derived from the
+// rendered document, so it changes whenever an item is added, edited or
+// removed.
+func (h *FeedHandler) write(w http.ResponseWriter, r *http.Request, contentType string, modified time.Time, doc interface{}) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(doc); err != nil {
+		h.respondServiceError(w, r, errGetFeed, err)
+		return
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", strconv.Quote(hex.EncodeToString(sum[:16])))
+
+	http.ServeContent(w, r, "", modified, bytes.NewReader(buf.Bytes()))
+}
+
+// feedGUID identifies a quote across feed formats and polls, IDs are never
+// reused so the GUID stays stable for the lifetime of the quote.
+func feedGUID(q *model.Quote) string {
+	return feedGUIDPrefix + strconv.Itoa(q.ID)
+}
+
+func itemTitle(q *model.Quote) string {
+	text := q.Quote
+	if utf8.RuneCountInString(text) > maxTitleRunes {
+		runes := []rune(text)
+		text = string(runes[:maxTitleRunes-1]) + "…"
+	}
+	return q.Author + ": " + text
+}
+
+// requestOrigin rebuilds scheme and host as seen by the client, honouring
+// X-Forwarded-Proto set by a TLS terminating proxy.
+func requestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Self          atomLink  `xml:"atom:link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Description string   `xml:"description"`
+	Categories  []string `xml:"category"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     atomPerson     `xml:"author"`
+	Content    atomText       `xml:"content"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
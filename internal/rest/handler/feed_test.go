@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+func TestFeedHandler(t *testing.T) {
+	ctx := context.Background()
+	log := logger.New("error", "console")
+
+	svc := service.NewQuoteService(storage.NewInMemory(10))
+	for _, q := range []*model.Quote{
+		{Author: "Seneca", Quote: "Luck is what happens when preparation meets opportunity.", Tags: []string{"luck"}},
+		{Author: "Confucius", Quote: "Life is really simple."},
+		{Author: "Seneca", Quote: "We suffer more in imagination than in reality."},
+		{Author: "Seneca", Quote: "While we teach, we learn.", Tags: []string{"learning"}},
+	} {
+		if _, err := svc.Create(ctx, q); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := NewFeeds(svc, log, WithFeedSize(2))
+
+	get := func(t *testing.T, serve http.HandlerFunc, target string, header http.Header) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		serve(rec, req)
+		return rec
+	}
+
+	t.Run("RSS lists newest quotes of the author with stable GUIDs", func(t *testing.T) {
+		rec := get(t, h.RSS, "/quotes/feed.rss?author=seneca", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != ContentTypeRSS {
+			t.Errorf("expected content type %s, got %s", ContentTypeRSS, ct)
+		}
+
+		var doc rssFeed
+		if err := xml.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("invalid RSS: %v", err)
+		}
+		items := doc.Channel.Items
+		if len(items) != 2 {
+			t.Fatalf("expected 2 items, got %d", len(items))
+		}
+		if items[0].GUID.Value != "urn:quotebook:quote:4" || items[1].GUID.Value != "urn:quotebook:quote:3" {
+			t.Errorf("unexpected GUIDs %q, %q", items[0].GUID.Value, items[1].GUID.Value)
+		}
+		if items[0].Description != "While we teach, we learn." || len(items[0].Categories) != 1 {
+			t.Errorf("unexpected item %+v", items[0])
+		}
+		// Decoding cannot tell <link> from <atom:link>, check the raw document.
+		if !strings.Contains(rec.Body.String(), "<link>http://example.com/v1/quotes?author=seneca</link>") {
+			t.Errorf("expected channel link to the filtered quotes, got %s", rec.Body.String())
+		}
+	})
+
+	t.Run("Atom filters by tag", func(t *testing.T) {
+		rec := get(t, h.Atom, "/quotes/feed.atom?tag=Luck", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+
+		var doc atomFeed
+		if err := xml.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("invalid Atom: %v", err)
+		}
+		if len(doc.Entries) != 1 || doc.Entries[0].ID != "urn:quotebook:quote:1" || doc.Entries[0].Author.Name != "Seneca" {
+			t.Errorf("unexpected entries %+v", doc.Entries)
+		}
+	})
+
+	t.Run("Conditional requests are answered with 304", func(t *testing.T) {
+		first := get(t, h.Atom, "/quotes/feed.atom", nil)
+		etag, modified := first.Header().Get("ETag"), first.Header().Get("Last-Modified")
+		if etag == "" || modified == "" {
+			t.Fatalf("expected ETag and Last-Modified, got %q and %q", etag, modified)
+		}
+
+		rec := get(t, h.Atom, "/quotes/feed.atom", http.Header{"If-None-Match": {etag}})
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("expected status 304 for If-None-Match, got %d", rec.Code)
+		}
+		rec = get(t, h.Atom, "/quotes/feed.atom", http.Header{"If-Modified-Since": {modified}})
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("expected status 304 for If-Modified-Since, got %d", rec.Code)
+		}
+
+		if err := svc.Delete(ctx, 4); err != nil {
+			t.Fatal(err)
+		}
+		rec = get(t, h.Atom, "/quotes/feed.atom", http.Header{"If-None-Match": {etag}})
+		if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+			t.Errorf("expected a fresh feed with a new ETag after delete, got %d %s", rec.Code, rec.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("Empty feed has no Last-Modified", func(t *testing.T) {
+		rec := get(t, h.RSS, "/quotes/feed.rss?author=nobody", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if lm := rec.Header().Get("Last-Modified"); lm != "" {
+			t.Errorf("expected no Last-Modified, got %q", lm)
+		}
+	})
+}
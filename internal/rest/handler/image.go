@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/card"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const (
+	errRenderImage = "failed to render quote image"
+
+	ContentTypeSVG = "image/svg+xml"
+	ContentTypePNG = "image/png"
+
+	// svgPolicy keeps a served SVG from running anything but its own styles
+	// and embedded font.
+	svgPolicy = "default-src 'none'; style-src 'unsafe-inline'; font-src data:"
+)
+
+// ImageHandler renders quotes as shareable SVG and PNG cards.
+type ImageHandler struct {
+	base
+	service  service.Quote
+	renderer *card.Renderer
+}
+
+func NewImages(service service.Quote, renderer *card.Renderer, logger *logger.Logger) *ImageHandler {
+	return &ImageHandler{
+		base:     newBase(logger, nil),
+		service:  service,
+		renderer: renderer,
+	}
+}
+
+func (h *ImageHandler) SVG(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, card.FormatSVG, ContentTypeSVG)
+}
+
+func (h *ImageHandler) PNG(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, card.FormatPNG, ContentTypePNG)
+}
+
+func (h *ImageHandler) serve(w http.ResponseWriter, r *http.Request, format card.Format, contentType string) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, errGetID), err)
+		return
+	}
+
+	opts, err := imageOptions(r)
+	if err == nil {
+		err = opts.Validate()
+	}
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidParam, err.Error()), err)
+		return
+	}
+
+	quote, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		h.respondServiceError(w, r, errGetQuote, err)
+		return
+	}
+
+	img, err := h.renderer.Render(quote, format, opts)
+	if err != nil {
+		h.respondServiceError(w, r, errRenderImage, err)
+		return
+	}
+
+	// The ETag follows the quote content, so clients revalidate cheaply
+	// and pick up edits immediately.
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", img.ETag)
+	w.Header().Set("Cache-Control", "public, no-cache")
+	if format == card.FormatSVG {
+		w.Header().Set("Content-Security-Policy", svgPolicy)
+	}
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(img.Data))
+}
+
+// imageOptions reads width, height, theme and font over card defaults.
+func imageOptions(r *http.Request) (card.Options, error) {
+	params := r.URL.Query()
+	opts := card.DefaultOptions()
+
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"width", &opts.Width}, {"height", &opts.Height}} {
+		if v := params.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return opts, fmt.Errorf("%s must be an integer", p.name)
+			}
+			*p.dst = n
+		}
+	}
+	if v := params.Get("theme"); v != "" {
+		opts.Theme = v
+	}
+	if v := params.Get("font"); v != "" {
+		opts.Font = v
+	}
+	return opts, nil
+}
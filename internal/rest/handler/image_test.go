@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/card"
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+func TestImageHandler(t *testing.T) {
+	ctx := context.Background()
+
+	svc := service.NewQuoteService(storage.NewInMemory(10))
+	if _, err := svc.Create(ctx, &model.Quote{Author: "Сенека", Quote: "Пока мы учим, мы учимся."}); err != nil {
+		t.Fatal(err)
+	}
+	renderer, err := card.NewRenderer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewImages(svc, renderer, logger.New("error", "console"))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/quotes/{id:[0-9]+}/image.svg", h.SVG)
+	router.HandleFunc("/quotes/{id:[0-9]+}/image.png", h.PNG)
+
+	get := func(target string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Images are revalidated by content ETag", func(t *testing.T) {
+		rec := get("/quotes/1/image.png?theme=dark&font=italic", nil)
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != ContentTypePNG {
+			t.Fatalf("expected PNG, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		etag := rec.Header().Get("ETag")
+
+		rec = get("/quotes/1/image.png?theme=dark&font=italic", http.Header{"If-None-Match": {etag}})
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("expected status 304, got %d", rec.Code)
+		}
+
+		if _, err := svc.Update(ctx, 1, &model.Quote{Author: "Сенека", Quote: "Учась, мы учим."}); err != nil {
+			t.Fatal(err)
+		}
+		rec = get("/quotes/1/image.png?theme=dark&font=italic", http.Header{"If-None-Match": {etag}})
+		if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+			t.Errorf("expected a new image after update, got %d", rec.Code)
+		}
+	})
+
+	t.Run("SVG cannot run scripts", func(t *testing.T) {
+		rec := get("/quotes/1/image.svg", nil)
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != ContentTypeSVG {
+			t.Fatalf("expected SVG, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		if rec.Header().Get("Content-Security-Policy") != svgPolicy {
+			t.Errorf("unexpected policy %q", rec.Header().Get("Content-Security-Policy"))
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tt := []struct {
+			target string
+			status int
+			code   string
+		}{
+			{target: "/quotes/1/image.png?width=abc", status: http.StatusBadRequest, code: codeInvalidParam},
+			{target: "/quotes/1/image.png?height=9000", status: http.StatusBadRequest, code: codeInvalidParam},
+			{target: "/quotes/1/image.svg?theme=neon", status: http.StatusBadRequest, code: codeInvalidParam},
+			{target: "/quotes/42/image.svg", status: http.StatusNotFound, code: string(service.CodeNotFound)},
+		}
+
+		for _, tc := range tt {
+			rec := get(tc.target, nil)
+			if rec.Code != tc.status || rec.Header().Get("Content-Type") != ContentTypeProblem {
+				t.Errorf("%s: expected %d problem, got %d %s", tc.target, tc.status, rec.Code, rec.Body.String())
+			}
+		}
+	})
+}
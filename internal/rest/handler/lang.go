@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+
+	"golang.org/x/text/language"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+)
+
+const errInvalidLang = "lang must be an ISO 639-1 language code"
+
+// langParam returns the normalised lang query parameter, empty when it is
+// absent, and responds with a problem when it is invalid.
+func (h *base) langParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	v := r.URL.Query().Get("lang")
+	if v == "" {
+		return "", true
+	}
+
+	lang, ok := service.ParseLang(v)
+	if !ok {
+		h.respondError(w, r, newProblem(r, codeInvalidParam, errInvalidLang), nil)
+		return "", false
+	}
+	return lang, true
+}
+
+// acceptedLangs lists the languages of the Accept-Language header as
+// ISO 639-1 codes, most preferred first. Regions are dropped, so en-GB and
+// en-US both ask for en.
+func acceptedLangs(r *http.Request) []string {
+	header := r.Header.Get("Accept-Language")
+	if header == "" {
+		return nil
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil {
+		return nil
+	}
+
+	var langs []string
+	for _, tag := range tags {
+		base, confidence := tag.Base()
+		if confidence == language.No {
+			continue
+		}
+		lang, ok := service.ParseLang(base.String())
+		if ok && !slices.Contains(langs, lang) {
+			langs = append(langs, lang)
+		}
+	}
+	return langs
+}
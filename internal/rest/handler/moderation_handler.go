@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const (
+	errSubmitQuote      = "failed to submit quote"
+	errApproveQuote     = "failed to approve quote"
+	errRejectQuote      = "failed to reject quote"
+	errGetPendingQuotes = "failed to get pending quotes"
+)
+
+type rejectInput struct {
+	Reason string `json:"reason"`
+}
+
+// ModerationHandler serves submission of quotes for review to everyone and
+// the review queue to the reviewer key.
+type ModerationHandler struct {
+	base
+	service service.Moderation
+}
+
+func NewModeration(service service.Moderation, logger *logger.Logger, opts ...Option) *ModerationHandler {
+	return &ModerationHandler{
+		base:    newBase(logger, opts),
+		service: service,
+	}
+}
+
+// Submit sends a draft or rejected quote to review.
+func (h *ModerationHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, errGetID), err)
+		return
+	}
+
+	quote, err := h.service.Submit(r.Context(), id)
+	if err != nil {
+		h.respondServiceError(w, r, errSubmitQuote, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quote)
+}
+
+// Queue lists pending quotes oldest first, one page of limit at a time. The
+// next page is linked with a Link header.
+func (h *ModerationHandler) Queue(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeReviewer(w, r) {
+		return
+	}
+
+	params := r.URL.Query()
+
+	limit := DefaultListLimit
+	if v := params.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxListLimit {
+			h.respondError(w, r, newProblem(r, codeInvalidParam, errInvalidLimit), err)
+			return
+		}
+		limit = n
+	}
+
+	afterID, err := service.DecodeCursor(params.Get("after"))
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidParam, errInvalidAfter), err)
+		return
+	}
+
+	// One extra quote tells whether another page exists.
+	quotes, err := h.service.Pending(r.Context(), afterID, limit+1)
+	if err != nil {
+		h.respondServiceError(w, r, errGetPendingQuotes, err)
+		return
+	}
+
+	if len(quotes) > limit {
+		quotes = quotes[:limit]
+		params.Set("after", service.EncodeCursor(quotes[limit-1].ID))
+		params.Set("limit", strconv.Itoa(limit))
+		next := url.URL{Path: r.URL.Path, RawQuery: params.Encode()}
+		w.Header().Set("Link", "<"+next.String()+`>; rel="next"`)
+	}
+
+	respondJSON(w, http.StatusOK, quotes)
+}
+
+// Get returns a quote whatever its status, for the reviewer to look at
+// quotes the public routes do not serve.
+func (h *ModerationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeReviewer(w, r) {
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, errGetID), err)
+		return
+	}
+
+	quote, err := h.service.GetForReview(r.Context(), id)
+	if err != nil {
+		h.respondServiceError(w, r, errGetQuote, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quote)
+}
+
+// Approve publishes a pending quote.
+func (h *ModerationHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeReviewer(w, r) {
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, errGetID), err)
+		return
+	}
+
+	quote, err := h.service.Approve(r.Context(), id)
+	if err != nil {
+		h.respondServiceError(w, r, errApproveQuote, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quote)
+}
+
+// Reject returns a pending quote to its author with the reason from the
+// request body.
+func (h *ModerationHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeReviewer(w, r) {
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, errGetID), err)
+		return
+	}
+
+	var in rejectInput
+	if !h.decodeJSON(w, r, &in) {
+		return
+	}
+
+	quote, err := h.service.Reject(r.Context(), id, in.Reason)
+	if err != nil {
+		h.respondServiceError(w, r, errRejectQuote, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quote)
+}
+
+func (h *ModerationHandler) authorizeReviewer(w http.ResponseWriter, r *http.Request) bool {
+	if err := h.service.AuthorizeReviewer(bearerToken(r)); err != nil {
+		h.respondAuthError(w, r, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,321 @@
+package handler
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"html/template"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const (
+	ContentTypeHTML       = "text/html; charset=utf-8"
+	ContentTypeJavaScript = "text/javascript; charset=utf-8"
+
+	DefaultPageSize = 20
+
+	errRenderPage   = "failed to render page"
+	errInvalidAfter = "after must be a cursor from a previous page"
+
+	// Pages may not be framed, the widget is meant to be.
+	pagePolicy   = "default-src 'none'; style-src 'unsafe-inline'; form-action 'self'; frame-ancestors 'none'"
+	widgetPolicy = "default-src 'none'; style-src 'unsafe-inline'"
+)
+
+//go:embed web
+var webFS embed.FS
+
+var (
+	pageTemplates  = parsePages("list", "quote", "error")
+	widgetTemplate = template.Must(template.ParseFS(webFS, "web/widget.html"))
+	widgetScript   = mustReadWeb("web/widget.js")
+)
+
+// parsePages pairs the shared layout with each page, pages define their own
+// "content" template so every page needs its own set.
+func parsePages(names ...string) map[string]*template.Template {
+	layout := template.Must(template.ParseFS(webFS, "web/layout.html"))
+
+	pages := make(map[string]*template.Template, len(names))
+	for _, name := range names {
+		pages[name] = template.Must(template.Must(layout.Clone()).ParseFS(webFS, "web/"+name+".html"))
+	}
+	return pages
+}
+
+func mustReadWeb(name string) []byte {
+	b, err := webFS.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// AcceptsHTML reports whether the client prefers HTML over JSON. Browsers
+// list text/html explicitly, API clients send application/json, */* or no
+// Accept header at all and keep getting JSON.
+func AcceptsHTML(r *http.Request) bool {
+	var htmlQ, jsonQ float64
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		switch mediaType {
+		case "text/html", "application/xhtml+xml":
+			htmlQ = max(htmlQ, q)
+		case "application/json", "application/*", "*/*":
+			jsonQ = max(jsonQ, q)
+		}
+	}
+	return htmlQ > jsonQ
+}
+
+// PagesHandler renders the quote collection as HTML for browsing in a
+// browser and serves the embeddable random quote widget.
+type PagesHandler struct {
+	base
+	service  service.Quote
+	pageSize int
+}
+
+type PagesOption func(*PagesHandler)
+
+// WithPageSize sets the number of quotes on a list page.
+func WithPageSize(n int) PagesOption {
+	return func(h *PagesHandler) {
+		h.pageSize = n
+	}
+}
+
+func NewPages(service service.Quote, logger *logger.Logger, opts ...PagesOption) *PagesHandler {
+	h := &PagesHandler{
+		base:     newBase(logger, nil),
+		service:  service,
+		pageSize: DefaultPageSize,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.pageSize < 1 {
+		h.pageSize = DefaultPageSize
+	}
+	return h
+}
+
+type pageData struct {
+	Title   string
+	Filter  storage.QuoteFilter
+	Quotes  []*model.Quote
+	Quote   *model.Quote
+	Random  bool
+	First   string
+	Next    string
+	Problem *Problem
+}
+
+// List shows one page of quotes in ID order, filtered by the search form.
+func (h *PagesHandler) List(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	afterID, err := service.DecodeCursor(params.Get("after"))
+	if err != nil {
+		h.renderError(w, r, newProblem(r, codeInvalidParam, errInvalidAfter), err)
+		return
+	}
+
+	filter := storage.QuoteFilter{
+		Author:  strings.TrimSpace(params.Get("author")),
+		Tag:     strings.TrimSpace(params.Get("tag")),
+		Text:    strings.TrimSpace(params.Get("q")),
+		AfterID: afterID,
+		Limit:   h.pageSize + 1,
+	}
+	quotes, err := h.service.Find(r.Context(), filter)
+	if err != nil {
+		h.renderServiceError(w, r, errGetQuotes, err)
+		return
+	}
+
+	data := pageData{Title: "Quotes", Filter: filter, Quotes: quotes}
+	if filter.Author != "" {
+		data.Title = "Quotes by " + filter.Author
+	}
+	if afterID > 0 {
+		data.First = pageURL(r, "")
+	}
+	if len(quotes) > h.pageSize {
+		data.Quotes = quotes[:h.pageSize]
+		data.Next = pageURL(r, service.EncodeCursor(data.Quotes[h.pageSize-1].ID))
+	}
+
+	h.render(w, r, http.StatusOK, "list", data)
+}
+
+func (h *PagesHandler) Quote(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.renderError(w, r, newProblem(r, codeInvalidID, errGetID), err)
+		return
+	}
+
+	quote, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		h.renderServiceError(w, r, errGetQuote, err)
+		return
+	}
+
+	h.render(w, r, http.StatusOK, "quote", pageData{Title: "Quote by " + quote.Author, Quote: quote})
+}
+
+func (h *PagesHandler) Random(w http.ResponseWriter, r *http.Request) {
+	quote, err := h.service.GetRandom(r.Context())
+	if err != nil {
+		h.renderServiceError(w, r, errGetRandomQuote, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.render(w, r, http.StatusOK, "quote", pageData{Title: "Random quote", Quote: quote, Random: true})
+}
+
+// widgetTheme holds the sanitised theming options of the widget, unknown
+// values fall back to defaults so a typo never breaks the host page.
+type widgetTheme struct {
+	Name   string
+	Accent string
+	Font   template.CSS
+}
+
+var (
+	accentPattern = regexp.MustCompile(`^#?([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+	widgetThemes = map[string]bool{"light": true, "dark": true, "auto": true}
+	// Font stacks are trusted CSS, the template would reject the quotes.
+	widgetFonts = map[string]template.CSS{
+		"sans":  "system-ui, sans-serif",
+		"serif": "Georgia, 'Times New Roman', serif",
+		"mono":  "ui-monospace, Menlo, Consolas, monospace",
+	}
+)
+
+func newWidgetTheme(params url.Values) widgetTheme {
+	theme := widgetTheme{Name: "auto", Accent: "#0a66c2", Font: widgetFonts["serif"]}
+	if name := params.Get("theme"); widgetThemes[name] {
+		theme.Name = name
+	}
+	if accent := params.Get("accent"); accentPattern.MatchString(accent) {
+		theme.Accent = "#" + strings.TrimPrefix(accent, "#")
+	}
+	if font, ok := widgetFonts[params.Get("font")]; ok {
+		theme.Font = font
+	}
+	return theme
+}
+
+// Widget is a minimal page with a random quote meant to be shown in an
+// iframe on other sites, themed by the theme, accent and font parameters.
+func (h *PagesHandler) Widget(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		Theme widgetTheme
+		Quote *model.Quote
+	}{Theme: newWidgetTheme(r.URL.Query())}
+
+	status := http.StatusOK
+	quote, err := h.service.GetRandom(r.Context())
+	switch {
+	case err == nil:
+		data.Quote = quote
+	case errors.Is(err, service.ErrNotFound):
+		status = http.StatusNotFound
+	default:
+		h.renderServiceError(w, r, errGetRandomQuote, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := widgetTemplate.Execute(&buf, data); err != nil {
+		h.log(r).Error().Err(err).Msg(errRenderPage)
+		http.Error(w, errRenderPage, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentTypeHTML)
+	w.Header().Set("Content-Security-Policy", widgetPolicy)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// WidgetScript is the embed snippet, it replaces its own script tag with
+// an iframe of Widget carrying the data-* theming attributes.
+func (h *PagesHandler) WidgetScript(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentTypeJavaScript)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	_, _ = w.Write(widgetScript)
+}
+
+// render executes a page into a buffer first so template errors still
+// produce a clean 500 instead of a half written page.
+func (h *PagesHandler) render(w http.ResponseWriter, r *http.Request, status int, page string, data pageData) {
+	var buf bytes.Buffer
+	if err := pageTemplates[page].ExecuteTemplate(&buf, "layout", data); err != nil {
+		h.log(r).Error().Err(err).Str("page", page).Msg(errRenderPage)
+		http.Error(w, errRenderPage, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentTypeHTML)
+	w.Header().Set("Content-Security-Policy", pagePolicy)
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// renderError is respondError for browsers: same logging, the problem is
+// shown as an error page.
+func (h *PagesHandler) renderError(w http.ResponseWriter, r *http.Request, p *Problem, err error) {
+	event := h.log(r).Warn()
+	if p.Status >= http.StatusInternalServerError {
+		event = h.log(r).Error()
+	}
+	event.Err(err).Str("code", p.Code).Msg(p.Detail)
+
+	h.render(w, r, p.Status, "error", pageData{Title: p.Title, Problem: p})
+}
+
+func (h *PagesHandler) renderServiceError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	h.renderError(w, r, problemFromError(r, err, message), err)
+}
+
+// pageURL links to the current list page with another cursor, keeping the
+// search parameters.
+func pageURL(r *http.Request, after string) string {
+	params := r.URL.Query()
+	params.Del("after")
+	if after != "" {
+		params.Set("after", after)
+	}
+
+	u := url.URL{Path: r.URL.Path, RawQuery: params.Encode()}
+	return u.String()
+}
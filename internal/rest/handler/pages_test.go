@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+func TestAcceptsHTML(t *testing.T) {
+	tt := []struct {
+		accept string
+		html   bool
+	}{
+		{accept: "", html: false},
+		{accept: "*/*", html: false},
+		{accept: "application/json", html: false},
+		{accept: "application/json, text/html", html: false},
+		{accept: "text/html", html: true},
+		{accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", html: true},
+		{accept: "text/html;q=0.5, application/json", html: false},
+		{accept: "text/html;q=0", html: false},
+	}
+
+	for _, tc := range tt {
+		req := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+		req.Header.Set("Accept", tc.accept)
+		if got := AcceptsHTML(req); got != tc.html {
+			t.Errorf("Accept %q: expected %v, got %v", tc.accept, tc.html, got)
+		}
+	}
+}
+
+func TestPagesHandler(t *testing.T) {
+	ctx := context.Background()
+	log := logger.New("error", "console")
+
+	svc := service.NewQuoteService(storage.NewInMemory(10))
+	for i := 1; i <= 5; i++ {
+		q := &model.Quote{Author: "Seneca", Quote: "Lesson <" + strconv.Itoa(i) + ">", Tags: []string{"stoic"}}
+		if i == 5 {
+			q.Author = "Confucius"
+		}
+		if _, err := svc.Create(ctx, q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := NewPages(svc, log, WithPageSize(2))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/quotes", h.List)
+	router.HandleFunc("/quotes/random", h.Random)
+	router.HandleFunc("/quotes/{id:[0-9]+}", h.Quote)
+	router.HandleFunc("/widget/random", h.Widget)
+
+	get := func(t *testing.T, target string) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if ct := rec.Header().Get("Content-Type"); ct != ContentTypeHTML {
+			t.Errorf("expected content type %s, got %s", ContentTypeHTML, ct)
+		}
+		return rec
+	}
+
+	t.Run("List pages through search results with escaped quotes", func(t *testing.T) {
+		rec := get(t, "/quotes?author=seneca")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		body := rec.Body.String()
+		if !strings.Contains(body, "Lesson &lt;1&gt;") || strings.Contains(body, "Lesson <1>") {
+			t.Errorf("expected escaped quote text, got %s", body)
+		}
+		if strings.Contains(body, "Lesson &lt;3&gt;") {
+			t.Errorf("expected page size of 2, got %s", body)
+		}
+
+		next := url.Values{"author": {"seneca"}, "after": {service.EncodeCursor(2)}}
+		if !strings.Contains(body, `href="/quotes?`+strings.ReplaceAll(next.Encode(), "&", "&amp;")+`"`) {
+			t.Fatalf("expected next page link, got %s", body)
+		}
+
+		rec = get(t, "/quotes?"+next.Encode())
+		body = rec.Body.String()
+		if !strings.Contains(body, "Lesson &lt;3&gt;") || !strings.Contains(body, "Lesson &lt;4&gt;") || strings.Contains(body, `rel="next"`) {
+			t.Errorf("unexpected last page %s", body)
+		}
+	})
+
+	t.Run("Text search", func(t *testing.T) {
+		body := get(t, "/quotes?q=lesson+%3C5").Body.String()
+		if !strings.Contains(body, "Confucius") || strings.Contains(body, "Lesson &lt;1&gt;") {
+			t.Errorf("unexpected search results %s", body)
+		}
+	})
+
+	t.Run("Invalid cursor renders an error page", func(t *testing.T) {
+		rec := get(t, "/quotes?after=nope")
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "Invalid parameter") {
+			t.Errorf("expected 400 error page, got %d %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("Quote page and not found page", func(t *testing.T) {
+		rec := get(t, "/quotes/5")
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Quote by Confucius") {
+			t.Errorf("unexpected quote page %d %s", rec.Code, rec.Body.String())
+		}
+		if rec.Header().Get("Content-Security-Policy") == "" {
+			t.Error("expected a Content-Security-Policy")
+		}
+
+		rec = get(t, "/quotes/42")
+		if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "quote not found") {
+			t.Errorf("expected 404 error page, got %d %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("Widget applies valid theme options only", func(t *testing.T) {
+		rec := get(t, "/widget/random?theme=dark&accent=e4572e&font=mono")
+		body := rec.Body.String()
+		if rec.Code != http.StatusOK || !strings.Contains(body, `class="dark"`) || !strings.Contains(body, "--accent: #e4572e") || !strings.Contains(body, "ui-monospace") {
+			t.Errorf("unexpected widget %d %s", rec.Code, body)
+		}
+		if strings.Contains(rec.Header().Get("Content-Security-Policy"), "frame-ancestors") {
+			t.Error("widget must be embeddable")
+		}
+
+		body = get(t, "/widget/random?theme=neon&accent=red;}&font=comic").Body.String()
+		if !strings.Contains(body, `class="auto"`) || !strings.Contains(body, "--accent: #0a66c2") || !strings.Contains(body, "Georgia") {
+			t.Errorf("expected default theme, got %s", body)
+		}
+	})
+
+	t.Run("Widget of an empty collection", func(t *testing.T) {
+		empty := NewPages(service.NewQuoteService(storage.NewInMemory(10)), log)
+		rec := httptest.NewRecorder()
+		empty.Widget(rec, httptest.NewRequest(http.MethodGet, "/widget/random", nil))
+		if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "No quotes yet.") {
+			t.Errorf("unexpected empty widget %d %s", rec.Code, rec.Body.String())
+		}
+	})
+}
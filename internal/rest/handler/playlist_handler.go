@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const (
+	errCreatePlaylist      = "failed to create playlist"
+	errGetPlaylists        = "failed to get playlists"
+	errGetPlaylist         = "failed to get playlist"
+	errUpdatePlaylist      = "failed to update playlist"
+	errDeletePlaylist      = "failed to delete playlist"
+	errGetPlaylistID       = "failed to get playlist id"
+	errGetPlaylistQuotes   = "failed to get playlist quotes"
+	errAddPlaylistQuote    = "failed to add quote to playlist"
+	errMovePlaylistQuote   = "failed to move quote in playlist"
+	errRemovePlaylistQuote = "failed to remove quote from playlist"
+	errInvalidAfterQuote   = "after must be the ID of a quote in the playlist"
+)
+
+// playlistInput is the accepted request body, server managed fields of
+// model.Playlist are not part of it.
+type playlistInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	QuoteIDs    []int  `json:"quote_ids"`
+}
+
+func (in playlistInput) playlist() *model.Playlist {
+	return &model.Playlist{Name: in.Name, Description: in.Description, QuoteIDs: in.QuoteIDs}
+}
+
+// playlistQuoteInput adds a quote, at the end unless Position is given.
+type playlistQuoteInput struct {
+	QuoteID  int  `json:"quote_id"`
+	Position *int `json:"position"`
+}
+
+type moveInput struct {
+	Position *int `json:"position"`
+}
+
+type PlaylistHandler struct {
+	base
+	service service.Playlist
+}
+
+func NewPlaylists(service service.Playlist, logger *logger.Logger, opts ...Option) *PlaylistHandler {
+	return &PlaylistHandler{
+		base:    newBase(logger, opts),
+		service: service,
+	}
+}
+
+func (h *PlaylistHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var in playlistInput
+	if !h.decodeJSON(w, r, &in) {
+		return
+	}
+
+	created, err := h.service.Create(r.Context(), in.playlist())
+	if err != nil {
+		h.respondServiceError(w, r, errCreatePlaylist, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, created)
+}
+
+func (h *PlaylistHandler) List(w http.ResponseWriter, r *http.Request) {
+	playlists, err := h.service.List(r.Context())
+	if err != nil {
+		h.respondServiceError(w, r, errGetPlaylists, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, playlists)
+}
+
+func (h *PlaylistHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r, "id", errGetPlaylistID)
+	if !ok {
+		return
+	}
+
+	playlist, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		h.respondServiceError(w, r, errGetPlaylist, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, playlist)
+}
+
+func (h *PlaylistHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r, "id", errGetPlaylistID)
+	if !ok {
+		return
+	}
+
+	var in playlistInput
+	if !h.decodeJSON(w, r, &in) {
+		return
+	}
+
+	updated, err := h.service.Update(r.Context(), id, in.playlist())
+	if err != nil {
+		h.respondServiceError(w, r, errUpdatePlaylist, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, updated)
+}
+
+func (h *PlaylistHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r, "id", errGetPlaylistID)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		h.respondServiceError(w, r, errDeletePlaylist, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Quotes lists the published quotes of a playlist in its order.
+func (h *PlaylistHandler) Quotes(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r, "id", errGetPlaylistID)
+	if !ok {
+		return
+	}
+
+	quotes, err := h.service.Quotes(r.Context(), id)
+	if err != nil {
+		h.respondServiceError(w, r, errGetPlaylistQuotes, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quotes)
+}
+
+func (h *PlaylistHandler) Random(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r, "id", errGetPlaylistID)
+	if !ok {
+		return
+	}
+
+	quote, err := h.service.Random(r.Context(), id)
+	if err != nil {
+		h.respondServiceError(w, r, errGetRandomQuote, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quote)
+}
+
+// Next serves the quote following the one given as after, the first quote
+// without it.
+func (h *PlaylistHandler) Next(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r, "id", errGetPlaylistID)
+	if !ok {
+		return
+	}
+
+	var after int
+	if v := r.URL.Query().Get("after"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			h.respondError(w, r, newProblem(r, codeInvalidParam, errInvalidAfterQuote), err)
+			return
+		}
+		after = n
+	}
+
+	quote, err := h.service.Next(r.Context(), id, after)
+	if err != nil {
+		h.respondServiceError(w, r, errGetPlaylistQuotes, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, quote)
+}
+
+// AddQuote appends a quote to the playlist or inserts it at the position
+// from the request body.
+func (h *PlaylistHandler) AddQuote(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r, "id", errGetPlaylistID)
+	if !ok {
+		return
+	}
+
+	var in playlistQuoteInput
+	if !h.decodeJSON(w, r, &in) {
+		return
+	}
+
+	var (
+		playlist *model.Playlist
+		err      error
+	)
+	if in.Position == nil {
+		playlist, err = h.service.Add(r.Context(), id, in.QuoteID)
+	} else {
+		playlist, err = h.service.Insert(r.Context(), id, in.QuoteID, *in.Position)
+	}
+	if err != nil {
+		h.respondServiceError(w, r, errAddPlaylistQuote, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, playlist)
+}
+
+func (h *PlaylistHandler) MoveQuote(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r, "id", errGetPlaylistID)
+	if !ok {
+		return
+	}
+	quoteID, ok := h.pathID(w, r, "quote_id", errGetID)
+	if !ok {
+		return
+	}
+
+	var in moveInput
+	if !h.decodeJSON(w, r, &in) {
+		return
+	}
+	// A missing position is reported by the range check.
+	position := -1
+	if in.Position != nil {
+		position = *in.Position
+	}
+
+	playlist, err := h.service.Move(r.Context(), id, quoteID, position)
+	if err != nil {
+		h.respondServiceError(w, r, errMovePlaylistQuote, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, playlist)
+}
+
+func (h *PlaylistHandler) RemoveQuote(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r, "id", errGetPlaylistID)
+	if !ok {
+		return
+	}
+	quoteID, ok := h.pathID(w, r, "quote_id", errGetID)
+	if !ok {
+		return
+	}
+
+	playlist, err := h.service.Remove(r.Context(), id, quoteID)
+	if err != nil {
+		h.respondServiceError(w, r, errRemovePlaylistQuote, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, playlist)
+}
+
+func (h *PlaylistHandler) pathID(w http.ResponseWriter, r *http.Request, name, message string) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)[name])
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, message), err)
+		return 0, false
+	}
+	return id, true
+}
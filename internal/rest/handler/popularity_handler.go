@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// HeaderUserID identifies the user behind a like or rating, each user
+// counts once per quote. The value is opaque to the service.
+const HeaderUserID = "X-User-ID"
+
+const (
+	errLikeQuote     = "failed to like quote"
+	errRateQuote     = "failed to rate quote"
+	errGetPopularity = "failed to get quote popularity"
+	errGetTopQuotes  = "failed to get top quotes"
+)
+
+type ratingInput struct {
+	Rating int `json:"rating"`
+}
+
+// Like likes the quote on behalf of the X-User-ID user.
+func (h *QuoteHandler) Like(w http.ResponseWriter, r *http.Request) {
+	h.setLike(w, r, true)
+}
+
+// Unlike takes back the like of the X-User-ID user.
+func (h *QuoteHandler) Unlike(w http.ResponseWriter, r *http.Request) {
+	h.setLike(w, r, false)
+}
+
+func (h *QuoteHandler) setLike(w http.ResponseWriter, r *http.Request, liked bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, errGetID), err)
+		return
+	}
+
+	p, err := h.service.SetLike(r.Context(), id, r.Header.Get(HeaderUserID), liked)
+	if err != nil {
+		h.respondServiceError(w, r, errLikeQuote, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, p)
+}
+
+// Rate sets the rating of the X-User-ID user from the request body.
+func (h *QuoteHandler) Rate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, errGetID), err)
+		return
+	}
+
+	var in ratingInput
+	if !h.decodeJSON(w, r, &in) {
+		return
+	}
+
+	p, err := h.service.Rate(r.Context(), id, r.Header.Get(HeaderUserID), in.Rating)
+	if err != nil {
+		h.respondServiceError(w, r, errRateQuote, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, p)
+}
+
+// Unrate removes the rating of the X-User-ID user.
+func (h *QuoteHandler) Unrate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, errGetID), err)
+		return
+	}
+
+	p, err := h.service.Unrate(r.Context(), id, r.Header.Get(HeaderUserID))
+	if err != nil {
+		h.respondServiceError(w, r, errRateQuote, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, p)
+}
+
+func (h *QuoteHandler) Popularity(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, errGetID), err)
+		return
+	}
+
+	p, err := h.service.Popularity(r.Context(), id)
+	if err != nil {
+		h.respondServiceError(w, r, errGetPopularity, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, p)
+}
+
+// Top lists the most popular quotes, limit of them at most.
+func (h *QuoteHandler) Top(w http.ResponseWriter, r *http.Request) {
+	limit := DefaultListLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxListLimit {
+			h.respondError(w, r, newProblem(r, codeInvalidParam, errInvalidLimit), err)
+			return
+		}
+		limit = n
+	}
+
+	ranked, err := h.service.Top(r.Context(), limit)
+	if err != nil {
+		h.respondServiceError(w, r, errGetTopQuotes, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ranked)
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/rest/middleware"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+)
+
+const (
+	ContentTypeProblem = "application/problem+json"
+
+	problemTypePrefix = "urn:quotebook:problem:"
+)
+
+// Request level codes, domain codes come from service.Code.
+const (
+	codeInvalidPayload  = "invalid_payload"
+	codeInvalidID       = "invalid_id"
+	codeMissingParam    = "missing_parameter"
+	codeInvalidParam    = "invalid_parameter"
+	codePayloadTooLarge = "payload_too_large"
+)
+
+// Problem is an RFC 7807 problem details object extended with a stable code,
+// the request ID and per-field validation errors.
+type Problem struct {
+	Type      string               `json:"type"`
+	Title     string               `json:"title"`
+	Status    int                  `json:"status"`
+	Detail    string               `json:"detail,omitempty"`
+	Instance  string               `json:"instance,omitempty"`
+	Code      string               `json:"code"`
+	RequestID string               `json:"request_id,omitempty"`
+	Errors    []service.FieldError `json:"errors,omitempty"`
+}
+
+type problemKind struct {
+	status int
+	title  string
+}
+
+var problemKinds = map[string]problemKind{
+	codeInvalidPayload:               {http.StatusBadRequest, "Invalid request payload"},
+	codeInvalidID:                    {http.StatusBadRequest, "Invalid ID"},
+	codeMissingParam:                 {http.StatusBadRequest, "Missing required parameter"},
+	codeInvalidParam:                 {http.StatusBadRequest, "Invalid parameter"},
+	codePayloadTooLarge:              {http.StatusRequestEntityTooLarge, "Payload too large"},
+	string(service.CodeValidation):   {http.StatusBadRequest, "Validation failed"},
+	string(service.CodeNotFound):     {http.StatusNotFound, "Resource not found"},
+	string(service.CodeConflict):     {http.StatusConflict, "Conflict"},
+	string(service.CodeUnauthorized): {http.StatusUnauthorized, "Unauthorized"},
+	string(service.CodeForbidden):    {http.StatusForbidden, "Forbidden"},
+	string(service.CodeTimeout):      {http.StatusGatewayTimeout, "Request timed out"},
+	string(service.CodeCanceled):     {StatusClientClosedRequest, "Request canceled"},
+	string(service.CodeUnavailable):  {http.StatusServiceUnavailable, "Service unavailable"},
+	string(service.CodeInternal):     {http.StatusInternalServerError, "Internal server error"},
+}
+
+func newProblem(r *http.Request, code, detail string) *Problem {
+	kind, ok := problemKinds[code]
+	if !ok {
+		code = string(service.CodeInternal)
+		kind = problemKinds[code]
+	}
+
+	return &Problem{
+		Type:      problemTypePrefix + code,
+		Title:     kind.title,
+		Status:    kind.status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: middleware.RequestIDFromContext(r.Context()),
+	}
+}
+
+// problemFromError maps service errors to problems. Unknown errors become
+// internal errors with the given detail so internals are not leaked.
+func problemFromError(r *http.Request, err error, detail string) *Problem {
+	var svcErr *service.Error
+	if !errors.As(err, &svcErr) {
+		return newProblem(r, string(service.CodeInternal), detail)
+	}
+
+	if svcErr.Code != service.CodeInternal && svcErr.Detail != "" {
+		detail = svcErr.Detail
+	}
+
+	p := newProblem(r, string(svcErr.Code), detail)
+	p.Errors = svcErr.Fields
+	return p
+}
+
+func respondProblem(w http.ResponseWriter, p *Problem) {
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(p.Status)
+
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		http.Error(w, errInvalidResponse, http.StatusInternalServerError)
+	}
+}
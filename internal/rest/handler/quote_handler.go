@@ -1,9 +1,9 @@
 package handler
 
 import (
-	"encoding/json"
 	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -20,132 +20,235 @@ const (
 	errInvalidResponse       = "invalid response"
 	errCreateQuote           = "failed to create quote"
 	errGetQuotes             = "failed to get quotes"
+	errGetQuote              = "failed to get quote"
 	errGetRandomQuote        = "failed to get random quote"
 	errGetByAuthor           = "failed to get quotes by author"
 	errGetID                 = "failed to get quote id"
 	errDeleteQuote           = "failed to delete quote"
-	errQuoteNotFound         = "quote not found"
-	errEmptyAuthorOrQuote    = "author and quote must be non-empty"
 	errEmptyAuthor           = "author param required"
+	errPayloadTooLarge       = "request body too large"
+	errInvalidLimit          = "limit must be an integer between 1 and 100"
+	errInvalidWeighted       = "weighted must be a boolean"
 )
 
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+const DefaultMaxBodyBytes = 64 << 10
+
+// StatusClientClosedRequest is the nginx convention for requests the client
+// abandoned before a response was written.
+const StatusClientClosedRequest = 499
+
 type QuoteHandler struct {
+	base
 	service service.Quote
-	logger  *logger.Logger
 }
 
-func New(service service.Quote, logger *logger.Logger) *QuoteHandler {
+func New(service service.Quote, logger *logger.Logger, opts ...Option) *QuoteHandler {
 	return &QuoteHandler{
+		base:    newBase(logger, opts),
 		service: service,
-		logger:  logger,
 	}
 }
 
 func (h *QuoteHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var quote model.Quote
-	if err := json.NewDecoder(r.Body).Decode(&quote); err != nil {
-		h.respondError(w, http.StatusBadRequest, errInvalidRequestPayload, err)
+	if !h.decodeJSON(w, r, &quote) {
+		return
+	}
+
+	created, err := h.service.Create(r.Context(), &quote)
+	if err != nil {
+		h.respondServiceError(w, r, errCreateQuote, err)
 		return
 	}
 
-	if strings.TrimSpace(quote.Author) == "" || strings.TrimSpace(quote.Quote) == "" {
-		h.respondError(w, http.StatusBadRequest, errEmptyAuthorOrQuote, nil)
+	respondJSON(w, http.StatusCreated, created)
+}
+
+// List returns every quote, or one page of them when any of limit, after,
+// q, tag or lang is given. The next page is linked with a Link header.
+func (h *QuoteHandler) List(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	if params.Has("limit") || params.Has("after") || params.Has("q") || params.Has("tag") || params.Has("lang") {
+		h.listPage(w, r)
 		return
 	}
 
-	created, err := h.service.Create(&quote)
+	quotes, err := h.service.List(r.Context())
 	if err != nil {
-		h.respondError(w, http.StatusInternalServerError, errCreateQuote, err)
+		h.respondServiceError(w, r, errGetQuotes, err)
 		return
 	}
 
-	respondJSON(w, http.StatusCreated, created)
+	respondJSON(w, http.StatusOK, quotes)
 }
 
-func (h *QuoteHandler) List(w http.ResponseWriter, _ *http.Request) {
-	quotes, err := h.service.List()
+func (h *QuoteHandler) listPage(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	limit := DefaultListLimit
+	if v := params.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxListLimit {
+			h.respondError(w, r, newProblem(r, codeInvalidParam, errInvalidLimit), err)
+			return
+		}
+		limit = n
+	}
+
+	afterID, err := service.DecodeCursor(params.Get("after"))
 	if err != nil {
-		h.respondError(w, http.StatusInternalServerError, errGetQuotes, err)
+		h.respondError(w, r, newProblem(r, codeInvalidParam, errInvalidAfter), err)
 		return
 	}
 
+	lang, ok := h.langParam(w, r)
+	if !ok {
+		return
+	}
+
+	// One extra quote tells whether another page exists.
+	quotes, err := h.service.Find(r.Context(), storage.QuoteFilter{
+		Tag:     strings.TrimSpace(params.Get("tag")),
+		Text:    strings.TrimSpace(params.Get("q")),
+		Lang:    lang,
+		AfterID: afterID,
+		Limit:   limit + 1,
+	})
+	if err != nil {
+		h.respondServiceError(w, r, errGetQuotes, err)
+		return
+	}
+
+	if len(quotes) > limit {
+		quotes = quotes[:limit]
+		params.Set("after", service.EncodeCursor(quotes[limit-1].ID))
+		params.Set("limit", strconv.Itoa(limit))
+		next := url.URL{Path: r.URL.Path, RawQuery: params.Encode()}
+		w.Header().Set("Link", "<"+next.String()+`>; rel="next"`)
+	}
+
 	respondJSON(w, http.StatusOK, quotes)
 }
 
-func (h *QuoteHandler) Random(w http.ResponseWriter, _ *http.Request) {
-	quote, err := h.service.GetRandom()
-	if errors.Is(err, storage.ErrNotFound) {
-		h.respondError(w, http.StatusNotFound, errQuoteNotFound, err)
+func (h *QuoteHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, errGetID), err)
 		return
 	}
+
+	quote, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		h.respondError(w, http.StatusInternalServerError, errGetRandomQuote, err)
+		h.respondServiceError(w, r, errGetQuote, err)
 		return
 	}
 
 	respondJSON(w, http.StatusOK, quote)
 }
 
-func (h *QuoteHandler) FilterByAuthor(w http.ResponseWriter, r *http.Request) {
-	author := r.URL.Query().Get("author")
-	if strings.TrimSpace(author) == "" {
-		h.respondError(w, http.StatusBadRequest, errEmptyAuthor, nil)
+// Random picks a quote in the lang parameter if given. Otherwise the
+// languages of Accept-Language are tried in order of preference, falling
+// back to any quote when none of them has quotes. With weighted=true highly
+// rated quotes come up more often.
+func (h *QuoteHandler) Random(w http.ResponseWriter, r *http.Request) {
+	lang, ok := h.langParam(w, r)
+	if !ok {
 		return
 	}
 
-	quote, err := h.service.GetByAuthor(author)
+	var weighted bool
+	if v := r.URL.Query().Get("weighted"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			h.respondError(w, r, newProblem(r, codeInvalidParam, errInvalidWeighted), err)
+			return
+		}
+		weighted = b
+	}
+
+	var (
+		quote  *model.Quote
+		err    error
+		filter = storage.QuoteFilter{Lang: lang, ByRating: weighted}
+	)
+	if lang != "" {
+		quote, err = h.service.FindRandom(r.Context(), filter)
+	} else {
+		w.Header().Add("Vary", "Accept-Language")
+		quote, err = h.randomPreferred(r, filter)
+	}
 	if err != nil {
-		h.respondError(w, http.StatusInternalServerError, errGetByAuthor, err)
+		h.respondServiceError(w, r, errGetRandomQuote, err)
 		return
 	}
 
+	if quote.Lang != "" {
+		w.Header().Set("Content-Language", quote.Lang)
+	}
 	respondJSON(w, http.StatusOK, quote)
 }
 
-func (h *QuoteHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	strID := vars["id"]
+func (h *QuoteHandler) randomPreferred(r *http.Request, filter storage.QuoteFilter) (*model.Quote, error) {
+	for _, lang := range acceptedLangs(r) {
+		filter.Lang = lang
+		quote, err := h.service.FindRandom(r.Context(), filter)
+		if !errors.Is(err, service.ErrNotFound) {
+			return quote, err
+		}
+	}
+	filter.Lang = ""
+	return h.service.FindRandom(r.Context(), filter)
+}
 
-	id, err := strconv.Atoi(strID)
-	if err != nil {
-		h.respondError(w, http.StatusBadRequest, errGetID, err)
+func (h *QuoteHandler) FilterByAuthor(w http.ResponseWriter, r *http.Request) {
+	author := r.URL.Query().Get("author")
+	if strings.TrimSpace(author) == "" {
+		h.respondError(w, r, newProblem(r, codeMissingParam, errEmptyAuthor), nil)
 		return
 	}
 
-	err = h.service.Delete(id)
-	if errors.Is(err, storage.ErrNotFound) {
-		h.respondError(w, http.StatusNotFound, errQuoteNotFound, err)
+	lang, ok := h.langParam(w, r)
+	if !ok {
 		return
 	}
+
+	var (
+		quotes []*model.Quote
+		err    error
+	)
+	if lang != "" {
+		quotes, err = h.service.Find(r.Context(), storage.QuoteFilter{Author: author, Lang: lang})
+	} else {
+		quotes, err = h.service.GetByAuthor(r.Context(), author)
+	}
 	if err != nil {
-		h.respondError(w, http.StatusInternalServerError, errDeleteQuote, err)
+		h.respondServiceError(w, r, errGetByAuthor, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	respondJSON(w, http.StatusOK, quotes)
 }
 
-func respondJSON(w http.ResponseWriter, status int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
+func (h *QuoteHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	strID := vars["id"]
 
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		http.Error(w, errInvalidResponse, http.StatusInternalServerError)
+	id, err := strconv.Atoi(strID)
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, errGetID), err)
+		return
 	}
-}
 
-func (h *QuoteHandler) respondError(w http.ResponseWriter, status int, message string, err error) {
+	err = h.service.Delete(r.Context(), id)
 	if err != nil {
-		h.logger.Error().Err(err).Msg(message)
-	} else {
-		h.logger.Warn().Msg(message)
+		h.respondServiceError(w, r, errDeleteQuote, err)
+		return
 	}
 
-	respondJSON(w, status, map[string]string{"error": message})
-}
-
-func (h *QuoteHandler) logDebug(r *http.Request) {
-	h.logger.Debug().
-		Str("method", r.Method).
-		Str("path", r.URL.String())
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -2,14 +2,19 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gorilla/mux"
 
 	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/rest/middleware"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
 	"github.com/zonder12120/brandscout-quotebook/internal/storage"
 	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
 )
@@ -23,28 +28,84 @@ type mockService struct {
 	quotesList     []*model.Quote
 }
 
-func (m *mockService) Create(q *model.Quote) (*model.Quote, error) {
+func (m *mockService) Create(_ context.Context, q *model.Quote) (*model.Quote, error) {
 	return m.createdQuote, m.createErr
 }
 
-func (m *mockService) List() ([]*model.Quote, error) {
+func (m *mockService) List(_ context.Context) ([]*model.Quote, error) {
 	return m.quotesList, nil
 }
 
-func (m *mockService) GetRandom() (*model.Quote, error) {
+func (m *mockService) ListPage(_ context.Context, afterID, limit int) ([]*model.Quote, error) {
+	return m.quotesList, nil
+}
+
+func (m *mockService) Get(_ context.Context, id int) (*model.Quote, error) {
+	return m.createdQuote, m.getRandomErr
+}
+
+func (m *mockService) GetRandom(_ context.Context) (*model.Quote, error) {
+	return m.createdQuote, m.getRandomErr
+}
+
+func (m *mockService) FindRandom(_ context.Context, filter storage.QuoteFilter) (*model.Quote, error) {
 	return m.createdQuote, m.getRandomErr
 }
 
-func (m *mockService) GetByAuthor(author string) ([]*model.Quote, error) {
+func (m *mockService) GetByAuthor(_ context.Context, author string) ([]*model.Quote, error) {
 	return m.quotesList, m.getByAuthorErr
 }
 
-func (m *mockService) Delete(id int) error {
+func (m *mockService) GetByAuthors(_ context.Context, authors []string) (map[string][]*model.Quote, error) {
+	return map[string][]*model.Quote{}, m.getByAuthorErr
+}
+
+func (m *mockService) Find(_ context.Context, filter storage.QuoteFilter) ([]*model.Quote, error) {
+	return m.quotesList, nil
+}
+
+func (m *mockService) Update(_ context.Context, id int, q *model.Quote) (*model.Quote, error) {
+	return m.createdQuote, m.createErr
+}
+
+func (m *mockService) Delete(_ context.Context, id int) error {
 	return m.deleteErr
 }
 
+func (m *mockService) Batch(_ context.Context, ops []service.BatchOp, atomic bool) ([]service.BatchResult, error) {
+	return make([]service.BatchResult, len(ops)), m.createErr
+}
+
+func (m *mockService) AddTranslation(_ context.Context, id int, t *model.Translation) (*model.Translation, error) {
+	return t, m.createErr
+}
+
+func (m *mockService) DeleteTranslation(_ context.Context, id int, lang string) error {
+	return m.deleteErr
+}
+
+func (m *mockService) SetLike(_ context.Context, id int, user string, liked bool) (model.Popularity, error) {
+	return model.Popularity{QuoteID: id}, m.createErr
+}
+
+func (m *mockService) Rate(_ context.Context, id int, user string, rating int) (model.Popularity, error) {
+	return model.Popularity{QuoteID: id}, m.createErr
+}
+
+func (m *mockService) Unrate(_ context.Context, id int, user string) (model.Popularity, error) {
+	return model.Popularity{QuoteID: id}, m.deleteErr
+}
+
+func (m *mockService) Popularity(_ context.Context, id int) (model.Popularity, error) {
+	return model.Popularity{QuoteID: id}, m.getRandomErr
+}
+
+func (m *mockService) Top(_ context.Context, limit int) ([]model.RankedQuote, error) {
+	return nil, m.getRandomErr
+}
+
 func TestHandler(t *testing.T) {
-	log := logger.New("debug")
+	log := logger.New("debug", "console")
 
 	t.Run("Create validation failure", func(t *testing.T) {
 		reqBody := []byte(`{"author": "", "quote": ""}`)
@@ -52,20 +113,65 @@ func TestHandler(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json")
 		rec := httptest.NewRecorder()
 
-		h := New(&mockService{}, log)
+		h := New(service.NewQuoteService(storage.NewInMemory(10)), log)
 		h.Create(rec, req)
 
 		if rec.Code != http.StatusBadRequest {
 			t.Errorf("expected status 400, got %d", rec.Code)
 		}
 
-		var resp map[string]string
+		if ct := rec.Header().Get("Content-Type"); ct != ContentTypeProblem {
+			t.Errorf("expected content type %s, got %s", ContentTypeProblem, ct)
+		}
+
+		var resp Problem
 		err := json.NewDecoder(rec.Body).Decode(&resp)
 		if err != nil {
 			t.Errorf("expected success decode, got %v", err)
 		}
-		if resp["error"] != errEmptyAuthorOrQuote {
-			t.Errorf("unexpected error message: %s", resp["error"])
+		if resp.Code != string(service.CodeValidation) {
+			t.Errorf("unexpected error code: %s", resp.Code)
+		}
+		if resp.Instance != "/quotes" {
+			t.Errorf("expected instance /quotes, got %s", resp.Instance)
+		}
+		if len(resp.Errors) != 2 || resp.Errors[0].Field != "author" || resp.Errors[1].Field != "quote" {
+			t.Errorf("expected author and quote field errors, got %+v", resp.Errors)
+		}
+	})
+
+	t.Run("Create rejects malformed payloads", func(t *testing.T) {
+		tt := []struct {
+			name   string
+			body   string
+			status int
+			code   string
+		}{
+			{name: "unknown field", body: `{"author": "A", "quote": "Q", "extra": 1}`, status: http.StatusBadRequest, code: codeInvalidPayload},
+			{name: "trailing data", body: `{"author": "A", "quote": "Q"} {}`, status: http.StatusBadRequest, code: codeInvalidPayload},
+			{name: "too large", body: `{"author": "A", "quote": "` + strings.Repeat("q", 100) + `"}`, status: http.StatusRequestEntityTooLarge, code: codePayloadTooLarge},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.name, func(t *testing.T) {
+				req := httptest.NewRequest("POST", "/quotes", strings.NewReader(tc.body))
+				rec := httptest.NewRecorder()
+
+				h := New(&mockService{createdQuote: &model.Quote{ID: 1}}, log, WithMaxBodyBytes(64))
+				h.Create(rec, req)
+
+				if rec.Code != tc.status {
+					t.Errorf("expected status %d, got %d", tc.status, rec.Code)
+				}
+
+				var resp Problem
+				if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+					t.Fatalf("expected success decode, got %v", err)
+				}
+				if resp.Code != tc.code {
+					t.Errorf("expected code %s, got %s", tc.code, resp.Code)
+				}
+			})
 		}
 	})
 
@@ -111,7 +217,7 @@ func TestHandler(t *testing.T) {
 		req = mux.SetURLVars(req, map[string]string{"id": "1"})
 		rec := httptest.NewRecorder()
 
-		h := New(&mockService{deleteErr: storage.ErrNotFound}, log)
+		h := New(&mockService{deleteErr: service.NewNotFoundError("quote not found", nil)}, log)
 		h.Delete(rec, req)
 
 		if rec.Code != http.StatusNotFound {
@@ -123,7 +229,7 @@ func TestHandler(t *testing.T) {
 		req := httptest.NewRequest("GET", "/quotes/random", nil)
 		rec := httptest.NewRecorder()
 
-		h := New(&mockService{getRandomErr: storage.ErrNotFound}, log)
+		h := New(&mockService{getRandomErr: service.ErrNotFound}, log)
 		h.Random(rec, req)
 
 		if rec.Code != http.StatusNotFound {
@@ -154,4 +260,258 @@ func TestHandler(t *testing.T) {
 			t.Errorf("expected 1 quote, got %d", len(quotes))
 		}
 	})
+
+	t.Run("List pages with a next link", func(t *testing.T) {
+		svc := service.NewQuoteService(storage.NewInMemory(10))
+		for _, text := range []string{"Alpha one", "Beta two", "Alpha three"} {
+			if _, err := svc.Create(context.Background(), &model.Quote{Author: "A", Quote: text}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		h := New(svc, log)
+
+		list := func(target string) ([]model.Quote, *httptest.ResponseRecorder) {
+			rec := httptest.NewRecorder()
+			h.List(rec, httptest.NewRequest("GET", target, nil))
+
+			var quotes []model.Quote
+			if rec.Code == http.StatusOK {
+				if err := json.NewDecoder(rec.Body).Decode(&quotes); err != nil {
+					t.Fatalf("expected success decode, got %v", err)
+				}
+			}
+			return quotes, rec
+		}
+
+		quotes, rec := list("/quotes?limit=2")
+		if len(quotes) != 2 || quotes[0].ID != 1 {
+			t.Fatalf("expected the first 2 quotes, got %+v", quotes)
+		}
+		next := strings.TrimSuffix(strings.TrimPrefix(rec.Header().Get("Link"), "<"), `>; rel="next"`)
+		if !strings.HasPrefix(next, "/quotes?after=") {
+			t.Fatalf("unexpected Link %q", rec.Header().Get("Link"))
+		}
+
+		quotes, rec = list(next)
+		if len(quotes) != 1 || quotes[0].ID != 3 || rec.Header().Get("Link") != "" {
+			t.Errorf("expected a last page with quote 3, got %+v", quotes)
+		}
+
+		quotes, _ = list("/quotes?q=alpha")
+		if len(quotes) != 2 {
+			t.Errorf("expected 2 matches, got %d", len(quotes))
+		}
+
+		for _, target := range []string{"/quotes?limit=0", "/quotes?limit=abc", "/quotes?after=bogus"} {
+			if _, rec := list(target); rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", target, rec.Code)
+			}
+		}
+	})
+
+	t.Run("Language is detected and filters quotes", func(t *testing.T) {
+		svc := service.NewQuoteService(storage.NewInMemory(10))
+		for _, q := range []*model.Quote{
+			{Author: "Seneca", Quote: "While we wait for life, life passes."},
+			{Author: "Seneca", Quote: "Пока мы откладываем жизнь, она проходит мимо."},
+			{Author: "Tolstoy", Quote: "Все счастливые семьи похожи друг на друга.", Lang: "RU"},
+		} {
+			if _, err := svc.Create(context.Background(), q); err != nil {
+				t.Fatal(err)
+			}
+		}
+		h := New(svc, log)
+
+		serve := func(handler http.HandlerFunc, target, acceptLanguage string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("GET", target, nil)
+			if acceptLanguage != "" {
+				req.Header.Set("Accept-Language", acceptLanguage)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			return rec
+		}
+		decode := func(rec *httptest.ResponseRecorder, dst interface{}) {
+			t.Helper()
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if err := json.NewDecoder(rec.Body).Decode(dst); err != nil {
+				t.Fatalf("expected success decode, got %v", err)
+			}
+		}
+
+		var quotes []model.Quote
+		decode(serve(h.List, "/quotes?lang=ru", ""), &quotes)
+		if len(quotes) != 2 || quotes[0].ID != 2 || quotes[1].Lang != "ru" {
+			t.Errorf("expected the Russian quotes, got %+v", quotes)
+		}
+
+		decode(serve(h.FilterByAuthor, "/quotes?author=seneca&lang=en", ""), &quotes)
+		if len(quotes) != 1 || quotes[0].ID != 1 {
+			t.Errorf("expected the English quote of Seneca, got %+v", quotes)
+		}
+
+		for i := 0; i < 10; i++ {
+			var quote model.Quote
+			rec := serve(h.Random, "/quotes/random", "de-DE, ru;q=0.9, en;q=0.5")
+			decode(rec, &quote)
+			if quote.Lang != "ru" || rec.Header().Get("Content-Language") != "ru" {
+				t.Fatalf("expected a Russian quote, got %+v", quote)
+			}
+		}
+
+		var quote model.Quote
+		decode(serve(h.Random, "/quotes/random?lang=en", "ru"), &quote)
+		if quote.ID != 1 {
+			t.Errorf("expected lang to take precedence over Accept-Language, got %+v", quote)
+		}
+		decode(serve(h.Random, "/quotes/random", "fr"), &quote)
+		if quote.ID == 0 {
+			t.Errorf("expected any quote when no preferred language matches")
+		}
+
+		if rec := serve(h.Random, "/quotes/random?lang=fr", ""); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 without quotes in lang, got %d", rec.Code)
+		}
+		if rec := serve(h.List, "/quotes?lang=russian", ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for an invalid lang, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Translations are added and served by random", func(t *testing.T) {
+		svc := service.NewQuoteService(storage.NewInMemory(10))
+		if _, err := svc.Create(context.Background(), &model.Quote{Author: "Seneca", Quote: "While we wait for life, life passes."}); err != nil {
+			t.Fatal(err)
+		}
+		h := New(svc, log)
+
+		serve := func(handler http.HandlerFunc, method, target, body string, vars map[string]string) *httptest.ResponseRecorder {
+			req := mux.SetURLVars(httptest.NewRequest(method, target, strings.NewReader(body)), vars)
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			return rec
+		}
+
+		rec := serve(h.AddTranslation, "POST", "/quotes/1/translations", `{"lang": "ru", "quote": "Пока мы откладываем жизнь, она проходит.", "translator": "Anon"}`, map[string]string{"id": "1"})
+		if rec.Code != http.StatusCreated || rec.Header().Get("Location") != "/quotes/1/translations/ru" {
+			t.Fatalf("expected status 201 with Location, got %d %q: %s", rec.Code, rec.Header().Get("Location"), rec.Body.String())
+		}
+		if rec := serve(h.AddTranslation, "POST", "/quotes/2/translations", `{"lang": "ru", "quote": "Q"}`, map[string]string{"id": "2"}); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 for a missing quote, got %d", rec.Code)
+		}
+
+		var quote model.Quote
+		rec = serve(h.Random, "GET", "/quotes/random?lang=ru", "", nil)
+		if err := json.NewDecoder(rec.Body).Decode(&quote); err != nil {
+			t.Fatal(err)
+		}
+		if quote.ID != 1 || quote.Lang != "ru" || quote.TranslatedFrom != "en" || rec.Header().Get("Content-Language") != "ru" {
+			t.Errorf("expected quote 1 translated into ru, got %+v", quote)
+		}
+
+		var translation model.Translation
+		rec = serve(h.Translation, "GET", "/quotes/1/translations/RU", "", map[string]string{"id": "1", "lang": "RU"})
+		if err := json.NewDecoder(rec.Body).Decode(&translation); err != nil || translation.Translator != "Anon" {
+			t.Errorf("unexpected translation %+v, %v", translation, err)
+		}
+
+		if rec := serve(h.DeleteTranslation, "DELETE", "/quotes/1/translations/ru", "", map[string]string{"id": "1", "lang": "ru"}); rec.Code != http.StatusNoContent {
+			t.Errorf("expected status 204, got %d", rec.Code)
+		}
+		if rec := serve(h.Translation, "GET", "/quotes/1/translations/ru", "", map[string]string{"id": "1", "lang": "ru"}); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+		if rec := serve(h.Translation, "GET", "/quotes/1/translations/rus", "", map[string]string{"id": "1", "lang": "rus"}); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("Likes and ratings rank top quotes", func(t *testing.T) {
+		svc := service.NewQuoteService(storage.NewInMemory(10))
+		for _, text := range []string{"Q1", "Q2"} {
+			if _, err := svc.Create(context.Background(), &model.Quote{Author: "A", Quote: text}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		h := New(svc, log)
+
+		serve := func(handler http.HandlerFunc, method, target, body, user string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, target, strings.NewReader(body))
+			req = mux.SetURLVars(req, map[string]string{"id": "2"})
+			if user != "" {
+				req.Header.Set(HeaderUserID, user)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			return rec
+		}
+
+		for _, user := range []string{"alice", "bob", "alice"} {
+			if rec := serve(h.Like, "PUT", "/quotes/2/like", "", user); rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+		}
+		rec := serve(h.Rate, "PUT", "/quotes/2/rating", `{"rating": 4}`, "carol")
+		var p model.Popularity
+		if err := json.NewDecoder(rec.Body).Decode(&p); err != nil || p.Likes != 2 || p.Ratings != 1 || p.AverageRating != 4 {
+			t.Errorf("unexpected popularity %+v, %v", p, err)
+		}
+		if rec := serve(h.Like, "PUT", "/quotes/2/like", "", ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 without %s, got %d", HeaderUserID, rec.Code)
+		}
+
+		var ranked []model.RankedQuote
+		rec = serve(h.Top, "GET", "/quotes/top", "", "")
+		if err := json.NewDecoder(rec.Body).Decode(&ranked); err != nil || len(ranked) != 1 || ranked[0].Quote.ID != 2 || ranked[0].Popularity.Score <= 2 {
+			t.Errorf("expected quote 2 on top, got %+v, %v", ranked, err)
+		}
+	})
+
+	t.Run("Error response carries request ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/quotes/random", nil)
+		req.Header.Set(middleware.HeaderRequestID, "req-42")
+		rec := httptest.NewRecorder()
+
+		h := New(&mockService{getRandomErr: service.ErrNotFound}, log)
+		middleware.RequestID(log)(http.HandlerFunc(h.Random)).ServeHTTP(rec, req)
+
+		var resp Problem
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("expected success decode, got %v", err)
+		}
+		if resp.RequestID != "req-42" {
+			t.Errorf("expected request_id req-42, got %q", resp.RequestID)
+		}
+		if resp.Code != string(service.CodeNotFound) {
+			t.Errorf("expected code not_found, got %q", resp.Code)
+		}
+	})
+
+	t.Run("Service errors map to statuses", func(t *testing.T) {
+		tt := []struct {
+			name   string
+			err    error
+			status int
+		}{
+			{name: "deadline exceeded", err: &service.Error{Code: service.CodeTimeout, Err: context.DeadlineExceeded}, status: http.StatusGatewayTimeout},
+			{name: "canceled", err: &service.Error{Code: service.CodeCanceled, Err: context.Canceled}, status: StatusClientClosedRequest},
+			{name: "unknown error", err: errors.New("boom"), status: http.StatusInternalServerError},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.name, func(t *testing.T) {
+				req := httptest.NewRequest("DELETE", "/quotes/1", nil)
+				req = mux.SetURLVars(req, map[string]string{"id": "1"})
+				rec := httptest.NewRecorder()
+
+				h := New(&mockService{deleteErr: tc.err}, log)
+				h.Delete(rec, req)
+
+				if rec.Code != tc.status {
+					t.Errorf("expected status %d, got %d", tc.status, rec.Code)
+				}
+			})
+		}
+	})
 }
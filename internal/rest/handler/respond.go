@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+// base carries what every JSON handler needs to decode requests and report
+// errors consistently.
+type base struct {
+	logger       *logger.Logger
+	maxBodyBytes int64
+}
+
+type Option func(*base)
+
+// WithMaxBodyBytes limits the size of JSON request bodies.
+func WithMaxBodyBytes(n int64) Option {
+	return func(b *base) {
+		b.maxBodyBytes = n
+	}
+}
+
+func newBase(logger *logger.Logger, opts []Option) base {
+	b := base{
+		logger:       logger,
+		maxBodyBytes: DefaultMaxBodyBytes,
+	}
+	for _, opt := range opts {
+		opt(&b)
+	}
+	return b
+}
+
+// decodeJSON strictly decodes a single JSON object from the request body and
+// responds with a problem on failure.
+func (h *base) decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.maxBodyBytes))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(dst)
+	if err == nil && dec.Decode(&struct{}{}) != io.EOF {
+		err = errors.New("unexpected data after JSON object")
+	}
+	if err == nil {
+		return true
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		h.respondError(w, r, newProblem(r, codePayloadTooLarge, errPayloadTooLarge), err)
+		return false
+	}
+
+	p := newProblem(r, codeInvalidPayload, errInvalidRequestPayload)
+	p.Detail = errInvalidRequestPayload + ": " + err.Error()
+	h.respondError(w, r, p, err)
+	return false
+}
+
+func respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, errInvalidResponse, http.StatusInternalServerError)
+	}
+}
+
+// respondError logs server errors at error level and client errors at warn
+// level, then writes p as problem+json.
+func (h *base) respondError(w http.ResponseWriter, r *http.Request, p *Problem, err error) {
+	event := h.log(r).Warn()
+	if p.Status >= http.StatusInternalServerError {
+		event = h.log(r).Error()
+	}
+	event.Err(err).Str("code", p.Code).Msg(p.Detail)
+
+	respondProblem(w, p)
+}
+
+// respondServiceError maps an error returned by the service to a problem,
+// message is used as detail for errors that must not be exposed.
+func (h *base) respondServiceError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	h.respondError(w, r, problemFromError(r, err, message), err)
+}
+
+// log returns the request scoped logger set by middleware.RequestID.
+func (h *base) log(r *http.Request) *logger.Logger {
+	return logger.FromContext(r.Context(), h.logger)
+}
+
+// respondAuthError asks for credentials when they are missing or invalid,
+// as RFC 6750 requires for 401 responses.
+func (h *base) respondAuthError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, service.ErrUnauthorized) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="quotebook"`)
+	}
+	h.respondServiceError(w, r, errAuthorize, err)
+}
+
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+)
+
+const (
+	errAddTranslation    = "failed to add translation"
+	errGetTranslations   = "failed to get translations"
+	errGetTranslation    = "failed to get translation"
+	errDeleteTranslation = "failed to delete translation"
+)
+
+// translationInput is the accepted request body, the creation time of
+// model.Translation is set by the server.
+type translationInput struct {
+	Lang       string `json:"lang"`
+	Quote      string `json:"quote"`
+	Translator string `json:"translator"`
+}
+
+// AddTranslation attaches a translation to the quote in the id path
+// parameter.
+func (h *QuoteHandler) AddTranslation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, errGetID), err)
+		return
+	}
+
+	var in translationInput
+	if !h.decodeJSON(w, r, &in) {
+		return
+	}
+
+	created, err := h.service.AddTranslation(r.Context(), id, &model.Translation{
+		Lang:       in.Lang,
+		Quote:      in.Quote,
+		Translator: in.Translator,
+	})
+	if err != nil {
+		h.respondServiceError(w, r, errAddTranslation, err)
+		return
+	}
+
+	w.Header().Set("Location", r.URL.Path+"/"+created.Lang)
+	respondJSON(w, http.StatusCreated, created)
+}
+
+// Translations lists the translations of a quote, the same ones embedded in
+// the quote itself.
+func (h *QuoteHandler) Translations(w http.ResponseWriter, r *http.Request) {
+	quote, ok := h.pathQuote(w, r, errGetTranslations)
+	if !ok {
+		return
+	}
+
+	translations := quote.Translations
+	if translations == nil {
+		translations = []model.Translation{}
+	}
+	respondJSON(w, http.StatusOK, translations)
+}
+
+// Translation returns the translation of a quote into the lang path
+// parameter.
+func (h *QuoteHandler) Translation(w http.ResponseWriter, r *http.Request) {
+	lang, ok := h.pathLang(w, r)
+	if !ok {
+		return
+	}
+	quote, ok := h.pathQuote(w, r, errGetTranslation)
+	if !ok {
+		return
+	}
+
+	t, ok := quote.Translation(lang)
+	if !ok {
+		h.respondServiceError(w, r, errGetTranslation, service.NewNotFoundError("translation not found", nil))
+		return
+	}
+
+	w.Header().Set("Content-Language", t.Lang)
+	respondJSON(w, http.StatusOK, t)
+}
+
+func (h *QuoteHandler) DeleteTranslation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, errGetID), err)
+		return
+	}
+	lang, ok := h.pathLang(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTranslation(r.Context(), id, lang); err != nil {
+		h.respondServiceError(w, r, errDeleteTranslation, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *QuoteHandler) pathQuote(w http.ResponseWriter, r *http.Request, message string) (*model.Quote, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, errGetID), err)
+		return nil, false
+	}
+
+	quote, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		h.respondServiceError(w, r, message, err)
+		return nil, false
+	}
+	return quote, true
+}
+
+func (h *QuoteHandler) pathLang(w http.ResponseWriter, r *http.Request) (string, bool) {
+	lang, ok := service.ParseLang(mux.Vars(r)["lang"])
+	if !ok {
+		h.respondError(w, r, newProblem(r, codeInvalidParam, errInvalidLang), nil)
+		return "", false
+	}
+	return lang, true
+}
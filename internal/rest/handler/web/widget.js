@@ -0,0 +1,31 @@
+// Quotebook random quote widget. Include it where the quote should appear:
+//
+//   <script src="https://quotes.example.com/widget/random.js"
+//           data-theme="dark" data-accent="#e4572e" data-font="serif" async></script>
+//
+// Supported attributes: data-theme (light, dark, auto), data-accent (hex
+// colour), data-font (sans, serif, mono), data-width and data-height.
+(function () {
+  var script = document.currentScript;
+  if (!script) {
+    return;
+  }
+
+  var params = new URLSearchParams();
+  ["theme", "accent", "font"].forEach(function (name) {
+    var value = script.getAttribute("data-" + name);
+    if (value) {
+      params.set(name, value);
+    }
+  });
+
+  var query = params.toString();
+  var frame = document.createElement("iframe");
+  frame.src = new URL(script.src).origin + "/widget/random" + (query ? "?" + query : "");
+  frame.title = "Random quote";
+  frame.loading = "lazy";
+  frame.style.border = "0";
+  frame.style.width = script.getAttribute("data-width") || "100%";
+  frame.style.height = script.getAttribute("data-height") || "160px";
+  script.parentNode.insertBefore(frame, script.nextSibling);
+})();
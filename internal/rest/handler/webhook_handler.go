@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const (
+	errCreateWebhook    = "failed to create webhook"
+	errGetWebhooks      = "failed to get webhooks"
+	errGetWebhook       = "failed to get webhook"
+	errUpdateWebhook    = "failed to update webhook"
+	errDeleteWebhook    = "failed to delete webhook"
+	errGetWebhookID     = "failed to get webhook id"
+	errGetDeadLetters   = "failed to get dead letters"
+	errReplayDeadLetter = "failed to replay dead letter"
+	errDeleteDeadLetter = "failed to delete dead letter"
+	errGetDeadLetterID  = "failed to get dead letter id"
+)
+
+// webhookInput is the accepted request body, server managed fields of
+// model.Webhook are not part of it.
+type webhookInput struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+func (in webhookInput) webhook() *model.Webhook {
+	return &model.Webhook{URL: in.URL, Events: in.Events, Secret: in.Secret}
+}
+
+// WebhookHandler serves webhook and dead letter management to the admin
+// key.
+type WebhookHandler struct {
+	base
+	service service.Webhook
+}
+
+func NewWebhooks(service service.Webhook, logger *logger.Logger, opts ...Option) *WebhookHandler {
+	return &WebhookHandler{
+		base:    newBase(logger, opts),
+		service: service,
+	}
+}
+
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	var in webhookInput
+	if !h.decodeJSON(w, r, &in) {
+		return
+	}
+
+	created, err := h.service.Create(r.Context(), in.webhook())
+	if err != nil {
+		h.respondServiceError(w, r, errCreateWebhook, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, created)
+}
+
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	webhooks, err := h.service.List(r.Context())
+	if err != nil {
+		h.respondServiceError(w, r, errGetWebhooks, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, webhooks)
+}
+
+func (h *WebhookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	id, ok := h.pathID(w, r, errGetWebhookID)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		h.respondServiceError(w, r, errGetWebhook, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, webhook)
+}
+
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	id, ok := h.pathID(w, r, errGetWebhookID)
+	if !ok {
+		return
+	}
+
+	var in webhookInput
+	if !h.decodeJSON(w, r, &in) {
+		return
+	}
+
+	updated, err := h.service.Update(r.Context(), id, in.webhook())
+	if err != nil {
+		h.respondServiceError(w, r, errUpdateWebhook, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, updated)
+}
+
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	id, ok := h.pathID(w, r, errGetWebhookID)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		h.respondServiceError(w, r, errDeleteWebhook, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	deadLetters, err := h.service.ListDeadLetters(r.Context())
+	if err != nil {
+		h.respondServiceError(w, r, errGetDeadLetters, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, deadLetters)
+}
+
+func (h *WebhookHandler) ReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	id, ok := h.pathID(w, r, errGetDeadLetterID)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ReplayDeadLetter(r.Context(), id); err != nil {
+		h.respondServiceError(w, r, errReplayDeadLetter, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *WebhookHandler) DeleteDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	id, ok := h.pathID(w, r, errGetDeadLetterID)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteDeadLetter(r.Context(), id); err != nil {
+		h.respondServiceError(w, r, errDeleteDeadLetter, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) pathID(w http.ResponseWriter, r *http.Request, message string) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, r, newProblem(r, codeInvalidID, message), err)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *WebhookHandler) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if err := h.service.AuthorizeAdmin(bearerToken(r)); err != nil {
+		h.respondAuthError(w, r, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Deprecation marks every response as deprecated (RFC 9745) with a sunset
+// date (RFC 8594) and points clients at the successor path prefix.
+func Deprecation(deprecatedAt, sunsetAt time.Time, successor string) func(http.Handler) http.Handler {
+	deprecation := "@" + strconv.FormatInt(deprecatedAt.Unix(), 10)
+	sunset := sunsetAt.UTC().Format(http.TimeFormat)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			w.Header().Set("Sunset", sunset)
+			w.Header().Add("Link", "<"+successor+r.URL.Path+`>; rel="successor-version"`)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"bufio"
+	"net"
 	"net/http"
 	"time"
 
@@ -15,13 +17,12 @@ func Logging(log *logger.Logger) func(http.Handler) http.Handler {
 			lw := &loggingResponseWriter{w, http.StatusOK}
 			next.ServeHTTP(lw, r)
 
-			log.Info().Msgf(
-				"method=%s path=%s status=%d duration=%s",
-				r.Method,
-				r.URL.Path,
-				lw.status,
-				time.Since(start),
-			)
+			logger.FromContext(r.Context(), log).Info().
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Int("status", lw.status).
+				Dur("duration", time.Since(start)).
+				Msg("request completed")
 		})
 	}
 }
@@ -35,3 +36,21 @@ func (l *loggingResponseWriter) WriteHeader(status int) {
 	l.status = status
 	l.ResponseWriter.WriteHeader(status)
 }
+
+// Flush and Hijack pass through to the wrapped writer so streaming handlers
+// (Server-Sent Events, WebSocket) work behind this middleware.
+func (l *loggingResponseWriter) Flush() {
+	_ = http.NewResponseController(l.ResponseWriter).Flush()
+}
+
+func (l *loggingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(l.ResponseWriter).Hijack()
+	if err == nil {
+		l.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+func (l *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return l.ResponseWriter
+}
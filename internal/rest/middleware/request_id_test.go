@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+func TestRequestID(t *testing.T) {
+	log := logger.New("debug", "console")
+
+	var gotID string
+	var gotLogger *logger.Logger
+	h := RequestID(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = RequestIDFromContext(r.Context())
+		gotLogger = logger.FromContext(r.Context(), nil)
+	}))
+
+	t.Run("Accepts client request ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/quotes", nil)
+		req.Header.Set(HeaderRequestID, "client-id-1")
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		if gotID != "client-id-1" {
+			t.Errorf("expected client-id-1 in context, got %q", gotID)
+		}
+		if rec.Header().Get(HeaderRequestID) != "client-id-1" {
+			t.Errorf("expected echoed request ID, got %q", rec.Header().Get(HeaderRequestID))
+		}
+		if gotLogger == nil || gotLogger == log {
+			t.Error("expected request scoped logger in context")
+		}
+	})
+
+	t.Run("Generates request ID when missing or invalid", func(t *testing.T) {
+		for _, header := range []string{"", "bad id", strings.Repeat("a", maxRequestIDLength+1)} {
+			req := httptest.NewRequest("GET", "/quotes", nil)
+			req.Header.Set(HeaderRequestID, header)
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if gotID == "" || gotID == header {
+				t.Errorf("expected generated request ID for %q, got %q", header, gotID)
+			}
+			if rec.Header().Get(HeaderRequestID) != gotID {
+				t.Errorf("expected response header %q, got %q", gotID, rec.Header().Get(HeaderRequestID))
+			}
+		}
+	})
+}
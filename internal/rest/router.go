@@ -2,24 +2,323 @@ package rest
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
 	"github.com/zonder12120/brandscout-quotebook/internal/rest/handler"
 	"github.com/zonder12120/brandscout-quotebook/internal/rest/middleware"
 	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
 )
 
-func NewRouter(h *handler.QuoteHandler, logger *logger.Logger) http.Handler {
+const (
+	readTimeout  = 2 * time.Second
+	writeTimeout = 5 * time.Second
+)
+
+// Unversioned paths are aliases of /v1 kept for existing clients.
+var (
+	legacyDeprecatedAt = time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)
+	legacySunsetAt     = time.Date(2027, time.May, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// apiVersion is a handler set mounted under its own path prefix. Versions
+// share the service layer and differ only in routes and response shapes.
+type apiVersion struct {
+	prefix   string
+	register func(r *mux.Router)
+}
+
+// routes holds the optional APIs mounted next to the quote handlers.
+type routes struct {
+	graphql  http.Handler
+	events   *handler.EventsHandler
+	webhooks *handler.WebhookHandler
+	feeds    *handler.FeedHandler
+	pages    *handler.PagesHandler
+	images   *handler.ImageHandler
+
+	collections *handler.CollectionHandler
+	moderation  *handler.ModerationHandler
+	playlists   *handler.PlaylistHandler
+}
+
+type Option func(*routes)
+
+// WithGraphQL serves GraphQL queries at /graphql.
+func WithGraphQL(gql http.Handler) Option {
+	return func(rt *routes) {
+		rt.graphql = gql
+	}
+}
+
+// WithEvents serves the quote change feed at /quotes/events (SSE) and
+// /quotes/events/ws (WebSocket).
+func WithEvents(events *handler.EventsHandler) Option {
+	return func(rt *routes) {
+		rt.events = events
+	}
+}
+
+// WithWebhooks serves webhook management at /v1/webhooks.
+func WithWebhooks(webhooks *handler.WebhookHandler) Option {
+	return func(rt *routes) {
+		rt.webhooks = webhooks
+	}
+}
+
+// WithFeeds serves recent quotes as RSS and Atom at /quotes/feed.rss and
+// /quotes/feed.atom.
+func WithFeeds(feeds *handler.FeedHandler) Option {
+	return func(rt *routes) {
+		rt.feeds = feeds
+	}
+}
+
+// WithPages serves HTML renderings of the quote routes to browsers and the
+// embeddable widget at /widget/random.
+func WithPages(pages *handler.PagesHandler) Option {
+	return func(rt *routes) {
+		rt.pages = pages
+	}
+}
+
+// WithImages serves quote cards at /quotes/{id}/image.svg and image.png.
+func WithImages(images *handler.ImageHandler) Option {
+	return func(rt *routes) {
+		rt.images = images
+	}
+}
+
+// WithCollections serves collection management at /v1/collections and the
+// quote API of every collection at /v1/collections/{collection}/quotes.
+func WithCollections(collections *handler.CollectionHandler) Option {
+	return func(rt *routes) {
+		rt.collections = collections
+	}
+}
+
+// WithModeration serves submission for review at /v1/quotes/{id}/submit
+// and the reviewer API at /v1/moderation.
+func WithModeration(moderation *handler.ModerationHandler) Option {
+	return func(rt *routes) {
+		rt.moderation = moderation
+	}
+}
+
+// WithPlaylists serves playlist management and playback at /v1/playlists.
+func WithPlaylists(playlists *handler.PlaylistHandler) Option {
+	return func(rt *routes) {
+		rt.playlists = playlists
+	}
+}
+
+func NewRouter(h *handler.QuoteHandler, logger *logger.Logger, opts ...Option) http.Handler {
+	var rt routes
+	for _, opt := range opts {
+		opt(&rt)
+	}
+
 	r := mux.NewRouter()
 
+	r.Use(middleware.RequestID(logger))
 	r.Use(middleware.Logging(logger))
 
-	r.HandleFunc("/quotes", h.Create).Methods("POST")
-	r.HandleFunc("/quotes", h.FilterByAuthor).Methods("GET").Queries("author", "{author}")
-	r.HandleFunc("/quotes", h.List).Methods("GET")
-	r.HandleFunc("/quotes/random", h.Random).Methods("GET")
-	r.HandleFunc("/quotes/{id:[0-9]+}", h.Delete).Methods("DELETE")
+	r.HandleFunc("/openapi.json", handler.OpenAPISpec).Methods("GET")
+	r.HandleFunc("/docs", handler.DocsPage).Methods("GET")
+	if rt.graphql != nil {
+		r.Handle("/graphql", middleware.Timeout(writeTimeout)(rt.graphql)).Methods("POST")
+	}
+	if rt.pages != nil {
+		r.Handle("/widget/random", withTimeout(readTimeout, rt.pages.Widget)).Methods("GET")
+		r.HandleFunc("/widget/random.js", rt.pages.WidgetScript).Methods("GET")
+	}
+
+	quotes := quoteRoutesV1(h, rt)
+	v1 := apiVersion{prefix: "/v1", register: func(r *mux.Router) {
+		quotes(r)
+		translationRoutesV1(h)(r)
+		popularityRoutesV1(h)(r)
+		batchRoutesV1(h)(r)
+		webhookRoutesV1(rt)(r)
+		collectionRoutesV1(rt)(r)
+		moderationRoutesV1(rt)(r)
+		playlistRoutesV1(rt)(r)
+	}}
+	mountVersions(r, v1)
+
+	// Only the quote API predates versioning, newer APIs have no aliases.
+	legacy := r.NewRoute().Subrouter()
+	legacy.Use(middleware.Deprecation(legacyDeprecatedAt, legacySunsetAt, v1.prefix))
+	quotes(legacy)
 
 	return r
 }
+
+func mountVersions(r *mux.Router, versions ...apiVersion) {
+	for _, v := range versions {
+		v.register(r.PathPrefix(v.prefix).Subrouter())
+	}
+}
+
+func quoteRoutesV1(h *handler.QuoteHandler, rt routes) func(r *mux.Router) {
+	return func(r *mux.Router) {
+		// Streams are long-lived and must not inherit request timeouts.
+		if rt.events != nil {
+			r.HandleFunc("/quotes/events", rt.events.Stream).Methods("GET")
+			r.HandleFunc("/quotes/events/ws", rt.events.WebSocket).Methods("GET")
+		}
+
+		list, byAuthor, random, get := h.List, h.FilterByAuthor, h.Random, h.Get
+		if p := rt.pages; p != nil {
+			list = negotiated(p.List, h.List)
+			byAuthor = negotiated(p.List, h.FilterByAuthor)
+			random = negotiated(p.Random, h.Random)
+			get = negotiated(p.Quote, h.Get)
+		}
+
+		r.Handle("/quotes", withTimeout(writeTimeout, h.Create)).Methods("POST")
+		r.Handle("/quotes", withTimeout(readTimeout, byAuthor)).Methods("GET").Queries("author", "{author}")
+		r.Handle("/quotes", withTimeout(readTimeout, list)).Methods("GET")
+		r.Handle("/quotes/random", withTimeout(readTimeout, random)).Methods("GET")
+		if rt.feeds != nil {
+			r.Handle("/quotes/feed.rss", withTimeout(readTimeout, rt.feeds.RSS)).Methods("GET")
+			r.Handle("/quotes/feed.atom", withTimeout(readTimeout, rt.feeds.Atom)).Methods("GET")
+		}
+		r.Handle("/quotes/{id:[0-9]+}", withTimeout(readTimeout, get)).Methods("GET")
+		r.Handle("/quotes/{id:[0-9]+}", withTimeout(writeTimeout, h.Delete)).Methods("DELETE")
+		if rt.images != nil {
+			r.Handle("/quotes/{id:[0-9]+}/image.svg", withTimeout(writeTimeout, rt.images.SVG)).Methods("GET")
+			r.Handle("/quotes/{id:[0-9]+}/image.png", withTimeout(writeTimeout, rt.images.PNG)).Methods("GET")
+		}
+	}
+}
+
+func translationRoutesV1(h *handler.QuoteHandler) func(r *mux.Router) {
+	return func(r *mux.Router) {
+		r.Handle("/quotes/{id:[0-9]+}/translations", withTimeout(writeTimeout, h.AddTranslation)).Methods("POST")
+		r.Handle("/quotes/{id:[0-9]+}/translations", withTimeout(readTimeout, h.Translations)).Methods("GET")
+		r.Handle("/quotes/{id:[0-9]+}/translations/{lang}", withTimeout(readTimeout, h.Translation)).Methods("GET")
+		r.Handle("/quotes/{id:[0-9]+}/translations/{lang}", withTimeout(writeTimeout, h.DeleteTranslation)).Methods("DELETE")
+	}
+}
+
+func batchRoutesV1(h *handler.QuoteHandler) func(r *mux.Router) {
+	return func(r *mux.Router) {
+		r.Handle("/quotes/batch", withTimeout(writeTimeout, h.Batch)).Methods("POST")
+	}
+}
+
+func popularityRoutesV1(h *handler.QuoteHandler) func(r *mux.Router) {
+	return func(r *mux.Router) {
+		r.Handle("/quotes/top", withTimeout(readTimeout, h.Top)).Methods("GET")
+		r.Handle("/quotes/{id:[0-9]+}/popularity", withTimeout(readTimeout, h.Popularity)).Methods("GET")
+		r.Handle("/quotes/{id:[0-9]+}/like", withTimeout(writeTimeout, h.Like)).Methods("PUT")
+		r.Handle("/quotes/{id:[0-9]+}/like", withTimeout(writeTimeout, h.Unlike)).Methods("DELETE")
+		r.Handle("/quotes/{id:[0-9]+}/rating", withTimeout(writeTimeout, h.Rate)).Methods("PUT")
+		r.Handle("/quotes/{id:[0-9]+}/rating", withTimeout(writeTimeout, h.Unrate)).Methods("DELETE")
+	}
+}
+
+func webhookRoutesV1(rt routes) func(r *mux.Router) {
+	return func(r *mux.Router) {
+		h := rt.webhooks
+		if h == nil {
+			return
+		}
+
+		r.Handle("/webhooks", withTimeout(writeTimeout, h.Create)).Methods("POST")
+		r.Handle("/webhooks", withTimeout(readTimeout, h.List)).Methods("GET")
+		r.Handle("/webhooks/dead-letters", withTimeout(readTimeout, h.ListDeadLetters)).Methods("GET")
+		r.Handle("/webhooks/dead-letters/{id:[0-9]+}/replay", withTimeout(writeTimeout, h.ReplayDeadLetter)).Methods("POST")
+		r.Handle("/webhooks/dead-letters/{id:[0-9]+}", withTimeout(writeTimeout, h.DeleteDeadLetter)).Methods("DELETE")
+		r.Handle("/webhooks/{id:[0-9]+}", withTimeout(readTimeout, h.Get)).Methods("GET")
+		r.Handle("/webhooks/{id:[0-9]+}", withTimeout(writeTimeout, h.Update)).Methods("PUT")
+		r.Handle("/webhooks/{id:[0-9]+}", withTimeout(writeTimeout, h.Delete)).Methods("DELETE")
+	}
+}
+
+func collectionRoutesV1(rt routes) func(r *mux.Router) {
+	return func(r *mux.Router) {
+		h := rt.collections
+		if h == nil {
+			return
+		}
+
+		r.Handle("/collections", withTimeout(writeTimeout, h.Create)).Methods("POST")
+		r.Handle("/collections", withTimeout(readTimeout, h.List)).Methods("GET")
+		r.Handle("/collections/{collection}", withTimeout(writeTimeout, h.Delete)).Methods("DELETE")
+		r.Handle("/collections/{collection}/keys", withTimeout(writeTimeout, h.CreateKey)).Methods("POST")
+		r.Handle("/collections/{collection}/keys", withTimeout(readTimeout, h.ListKeys)).Methods("GET")
+		r.Handle("/collections/{collection}/keys/{id:[0-9]+}", withTimeout(writeTimeout, h.DeleteKey)).Methods("DELETE")
+
+		read, write := model.PermissionRead, model.PermissionWrite
+		r.Handle("/collections/{collection}/quotes", withTimeout(writeTimeout, h.Quotes((*handler.QuoteHandler).Create, write))).Methods("POST")
+		r.Handle("/collections/{collection}/quotes", withTimeout(readTimeout, h.Quotes((*handler.QuoteHandler).FilterByAuthor, read))).Methods("GET").Queries("author", "{author}")
+		r.Handle("/collections/{collection}/quotes", withTimeout(readTimeout, h.Quotes((*handler.QuoteHandler).List, read))).Methods("GET")
+		r.Handle("/collections/{collection}/quotes/random", withTimeout(readTimeout, h.Quotes((*handler.QuoteHandler).Random, read))).Methods("GET")
+		r.Handle("/collections/{collection}/quotes/{id:[0-9]+}", withTimeout(readTimeout, h.Quotes((*handler.QuoteHandler).Get, read))).Methods("GET")
+		r.Handle("/collections/{collection}/quotes/{id:[0-9]+}", withTimeout(writeTimeout, h.Quotes((*handler.QuoteHandler).Delete, write))).Methods("DELETE")
+
+		// Review is open to the reviewer key only, which the handler checks.
+		r.Handle("/collections/{collection}/quotes/{id:[0-9]+}/submit", withTimeout(writeTimeout, h.Moderation((*handler.ModerationHandler).Submit, write))).Methods("POST")
+		r.Handle("/collections/{collection}/moderation/queue", withTimeout(readTimeout, h.Moderation((*handler.ModerationHandler).Queue, ""))).Methods("GET")
+		r.Handle("/collections/{collection}/moderation/quotes/{id:[0-9]+}", withTimeout(readTimeout, h.Moderation((*handler.ModerationHandler).Get, ""))).Methods("GET")
+		r.Handle("/collections/{collection}/moderation/quotes/{id:[0-9]+}/approve", withTimeout(writeTimeout, h.Moderation((*handler.ModerationHandler).Approve, ""))).Methods("POST")
+		r.Handle("/collections/{collection}/moderation/quotes/{id:[0-9]+}/reject", withTimeout(writeTimeout, h.Moderation((*handler.ModerationHandler).Reject, ""))).Methods("POST")
+	}
+}
+
+func moderationRoutesV1(rt routes) func(r *mux.Router) {
+	return func(r *mux.Router) {
+		h := rt.moderation
+		if h == nil {
+			return
+		}
+
+		r.Handle("/quotes/{id:[0-9]+}/submit", withTimeout(writeTimeout, h.Submit)).Methods("POST")
+		r.Handle("/moderation/queue", withTimeout(readTimeout, h.Queue)).Methods("GET")
+		r.Handle("/moderation/quotes/{id:[0-9]+}", withTimeout(readTimeout, h.Get)).Methods("GET")
+		r.Handle("/moderation/quotes/{id:[0-9]+}/approve", withTimeout(writeTimeout, h.Approve)).Methods("POST")
+		r.Handle("/moderation/quotes/{id:[0-9]+}/reject", withTimeout(writeTimeout, h.Reject)).Methods("POST")
+	}
+}
+
+func playlistRoutesV1(rt routes) func(r *mux.Router) {
+	return func(r *mux.Router) {
+		h := rt.playlists
+		if h == nil {
+			return
+		}
+
+		r.Handle("/playlists", withTimeout(writeTimeout, h.Create)).Methods("POST")
+		r.Handle("/playlists", withTimeout(readTimeout, h.List)).Methods("GET")
+		r.Handle("/playlists/{id:[0-9]+}", withTimeout(readTimeout, h.Get)).Methods("GET")
+		r.Handle("/playlists/{id:[0-9]+}", withTimeout(writeTimeout, h.Update)).Methods("PUT")
+		r.Handle("/playlists/{id:[0-9]+}", withTimeout(writeTimeout, h.Delete)).Methods("DELETE")
+		r.Handle("/playlists/{id:[0-9]+}/quotes", withTimeout(readTimeout, h.Quotes)).Methods("GET")
+		r.Handle("/playlists/{id:[0-9]+}/quotes", withTimeout(writeTimeout, h.AddQuote)).Methods("POST")
+		r.Handle("/playlists/{id:[0-9]+}/quotes/random", withTimeout(readTimeout, h.Random)).Methods("GET")
+		r.Handle("/playlists/{id:[0-9]+}/quotes/next", withTimeout(readTimeout, h.Next)).Methods("GET")
+		r.Handle("/playlists/{id:[0-9]+}/quotes/{quote_id:[0-9]+}/move", withTimeout(writeTimeout, h.MoveQuote)).Methods("POST")
+		r.Handle("/playlists/{id:[0-9]+}/quotes/{quote_id:[0-9]+}", withTimeout(writeTimeout, h.RemoveQuote)).Methods("DELETE")
+	}
+}
+
+// negotiated serves browsers the HTML page and every other client the JSON
+// representation of the same resource.
+func negotiated(html, json http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		if handler.AcceptsHTML(r) {
+			html(w, r)
+			return
+		}
+		json(w, r)
+	}
+}
+
+func withTimeout(d time.Duration, h http.HandlerFunc) http.Handler {
+	return middleware.Timeout(d)(h)
+}
@@ -0,0 +1,515 @@
+package rest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	"github.com/zonder12120/brandscout-quotebook/api"
+	"github.com/zonder12120/brandscout-quotebook/internal/card"
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
+	"github.com/zonder12120/brandscout-quotebook/internal/gql"
+	"github.com/zonder12120/brandscout-quotebook/internal/rest/handler"
+	"github.com/zonder12120/brandscout-quotebook/internal/rest/middleware"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const (
+	testAdminKey    = "test-admin-key"
+	testReviewerKey = "test-reviewer-key"
+)
+
+var pathVarPattern = regexp.MustCompile(`\{(\w+):[^}]+\}`)
+
+type openAPISpec map[string]interface{}
+
+func loadSpec(t *testing.T) openAPISpec {
+	t.Helper()
+
+	var spec openAPISpec
+	if err := json.Unmarshal(api.OpenAPISpec, &spec); err != nil {
+		t.Fatalf("invalid OpenAPI document: %v", err)
+	}
+	if spec["openapi"] != "3.1.0" {
+		t.Fatalf("expected OpenAPI 3.1.0, got %v", spec["openapi"])
+	}
+	return spec
+}
+
+func newTestRouter() *mux.Router {
+	log := logger.New("error", "console")
+	quotes := storage.NewInMemory(10)
+	playlists := service.NewPlaylistService(storage.NewPlaylistInMemory(), quotes)
+	svc := service.NewQuoteService(quotes, service.WithModeration(testReviewerKey), service.WithRemovalHook(playlists.RemoveQuotes))
+	gqlHandler, err := gql.New(svc, log)
+	if err != nil {
+		panic(err)
+	}
+	renderer, err := card.NewRenderer()
+	if err != nil {
+		panic(err)
+	}
+	return NewRouter(handler.New(svc, log), log,
+		WithGraphQL(gqlHandler),
+		WithEvents(handler.NewEvents(events.NewBus(), log)),
+		WithWebhooks(handler.NewWebhooks(service.NewWebhookService(storage.NewWebhookInMemory(10), nil, service.WithWebhookAdminKey(testAdminKey)), log)),
+		WithFeeds(handler.NewFeeds(svc, log)),
+		WithPages(handler.NewPages(svc, log)),
+		WithImages(handler.NewImages(svc, renderer, log)),
+		WithCollections(handler.NewCollections(service.NewCollectionService(storage.NewCollectionInMemory(),
+			service.WithAdminKey(testAdminKey),
+			service.WithQuoteOptions(service.WithModeration(testReviewerKey)),
+		), log)),
+		WithModeration(handler.NewModeration(svc, log)),
+		WithPlaylists(handler.NewPlaylists(playlists, log)),
+	).(*mux.Router)
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	spec := loadSpec(t)
+	paths := spec["paths"].(map[string]interface{})
+
+	t.Run("Every route is documented", func(t *testing.T) {
+		routed := make(map[string]bool)
+
+		err := newTestRouter().Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			// Subrouter and prefix routes carry no methods, only leaf routes are served.
+			tpl, err := route.GetPathTemplate()
+			if err != nil {
+				return nil
+			}
+			methods, err := route.GetMethods()
+			if err != nil {
+				return nil
+			}
+
+			path := pathVarPattern.ReplaceAllString(tpl, "{$1}")
+			for _, method := range methods {
+				key := strings.ToLower(method) + " " + path
+				routed[key] = true
+
+				item, ok := paths[path].(map[string]interface{})
+				if !ok || item[strings.ToLower(method)] == nil {
+					t.Errorf("route %s %s is missing from the OpenAPI document", method, path)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("walk router: %v", err)
+		}
+
+		for path, item := range paths {
+			for method := range item.(map[string]interface{}) {
+				if !routed[method+" "+path] {
+					t.Errorf("documented operation %s %s is not routed", strings.ToUpper(method), path)
+				}
+			}
+		}
+	})
+
+	t.Run("Responses match declared schemas", func(t *testing.T) {
+		router := newTestRouter()
+
+		tt := []struct {
+			method string
+			target string
+			body   string
+			token  string
+			user   string
+			status int
+		}{
+			{method: "GET", target: "/v1/quotes/random", status: http.StatusNotFound},
+			{method: "POST", target: "/v1/quotes", body: `{"author": "Confucius", "quote": "Life is simple."}`, status: http.StatusCreated},
+			{method: "GET", target: "/v1/quotes/1", status: http.StatusNotFound},
+			{method: "PUT", target: "/v1/quotes/1/like", user: "alice", status: http.StatusNotFound},
+			{method: "GET", target: "/v1/moderation/quotes/1", status: http.StatusUnauthorized},
+			{method: "GET", target: "/v1/moderation/quotes/1", token: testReviewerKey, status: http.StatusOK},
+			{method: "GET", target: "/v1/moderation/queue", status: http.StatusUnauthorized},
+			{method: "GET", target: "/v1/moderation/queue?limit=5", token: testReviewerKey, status: http.StatusOK},
+			{method: "POST", target: "/v1/moderation/quotes/1/approve", token: "wrong", status: http.StatusForbidden},
+			{method: "POST", target: "/v1/moderation/quotes/1/approve", token: testReviewerKey, status: http.StatusOK},
+			{method: "POST", target: "/v1/moderation/quotes/1/approve", token: testReviewerKey, status: http.StatusConflict},
+			{method: "POST", target: "/v1/quotes", body: `{"author": "", "quote": ""}`, status: http.StatusBadRequest},
+			{method: "POST", target: "/v1/quotes", body: `{"author": "A", "quote": "Q", "id": 5, "x": 1}`, status: http.StatusBadRequest},
+			{method: "GET", target: "/v1/quotes", status: http.StatusOK},
+			{method: "GET", target: "/v1/quotes?author=confucius", status: http.StatusOK},
+			{method: "GET", target: "/v1/quotes?author=", status: http.StatusBadRequest},
+			{method: "GET", target: "/v1/quotes/random", status: http.StatusOK},
+			{method: "GET", target: "/quotes", status: http.StatusOK},
+			{method: "POST", target: "/quotes", body: `{"author": "Seneca", "quote": "Luck is what happens when preparation meets opportunity."}`, status: http.StatusCreated},
+			{method: "POST", target: "/v1/moderation/quotes/2/reject", body: `{"reason": " "}`, token: testReviewerKey, status: http.StatusBadRequest},
+			{method: "POST", target: "/v1/moderation/quotes/2/reject", body: `{"reason": "Misattributed."}`, token: testReviewerKey, status: http.StatusOK},
+			{method: "POST", target: "/v1/quotes/2/submit", status: http.StatusOK},
+			{method: "POST", target: "/v1/moderation/quotes/2/approve", token: testReviewerKey, status: http.StatusOK},
+			{method: "POST", target: "/v1/quotes", body: `{"author": "Seneca", "quote": "We suffer more in imagination.", "status": "draft"}`, status: http.StatusCreated},
+			{method: "POST", target: "/v1/quotes", body: `{"author": "Seneca", "quote": "Q", "status": "published"}`, status: http.StatusBadRequest},
+			{method: "POST", target: "/v1/quotes/3/submit", status: http.StatusOK},
+			{method: "POST", target: "/v1/quotes/3/submit", status: http.StatusConflict},
+			{method: "POST", target: "/v1/quotes/99/submit", status: http.StatusNotFound},
+			{method: "GET", target: "/v1/quotes/feed.rss", status: http.StatusOK},
+			{method: "GET", target: "/v1/quotes/feed.atom?author=seneca", status: http.StatusOK},
+			{method: "GET", target: "/quotes/feed.atom", status: http.StatusOK},
+			{method: "GET", target: "/v1/quotes/1", status: http.StatusOK},
+			{method: "GET", target: "/v1/quotes/1/image.svg?theme=dark", status: http.StatusOK},
+			{method: "GET", target: "/quotes/1/image.png?width=400&height=400", status: http.StatusOK},
+			{method: "GET", target: "/v1/quotes/1/image.png?width=10", status: http.StatusBadRequest},
+			{method: "POST", target: "/v1/quotes/1/translations", body: `{"lang": "ru", "quote": "Жизнь проста.", "translator": "Anon"}`, status: http.StatusCreated},
+			{method: "POST", target: "/v1/quotes/1/translations", body: `{"lang": "RU", "quote": "Жизнь простая."}`, status: http.StatusConflict},
+			{method: "POST", target: "/v1/quotes/1/translations", body: `{"lang": "russian", "quote": ""}`, status: http.StatusBadRequest},
+			{method: "GET", target: "/v1/quotes/1/translations", status: http.StatusOK},
+			{method: "GET", target: "/v1/quotes/1/translations/ru", status: http.StatusOK},
+			{method: "GET", target: "/v1/quotes/random?lang=ru", status: http.StatusOK},
+			{method: "DELETE", target: "/v1/quotes/1/translations/ru", status: http.StatusNoContent},
+			{method: "GET", target: "/v1/quotes/1/translations/ru", status: http.StatusNotFound},
+			{method: "PUT", target: "/v1/quotes/1/like", user: "alice", status: http.StatusOK},
+			{method: "PUT", target: "/v1/quotes/1/like", status: http.StatusBadRequest},
+			{method: "DELETE", target: "/v1/quotes/1/like", user: "bob", status: http.StatusOK},
+			{method: "PUT", target: "/v1/quotes/1/rating", body: `{"rating": 5}`, user: "alice", status: http.StatusOK},
+			{method: "PUT", target: "/v1/quotes/1/rating", body: `{"rating": 0}`, user: "alice", status: http.StatusBadRequest},
+			{method: "PUT", target: "/v1/quotes/99/rating", body: `{"rating": 3}`, user: "alice", status: http.StatusNotFound},
+			{method: "GET", target: "/v1/quotes/1/popularity", status: http.StatusOK},
+			{method: "GET", target: "/v1/quotes/top?limit=5", status: http.StatusOK},
+			{method: "GET", target: "/v1/quotes/top?limit=0", status: http.StatusBadRequest},
+			{method: "GET", target: "/v1/quotes/random?weighted=true", status: http.StatusOK},
+			{method: "GET", target: "/v1/quotes/random?weighted=maybe", status: http.StatusBadRequest},
+			{method: "DELETE", target: "/v1/quotes/1/rating", user: "alice", status: http.StatusOK},
+			{method: "GET", target: "/quotes/99", status: http.StatusNotFound},
+			{method: "POST", target: "/v1/playlists", body: `{"name": "Monday motivation", "quote_ids": [2]}`, status: http.StatusCreated},
+			{method: "POST", target: "/v1/playlists", body: `{"name": "", "quote_ids": [99]}`, status: http.StatusBadRequest},
+			{method: "GET", target: "/v1/playlists", status: http.StatusOK},
+			{method: "GET", target: "/v1/playlists/1", status: http.StatusOK},
+			{method: "GET", target: "/v1/playlists/2", status: http.StatusNotFound},
+			{method: "POST", target: "/v1/playlists/1/quotes", body: `{"quote_id": 1, "position": 0}`, status: http.StatusOK},
+			{method: "POST", target: "/v1/playlists/1/quotes", body: `{"quote_id": 1}`, status: http.StatusConflict},
+			{method: "POST", target: "/v1/playlists/1/quotes", body: `{"quote_id": 3}`, status: http.StatusBadRequest},
+			{method: "POST", target: "/v1/playlists/1/quotes/1/move", body: `{"position": 1}`, status: http.StatusOK},
+			{method: "POST", target: "/v1/playlists/1/quotes/1/move", body: `{"position": 5}`, status: http.StatusBadRequest},
+			{method: "GET", target: "/v1/playlists/1/quotes", status: http.StatusOK},
+			{method: "GET", target: "/v1/playlists/1/quotes/random", status: http.StatusOK},
+			{method: "GET", target: "/v1/playlists/1/quotes/next?after=1", status: http.StatusOK},
+			{method: "GET", target: "/v1/playlists/1/quotes/next?after=99", status: http.StatusNotFound},
+			{method: "DELETE", target: "/v1/playlists/1/quotes/2", status: http.StatusOK},
+			{method: "PUT", target: "/v1/playlists/1", body: `{"name": "Weekly", "description": "Quotes for the week", "quote_ids": [1, 2]}`, status: http.StatusOK},
+			{method: "POST", target: "/v1/quotes/batch", body: `{"operations": [{"op": "create", "temp_id": "a", "quote": {"author": "Batch", "quote": "First"}}, {"op": "update", "temp_id": "a", "quote": {"author": "Batch", "quote": "First, edited"}}]}`, status: http.StatusOK},
+			{method: "POST", target: "/v1/quotes/batch", body: `{"operations": [{"op": "create", "quote": {"author": "Batch", "quote": "Second"}}, {"op": "delete", "id": 999}]}`, status: http.StatusNotFound},
+			{method: "POST", target: "/v1/quotes/batch", body: `{"atomic": false, "operations": [{"op": "delete", "id": 999}, {"op": "update", "temp_id": "b", "quote": {"author": "Batch", "quote": "Third"}}]}`, status: http.StatusOK},
+			{method: "POST", target: "/v1/quotes/batch", body: `{"operations": []}`, status: http.StatusBadRequest},
+			{method: "DELETE", target: "/v1/quotes/1", status: http.StatusNoContent},
+			{method: "DELETE", target: "/v1/playlists/1", status: http.StatusNoContent},
+			{method: "DELETE", target: "/quotes/1", status: http.StatusNotFound},
+			{method: "GET", target: "/openapi.json", status: http.StatusOK},
+			{method: "GET", target: "/docs", status: http.StatusOK},
+			{method: "GET", target: "/widget/random?theme=dark", status: http.StatusOK},
+			{method: "GET", target: "/widget/random.js", status: http.StatusOK},
+			{method: "POST", target: "/v1/webhooks", body: `{"url": "https://example.com/hook", "events": ["created"]}`, status: http.StatusUnauthorized},
+			{method: "GET", target: "/v1/webhooks/dead-letters", token: testReviewerKey, status: http.StatusForbidden},
+			{method: "POST", target: "/v1/webhooks", body: `{"url": "https://example.com/hook", "events": ["created"]}`, token: testAdminKey, status: http.StatusCreated},
+			{method: "POST", target: "/v1/webhooks", body: `{"url": "nope"}`, token: testAdminKey, status: http.StatusBadRequest},
+			{method: "GET", target: "/v1/webhooks", token: testAdminKey, status: http.StatusOK},
+			{method: "GET", target: "/v1/webhooks/1", token: testAdminKey, status: http.StatusOK},
+			{method: "PUT", target: "/v1/webhooks/1", body: `{"url": "https://example.com/other"}`, token: testAdminKey, status: http.StatusOK},
+			{method: "GET", target: "/v1/webhooks/dead-letters", token: testAdminKey, status: http.StatusOK},
+			{method: "POST", target: "/v1/webhooks/dead-letters/1/replay", token: testAdminKey, status: http.StatusNotFound},
+			{method: "DELETE", target: "/v1/webhooks/1", token: testAdminKey, status: http.StatusNoContent},
+			{method: "POST", target: "/v1/collections", body: `{"name": "team-a", "quotes_limit": 5}`, token: testAdminKey, status: http.StatusCreated},
+			{method: "POST", target: "/v1/collections", body: `{"name": "team-a"}`, token: testAdminKey, status: http.StatusConflict},
+			{method: "POST", target: "/v1/collections", body: `{"name": "Team A"}`, token: testAdminKey, status: http.StatusBadRequest},
+			{method: "POST", target: "/v1/collections", body: `{"name": "team-b"}`, status: http.StatusUnauthorized},
+			{method: "GET", target: "/v1/collections", token: "wrong", status: http.StatusForbidden},
+			{method: "GET", target: "/v1/collections", token: testAdminKey, status: http.StatusOK},
+			{method: "POST", target: "/v1/collections/team-a/keys", body: `{"name": "ci", "permissions": ["read", "write"]}`, token: testAdminKey, status: http.StatusCreated},
+			{method: "POST", target: "/v1/collections/team-a/keys", body: `{"permissions": ["admin"]}`, token: testAdminKey, status: http.StatusBadRequest},
+			{method: "GET", target: "/v1/collections/team-a/keys", token: testAdminKey, status: http.StatusOK},
+			{method: "POST", target: "/v1/collections/team-a/quotes", body: `{"author": "Seneca", "quote": "While we wait for life, life passes."}`, token: testAdminKey, status: http.StatusCreated},
+			{method: "GET", target: "/v1/collections/team-a/moderation/queue", token: testReviewerKey, status: http.StatusOK},
+			{method: "GET", target: "/v1/collections/team-a/moderation/queue", token: testAdminKey, status: http.StatusForbidden},
+			{method: "POST", target: "/v1/collections/nope/moderation/quotes/1/approve", token: testReviewerKey, status: http.StatusNotFound},
+			{method: "GET", target: "/v1/collections/team-a/moderation/quotes/1", token: testReviewerKey, status: http.StatusOK},
+			{method: "POST", target: "/v1/collections/team-a/moderation/quotes/1/approve", token: testReviewerKey, status: http.StatusOK},
+			{method: "POST", target: "/v1/collections/team-a/quotes/1/submit", status: http.StatusUnauthorized},
+			{method: "POST", target: "/v1/collections/team-a/quotes/1/submit", token: testAdminKey, status: http.StatusConflict},
+			{method: "GET", target: "/v1/collections/team-a/quotes", token: testAdminKey, status: http.StatusOK},
+			{method: "GET", target: "/v1/collections/team-a/quotes?author=seneca", token: testAdminKey, status: http.StatusOK},
+			{method: "GET", target: "/v1/collections/team-a/quotes/random", status: http.StatusUnauthorized},
+			{method: "GET", target: "/v1/collections/team-a/quotes/1", token: testAdminKey, status: http.StatusOK},
+			{method: "GET", target: "/v1/collections/nope/quotes/1", token: testAdminKey, status: http.StatusNotFound},
+			{method: "DELETE", target: "/v1/collections/team-a/quotes/1", token: testAdminKey, status: http.StatusNoContent},
+			{method: "DELETE", target: "/v1/collections/team-a/keys/1", token: testAdminKey, status: http.StatusNoContent},
+			{method: "DELETE", target: "/v1/collections/team-a", token: testAdminKey, status: http.StatusNoContent},
+			{method: "POST", target: "/graphql", body: `{"query": "{ quotes { nodes { id author { name } } } }"}`, status: http.StatusOK},
+			{method: "POST", target: "/graphql", body: `{"query": "{ nope }"}`, status: http.StatusBadRequest},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.method+" "+tc.target, func(t *testing.T) {
+				req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+				if tc.token != "" {
+					req.Header.Set("Authorization", "Bearer "+tc.token)
+				}
+				if tc.user != "" {
+					req.Header.Set(handler.HeaderUserID, tc.user)
+				}
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+
+				if rec.Code != tc.status {
+					t.Fatalf("expected status %d, got %d: %s", tc.status, rec.Code, rec.Body.String())
+				}
+
+				var match mux.RouteMatch
+				if !router.Match(req, &match) {
+					t.Fatalf("no route for %s %s", tc.method, tc.target)
+				}
+				tpl, _ := match.Route.GetPathTemplate()
+				path := pathVarPattern.ReplaceAllString(tpl, "{$1}")
+
+				op := paths[path].(map[string]interface{})[strings.ToLower(tc.method)].(map[string]interface{})
+				responses := op["responses"].(map[string]interface{})
+				response, ok := responses[strconv.Itoa(rec.Code)]
+				if !ok {
+					response, ok = responses["default"]
+				}
+				if !ok {
+					t.Fatalf("status %d is not declared", rec.Code)
+				}
+				response = spec.resolve(response)
+
+				content, _ := response.(map[string]interface{})["content"].(map[string]interface{})
+				if len(content) == 0 {
+					if rec.Body.Len() != 0 {
+						t.Errorf("expected empty body, got %s", rec.Body.String())
+					}
+					return
+				}
+
+				mediaType, _, _ := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+				media, ok := content[mediaType].(map[string]interface{})
+				if !ok {
+					t.Fatalf("content type %q is not declared for status %d", mediaType, rec.Code)
+				}
+
+				var body interface{} = rec.Body.String()
+				if strings.HasSuffix(mediaType, "json") {
+					if err := json.NewDecoder(rec.Body).Decode(&body); err != nil && err != io.EOF {
+						t.Fatalf("invalid JSON body: %v", err)
+					}
+				}
+
+				if err := spec.validate(media["schema"], body, "body"); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	})
+}
+
+func TestLegacyRoutes(t *testing.T) {
+	router := newTestRouter()
+
+	t.Run("Unversioned paths are deprecated", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", "/quotes", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if rec.Header().Get("Deprecation") == "" || rec.Header().Get("Sunset") == "" {
+			t.Errorf("expected Deprecation and Sunset headers, got %v", rec.Header())
+		}
+		if link := rec.Header().Get("Link"); link != `</v1/quotes>; rel="successor-version"` {
+			t.Errorf("unexpected Link header: %s", link)
+		}
+	})
+
+	t.Run("Versioned paths are not deprecated", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/quotes", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if rec.Header().Get("Deprecation") != "" {
+			t.Errorf("unexpected Deprecation header on /v1")
+		}
+	})
+}
+
+func TestEventRoutes(t *testing.T) {
+	srv := httptest.NewServer(newTestRouter())
+	defer srv.Close()
+
+	t.Run("WebSocket upgrade passes through middleware", func(t *testing.T) {
+		url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/v1/quotes/events/ws"
+		conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		defer conn.Close()
+
+		if resp.Header.Get(middleware.HeaderRequestID) == "" {
+			t.Errorf("expected request ID on handshake response, got %v", resp.Header)
+		}
+	})
+
+	t.Run("SSE stream is flushed through middleware", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/v1/quotes/events")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		line, err := bufio.NewReader(resp.Body).ReadString('\n')
+		if err != nil || !strings.HasPrefix(line, "retry:") {
+			t.Errorf("expected retry preamble, got %q, %v", line, err)
+		}
+	})
+}
+
+func (s openAPISpec) resolve(node interface{}) interface{} {
+	obj, ok := node.(map[string]interface{})
+	if !ok {
+		return node
+	}
+	ref, ok := obj["$ref"].(string)
+	if !ok {
+		return node
+	}
+
+	var cur interface{} = map[string]interface{}(s)
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		cur = cur.(map[string]interface{})[part]
+	}
+	return s.resolve(cur)
+}
+
+// validate checks value against the subset of JSON Schema used by the document.
+func (s openAPISpec) validate(schemaNode, value interface{}, at string) error {
+	schema, _ := s.resolve(schemaNode).(map[string]interface{})
+	if schema == nil {
+		return nil
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if e == value {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", at, value, enum)
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %T", at, value)
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, ok := obj[name.(string)]; !ok {
+					return fmt.Errorf("%s: missing required property %q", at, name)
+				}
+			}
+		}
+		for name, v := range obj {
+			prop, ok := props[name]
+			if !ok {
+				if schema["additionalProperties"] == false {
+					return fmt.Errorf("%s: unexpected property %q", at, name)
+				}
+				continue
+			}
+			if err := s.validate(prop, v, at+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %T", at, value)
+		}
+		for i, item := range arr {
+			if err := s.validate(schema["items"], item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: expected string, got %T", at, value)
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return fmt.Errorf("%s: expected integer, got %v", at, value)
+		}
+		if minimum, ok := schema["minimum"].(float64); ok && n < minimum {
+			return fmt.Errorf("%s: %v is less than minimum %v", at, n, minimum)
+		}
+	}
+
+	return nil
+}
+
+func TestContentNegotiation(t *testing.T) {
+	router := newTestRouter()
+
+	create := httptest.NewRequest("POST", "/v1/quotes", strings.NewReader(`{"author": "Seneca", "quote": "We learn."}`))
+	router.ServeHTTP(httptest.NewRecorder(), create)
+	approve := httptest.NewRequest("POST", "/v1/moderation/quotes/1/approve", nil)
+	approve.Header.Set("Authorization", "Bearer "+testReviewerKey)
+	router.ServeHTTP(httptest.NewRecorder(), approve)
+
+	tt := []struct {
+		target      string
+		accept      string
+		contentType string
+	}{
+		{target: "/v1/quotes", accept: "text/html,application/xhtml+xml,*/*;q=0.8", contentType: handler.ContentTypeHTML},
+		{target: "/v1/quotes?author=seneca", accept: "text/html", contentType: handler.ContentTypeHTML},
+		{target: "/quotes/1", accept: "text/html", contentType: handler.ContentTypeHTML},
+		{target: "/v1/quotes/random", accept: "text/html", contentType: handler.ContentTypeHTML},
+		{target: "/v1/quotes", accept: "*/*", contentType: "application/json"},
+		{target: "/v1/quotes/1", accept: "application/json, text/html", contentType: "application/json"},
+		{target: "/v1/quotes/1", contentType: "application/json"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.target+" "+tc.accept, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.target, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != tc.contentType {
+				t.Errorf("expected content type %s, got %s", tc.contentType, ct)
+			}
+			if rec.Header().Get("Vary") != "Accept" {
+				t.Errorf("expected Vary: Accept, got %q", rec.Header().Get("Vary"))
+			}
+		})
+	}
+}
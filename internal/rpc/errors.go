@@ -0,0 +1,62 @@
+package rpc
+
+import (
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+)
+
+var grpcCodes = map[service.Code]codes.Code{
+	service.CodeValidation:   codes.InvalidArgument,
+	service.CodeNotFound:     codes.NotFound,
+	service.CodeConflict:     codes.AlreadyExists,
+	service.CodeUnauthorized: codes.Unauthenticated,
+	service.CodeForbidden:    codes.PermissionDenied,
+	service.CodeTimeout:      codes.DeadlineExceeded,
+	service.CodeCanceled:     codes.Canceled,
+	service.CodeUnavailable:  codes.Unavailable,
+	service.CodeInternal:     codes.Internal,
+}
+
+// toStatus maps service errors to gRPC statuses, validation errors carry a
+// BadRequest detail with every failing field.
+func toStatus(err error) error {
+	var svcErr *service.Error
+	if !errors.As(err, &svcErr) {
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	code, ok := grpcCodes[svcErr.Code]
+	if !ok || code == codes.Internal {
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	msg := string(svcErr.Code)
+	if svcErr.Detail != "" {
+		msg = svcErr.Detail
+	}
+	st := status.New(code, msg)
+
+	if len(svcErr.Fields) == 0 {
+		return st.Err()
+	}
+
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(svcErr.Fields))
+	for _, f := range svcErr.Fields {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       f.Field,
+			Description: f.Message,
+			Reason:      f.Code,
+		})
+	}
+
+	withDetails, detailErr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
@@ -0,0 +1,81 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/rest/middleware"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const metadataRequestID = "x-request-id"
+
+func unaryLogging(log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		ctx, reqLog := withRequestLogger(ctx, log, info.FullMethod)
+
+		resp, err := handler(ctx, req)
+
+		logCall(reqLog, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func streamLogging(log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, reqLog := withRequestLogger(ss.Context(), log, info.FullMethod)
+
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+
+		logCall(reqLog, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// withRequestLogger mirrors middleware.RequestID for gRPC: the request ID is
+// taken from metadata or generated, and sent back in the response header.
+func withRequestLogger(ctx context.Context, log *logger.Logger, method string) (context.Context, *logger.Logger) {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(metadataRequestID); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	if !middleware.ValidRequestID(id) {
+		id = middleware.NewRequestID()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, id))
+
+	reqLog := log.With().Str("request_id", id).Logger()
+	return logger.WithContext(ctx, reqLog), reqLog
+}
+
+func logCall(log *logger.Logger, method string, start time.Time, err error) {
+	st := status.Convert(err)
+
+	event := log.Info()
+	if err != nil {
+		event = log.Warn().Err(err)
+	}
+	event.
+		Str("method", method).
+		Str("code", st.Code().String()).
+		Dur("duration", time.Since(start)).
+		Msg("rpc completed")
+}
+
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,226 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	quotebookv1 "github.com/zonder12120/brandscout-quotebook/api/quotebook/v1"
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
+// Subscriber is the source of quote change events, implemented by events.Bus.
+type Subscriber interface {
+	Subscribe(buffer int) (<-chan events.Event, func())
+}
+
+type QuoteServer struct {
+	quotebookv1.UnimplementedQuoteServiceServer
+
+	service service.Quote
+	events  Subscriber
+}
+
+// NewServer returns a gRPC server with the quote service and the logging
+// interceptors registered.
+func NewServer(svc service.Quote, sub Subscriber, log *logger.Logger) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryLogging(log)),
+		grpc.ChainStreamInterceptor(streamLogging(log)),
+	)
+	quotebookv1.RegisterQuoteServiceServer(srv, &QuoteServer{service: svc, events: sub})
+	return srv
+}
+
+func (s *QuoteServer) CreateQuote(ctx context.Context, req *quotebookv1.CreateQuoteRequest) (*quotebookv1.Quote, error) {
+	created, err := s.service.Create(ctx, &model.Quote{
+		Author:    req.GetAuthor(),
+		Quote:     req.GetQuote(),
+		Tags:      req.GetTags(),
+		Lang:      req.GetLang(),
+		PublishAt: fromTimestamp(req.GetPublishAt()),
+		ExpireAt:  fromTimestamp(req.GetExpireAt()),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(created), nil
+}
+
+func (s *QuoteServer) GetQuote(ctx context.Context, req *quotebookv1.GetQuoteRequest) (*quotebookv1.Quote, error) {
+	q, err := s.service.Get(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(q), nil
+}
+
+func (s *QuoteServer) ListQuotes(ctx context.Context, req *quotebookv1.ListQuotesRequest) (*quotebookv1.ListQuotesResponse, error) {
+	afterID, err := service.DecodeCursor(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+	}
+
+	size := int(req.GetPageSize())
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	if size > maxPageSize {
+		size = maxPageSize
+	}
+
+	// One extra quote tells whether another page exists.
+	quotes, err := s.service.ListPage(ctx, afterID, size+1)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &quotebookv1.ListQuotesResponse{}
+	if len(quotes) > size {
+		quotes = quotes[:size]
+		resp.NextPageToken = service.EncodeCursor(quotes[size-1].ID)
+	}
+	resp.Quotes = toProtoList(quotes)
+
+	return resp, nil
+}
+
+func (s *QuoteServer) GetRandomQuote(ctx context.Context, req *quotebookv1.GetRandomQuoteRequest) (*quotebookv1.Quote, error) {
+	if req.GetLang() != "" {
+		lang, ok := service.ParseLang(req.GetLang())
+		if !ok {
+			return nil, status.Error(codes.InvalidArgument, "lang must be an ISO 639-1 language code")
+		}
+		q, err := s.service.FindRandom(ctx, storage.QuoteFilter{Lang: lang})
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		return toProto(q), nil
+	}
+
+	q, err := s.service.GetRandom(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProto(q), nil
+}
+
+func (s *QuoteServer) ListQuotesByAuthor(ctx context.Context, req *quotebookv1.ListQuotesByAuthorRequest) (*quotebookv1.ListQuotesResponse, error) {
+	if strings.TrimSpace(req.GetAuthor()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "author is required")
+	}
+
+	quotes, err := s.service.GetByAuthor(ctx, req.GetAuthor())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &quotebookv1.ListQuotesResponse{Quotes: toProtoList(quotes)}, nil
+}
+
+func (s *QuoteServer) DeleteQuote(ctx context.Context, req *quotebookv1.DeleteQuoteRequest) (*quotebookv1.DeleteQuoteResponse, error) {
+	if err := s.service.Delete(ctx, int(req.GetId())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &quotebookv1.DeleteQuoteResponse{}, nil
+}
+
+func (s *QuoteServer) WatchQuotes(req *quotebookv1.WatchQuotesRequest, stream grpc.ServerStreamingServer[quotebookv1.QuoteEvent]) error {
+	ch, cancel := s.events.Subscribe(events.DefaultSubscriberBuffer)
+	defer cancel()
+
+	// Headers tell the client the subscription is active.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	author := strings.TrimSpace(req.GetAuthor())
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e, ok := <-ch:
+			if !ok {
+				return status.Error(codes.Unavailable, "event stream closed")
+			}
+			// Quotes of collections are not served over gRPC.
+			if e.Collection != "" || author != "" && !strings.EqualFold(e.Quote.Author, author) {
+				continue
+			}
+			if err := stream.Send(toProtoEvent(e)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toProto(q *model.Quote) *quotebookv1.Quote {
+	return &quotebookv1.Quote{
+		Id:        int64(q.ID),
+		Author:    q.Author,
+		Quote:     q.Quote,
+		Tags:      q.Tags,
+		Lang:      q.Lang,
+		PublishAt: toTimestamp(q.PublishAt),
+		ExpireAt:  toTimestamp(q.ExpireAt),
+	}
+}
+
+// toTimestamp leaves zero times unset, fromTimestamp reads unset ones as
+// zero rather than the Unix epoch.
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func toProtoList(quotes []*model.Quote) []*quotebookv1.Quote {
+	out := make([]*quotebookv1.Quote, 0, len(quotes))
+	for _, q := range quotes {
+		out = append(out, toProto(q))
+	}
+	return out
+}
+
+var eventTypes = map[events.Type]quotebookv1.QuoteEvent_Type{
+	events.QuoteCreated:   quotebookv1.QuoteEvent_TYPE_CREATED,
+	events.QuoteUpdated:   quotebookv1.QuoteEvent_TYPE_UPDATED,
+	events.QuoteDeleted:   quotebookv1.QuoteEvent_TYPE_DELETED,
+	events.QuoteEvicted:   quotebookv1.QuoteEvent_TYPE_EVICTED,
+	events.QuotePublished: quotebookv1.QuoteEvent_TYPE_PUBLISHED,
+	events.QuoteExpired:   quotebookv1.QuoteEvent_TYPE_EXPIRED,
+}
+
+func toProtoEvent(e events.Event) *quotebookv1.QuoteEvent {
+	quote := e.Quote
+	return &quotebookv1.QuoteEvent{
+		Id:      e.ID,
+		Type:    eventTypes[e.Type],
+		QuoteId: int64(e.QuoteID),
+		Quote:   toProto(&quote),
+		Time:    timestamppb.New(e.Time),
+	}
+}
@@ -0,0 +1,237 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	quotebookv1 "github.com/zonder12120/brandscout-quotebook/api/quotebook/v1"
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+func newTestClient(t *testing.T) quotebookv1.QuoteServiceClient {
+	t.Helper()
+
+	bus := events.NewBus()
+	svc := service.NewQuoteService(storage.NewInMemory(100), service.WithPublisher(bus))
+	srv := NewServer(svc, bus, logger.New("error", "console"))
+
+	lis := bufconn.Listen(1 << 20)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+		bus.Close()
+		srv.Stop()
+	})
+
+	return quotebookv1.NewQuoteServiceClient(conn)
+}
+
+func TestQuoteServer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("CRUD", func(t *testing.T) {
+		client := newTestClient(t)
+
+		_, err := client.GetRandomQuote(ctx, &quotebookv1.GetRandomQuoteRequest{})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("expected NotFound, got %v", err)
+		}
+
+		var header metadata.MD
+		created, err := client.CreateQuote(ctx, &quotebookv1.CreateQuoteRequest{Author: "Confucius", Quote: "Life is simple."}, grpc.Header(&header))
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if created.GetId() != 1 {
+			t.Errorf("expected ID 1, got %d", created.GetId())
+		}
+		if len(header.Get(metadataRequestID)) != 1 {
+			t.Errorf("expected request ID header, got %v", header)
+		}
+
+		got, err := client.GetQuote(ctx, &quotebookv1.GetQuoteRequest{Id: created.GetId()})
+		if err != nil || got.GetAuthor() != "Confucius" {
+			t.Errorf("unexpected quote %v, %v", got, err)
+		}
+
+		byAuthor, err := client.ListQuotesByAuthor(ctx, &quotebookv1.ListQuotesByAuthorRequest{Author: "confucius"})
+		if err != nil || len(byAuthor.GetQuotes()) != 1 {
+			t.Errorf("unexpected quotes by author %v, %v", byAuthor, err)
+		}
+
+		if _, err := client.DeleteQuote(ctx, &quotebookv1.DeleteQuoteRequest{Id: created.GetId()}); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		_, err = client.DeleteQuote(ctx, &quotebookv1.DeleteQuoteRequest{Id: created.GetId()})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("expected NotFound, got %v", err)
+		}
+	})
+
+	t.Run("Scheduled quotes keep their window", func(t *testing.T) {
+		client := newTestClient(t)
+
+		expireAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		created, err := client.CreateQuote(ctx, &quotebookv1.CreateQuoteRequest{Author: "A", Quote: "Q", ExpireAt: timestamppb.New(expireAt)})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if created.GetPublishAt() != nil || !created.GetExpireAt().AsTime().Equal(expireAt) {
+			t.Errorf("unexpected window %v - %v", created.GetPublishAt(), created.GetExpireAt())
+		}
+
+		_, err = client.CreateQuote(ctx, &quotebookv1.CreateQuoteRequest{Author: "A", Quote: "Q", PublishAt: timestamppb.New(expireAt.Add(time.Hour))})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if list, _ := client.ListQuotes(ctx, &quotebookv1.ListQuotesRequest{}); len(list.GetQuotes()) != 1 {
+			t.Errorf("expected the scheduled quote to be hidden, got %v", list.GetQuotes())
+		}
+	})
+
+	t.Run("Validation errors carry field violations", func(t *testing.T) {
+		client := newTestClient(t)
+
+		_, err := client.CreateQuote(ctx, &quotebookv1.CreateQuoteRequest{})
+		st := status.Convert(err)
+		if st.Code() != codes.InvalidArgument {
+			t.Fatalf("expected InvalidArgument, got %v", err)
+		}
+
+		var fields []string
+		for _, d := range st.Details() {
+			if br, ok := d.(*errdetails.BadRequest); ok {
+				for _, v := range br.GetFieldViolations() {
+					fields = append(fields, v.GetField())
+				}
+			}
+		}
+		if len(fields) != 2 {
+			t.Errorf("expected 2 field violations, got %v", fields)
+		}
+	})
+
+	t.Run("GetRandomQuote filters by language", func(t *testing.T) {
+		client := newTestClient(t)
+
+		created, err := client.CreateQuote(ctx, &quotebookv1.CreateQuoteRequest{Author: "Bacon", Quote: "Knowledge is power"})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if created.GetLang() != "en" {
+			t.Errorf("expected detected lang en, got %q", created.GetLang())
+		}
+
+		got, err := client.GetRandomQuote(ctx, &quotebookv1.GetRandomQuoteRequest{Lang: "EN"})
+		if err != nil || got.GetId() != created.GetId() {
+			t.Errorf("unexpected quote %v, %v", got, err)
+		}
+
+		_, err = client.GetRandomQuote(ctx, &quotebookv1.GetRandomQuoteRequest{Lang: "ru"})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("expected NotFound, got %v", err)
+		}
+
+		_, err = client.GetRandomQuote(ctx, &quotebookv1.GetRandomQuoteRequest{Lang: "english"})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expected InvalidArgument, got %v", err)
+		}
+	})
+
+	t.Run("ListQuotes pages through all quotes", func(t *testing.T) {
+		client := newTestClient(t)
+		for i := 0; i < 5; i++ {
+			_, err := client.CreateQuote(ctx, &quotebookv1.CreateQuoteRequest{Author: "A", Quote: "Q" + strconv.Itoa(i)})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var ids []int64
+		token := ""
+		for pages := 0; ; pages++ {
+			if pages > 5 {
+				t.Fatal("too many pages")
+			}
+			resp, err := client.ListQuotes(ctx, &quotebookv1.ListQuotesRequest{PageSize: 2, PageToken: token})
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			for _, q := range resp.GetQuotes() {
+				ids = append(ids, q.GetId())
+			}
+			token = resp.GetNextPageToken()
+			if token == "" {
+				break
+			}
+		}
+
+		if len(ids) != 5 || ids[0] != 1 || ids[4] != 5 {
+			t.Errorf("unexpected ids %v", ids)
+		}
+
+		_, err := client.ListQuotes(ctx, &quotebookv1.ListQuotesRequest{PageToken: "???"})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expected InvalidArgument for bad token, got %v", err)
+		}
+	})
+
+	t.Run("WatchQuotes streams filtered events", func(t *testing.T) {
+		client := newTestClient(t)
+
+		watchCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		stream, err := client.WatchQuotes(watchCtx, &quotebookv1.WatchQuotesRequest{Author: "seneca"})
+		if err != nil {
+			t.Fatalf("watch: %v", err)
+		}
+		// Headers arrive once the server handler is running and subscribed.
+		if _, err := stream.Header(); err != nil {
+			t.Fatalf("header: %v", err)
+		}
+
+		_, _ = client.CreateQuote(ctx, &quotebookv1.CreateQuoteRequest{Author: "Confucius", Quote: "Skip me"})
+		created, _ := client.CreateQuote(ctx, &quotebookv1.CreateQuoteRequest{Author: "Seneca", Quote: "Keep me"})
+		_, _ = client.DeleteQuote(ctx, &quotebookv1.DeleteQuoteRequest{Id: created.GetId()})
+
+		want := []quotebookv1.QuoteEvent_Type{quotebookv1.QuoteEvent_TYPE_CREATED, quotebookv1.QuoteEvent_TYPE_DELETED}
+		for _, typ := range want {
+			e, err := stream.Recv()
+			if err != nil {
+				t.Fatalf("recv: %v", err)
+			}
+			if e.GetType() != typ || e.GetQuoteId() != created.GetId() || e.GetQuote().GetAuthor() != "Seneca" {
+				t.Errorf("unexpected event %v", e)
+			}
+		}
+	})
+}
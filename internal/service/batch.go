@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const (
+	DefaultMaxBatchSize = 100
+
+	maxTempIDLength = 64
+)
+
+type BatchAction string
+
+const (
+	BatchCreate BatchAction = "create"
+	BatchUpdate BatchAction = "update"
+	BatchDelete BatchAction = "delete"
+)
+
+// BatchOp is one operation of a batch. A create may name the new quote
+// with TempID, update and delete address a quote either by ID or by the
+// TempID of an earlier create.
+type BatchOp struct {
+	Action BatchAction
+	ID     int
+	TempID string
+	Quote  *model.Quote
+}
+
+// BatchResult is the outcome of one operation: the created, updated or
+// deleted quote, or in a non-atomic batch the error it failed with.
+type BatchResult struct {
+	Quote *model.Quote
+	Err   error
+}
+
+// WithMaxBatchSize overrides DefaultMaxBatchSize, zero disables the check.
+func WithMaxBatchSize(n int) Option {
+	return func(s *QuoteService) {
+		s.maxBatch = n
+	}
+}
+
+// Batch applies ops in order under a single storage lock. An atomic batch
+// stops at the first failing operation and undoes the ones before it, the
+// error names the operation and its fields are prefixed with
+// operations[i]. A non-atomic batch tries every operation and reports
+// failures in the results. Events and the removal hook follow only the
+// stored changes.
+func (s *QuoteService) Batch(ctx context.Context, ops []BatchOp, atomic bool) ([]BatchResult, error) {
+	switch {
+	case len(ops) == 0:
+		return nil, NewValidationError(FieldError{Field: "operations", Code: fieldCodeRequired, Message: "must contain at least one operation"})
+	case s.maxBatch > 0 && len(ops) > s.maxBatch:
+		return nil, NewValidationError(FieldError{
+			Field:   "operations",
+			Code:    fieldCodeTooMany,
+			Message: fmt.Sprintf("must contain at most %d operations", s.maxBatch),
+		})
+	}
+
+	results := make([]BatchResult, len(ops))
+	if err := s.prepareBatch(ops, results, atomic); err != nil {
+		return nil, err
+	}
+
+	var (
+		changes []batchChange
+		removed []int
+	)
+	err := s.store.Batch(ctx, func(tx storage.QuoteTx) error {
+		tempIDs := make(map[string]int)
+		for i, op := range ops {
+			if results[i].Err != nil {
+				continue
+			}
+
+			q, change, err := s.applyBatchOp(tx, op, tempIDs)
+			if err != nil {
+				if atomic {
+					return batchError(i, err)
+				}
+				results[i].Err = err
+				continue
+			}
+
+			results[i].Quote = q
+			changes = append(changes, change...)
+			// A quote withdrawn for review is announced as deleted but stays.
+			for _, c := range change {
+				if c.typ == events.QuoteEvicted || c.typ == events.QuoteDeleted && op.Action == BatchDelete {
+					removed = append(removed, c.quote.ID)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	logger.FromContext(ctx, nil).Debug().Int("operations", len(ops)).Int("changes", len(changes)).Msg("quote batch applied")
+	s.removed(ctx, removed...)
+	for _, c := range changes {
+		s.publish(c.typ, c.quote)
+	}
+	return results, nil
+}
+
+// batchChange is a stored change announced once the batch is done.
+type batchChange struct {
+	typ   events.Type
+	quote *model.Quote
+}
+
+// prepareBatch checks every operation before the storage is locked. In an
+// atomic batch all failures are returned together, otherwise they are set
+// on the results.
+func (s *QuoteService) prepareBatch(ops []BatchOp, results []BatchResult, atomic bool) error {
+	var fields []FieldError
+	declared := make(map[string]bool)
+	for i, op := range ops {
+		err := s.prepareBatchOp(op, declared)
+		if err == nil {
+			continue
+		}
+		if !atomic {
+			results[i].Err = err
+			continue
+		}
+
+		var svcErr *Error
+		if !errors.As(err, &svcErr) || len(svcErr.Fields) == 0 {
+			return batchError(i, err)
+		}
+		fields = append(fields, prefixFields(fmt.Sprintf("operations[%d].", i), svcErr.Fields)...)
+	}
+
+	if len(fields) > 0 {
+		return NewValidationError(fields...)
+	}
+	return nil
+}
+
+// prepareBatchOp validates op the way Create and Update validate their
+// input, declared collects the temporary IDs of the creates so far.
+func (s *QuoteService) prepareBatchOp(op BatchOp, declared map[string]bool) error {
+	var fields []FieldError
+
+	switch op.Action {
+	case BatchCreate:
+		if op.ID != 0 {
+			fields = append(fields, FieldError{Field: "id", Code: fieldCodeInvalid, Message: "must be omitted for create"})
+		}
+		if op.TempID != "" {
+			switch {
+			case len(op.TempID) > maxTempIDLength:
+				fields = append(fields, FieldError{
+					Field:   "temp_id",
+					Code:    fieldCodeTooLong,
+					Message: fmt.Sprintf("must be at most %d characters", maxTempIDLength),
+				})
+			case declared[op.TempID]:
+				fields = append(fields, FieldError{Field: "temp_id", Code: fieldCodeInvalid, Message: "must not repeat"})
+			}
+			declared[op.TempID] = true
+		}
+	case BatchUpdate, BatchDelete:
+		switch {
+		case (op.ID == 0) == (op.TempID == ""):
+			fields = append(fields, FieldError{Field: "id", Code: fieldCodeRequired, Message: "exactly one of id and temp_id must be given"})
+		case op.TempID != "" && !declared[op.TempID]:
+			fields = append(fields, FieldError{Field: "temp_id", Code: fieldCodeInvalid, Message: "must name a quote created earlier in the batch"})
+		case op.TempID == "" && op.ID < 0:
+			fields = append(fields, FieldError{Field: "id", Code: fieldCodeInvalid, Message: "must be a positive integer"})
+		}
+	default:
+		fields = append(fields, FieldError{Field: "op", Code: fieldCodeInvalid, Message: "must be one of create, update, delete"})
+	}
+
+	switch {
+	case op.Action == BatchDelete && op.Quote != nil:
+		fields = append(fields, FieldError{Field: "quote", Code: fieldCodeInvalid, Message: "must be omitted for delete"})
+	case op.Action == BatchCreate || op.Action == BatchUpdate:
+		if op.Quote == nil {
+			fields = append(fields, FieldError{Field: "quote", Code: fieldCodeRequired, Message: "must be given"})
+			break
+		}
+
+		prepare := s.prepareCreate
+		if op.Action == BatchUpdate {
+			prepare = s.prepareUpdate
+		}
+		if err := prepare(op.Quote); err != nil {
+			var svcErr *Error
+			if !errors.As(err, &svcErr) {
+				return err
+			}
+			fields = append(fields, prefixFields("quote.", svcErr.Fields)...)
+		}
+	}
+
+	if len(fields) > 0 {
+		return NewValidationError(fields...)
+	}
+	return nil
+}
+
+// applyBatchOp stores a prepared operation and returns its quote with the
+// changes to announce.
+func (s *QuoteService) applyBatchOp(tx storage.QuoteTx, op BatchOp, tempIDs map[string]int) (*model.Quote, []batchChange, error) {
+	if op.Action == BatchCreate {
+		created, evicted := tx.CreateQuote(op.Quote)
+		if op.TempID != "" {
+			tempIDs[op.TempID] = created.ID
+		}
+
+		changes := []batchChange{{typ: events.QuoteCreated, quote: created}}
+		if evicted != nil {
+			changes = append([]batchChange{{typ: events.QuoteEvicted, quote: evicted}}, changes...)
+		}
+		return created, changes, nil
+	}
+
+	id := op.ID
+	if op.TempID != "" {
+		var ok bool
+		if id, ok = tempIDs[op.TempID]; !ok {
+			return nil, nil, NewNotFoundError(fmt.Sprintf("quote %q was not created", op.TempID), nil)
+		}
+	}
+
+	if op.Action == BatchDelete {
+		deleted, err := tx.DeleteByID(id)
+		if err != nil {
+			return nil, nil, wrapError(err)
+		}
+		return deleted, []batchChange{{typ: events.QuoteDeleted, quote: deleted}}, nil
+	}
+
+	edit := s.edit(op.Quote)
+	updated, err := tx.ModifyQuote(id, edit.apply)
+	if err != nil {
+		return nil, nil, wrapError(err)
+	}
+	return updated, edit.changes(updated), nil
+}
+
+// batchError names the failed operation in err, keeping its code.
+func batchError(i int, err error) error {
+	err = wrapError(err)
+
+	var svcErr *Error
+	if !errors.As(err, &svcErr) {
+		return err
+	}
+
+	detail := svcErr.Detail
+	if detail == "" {
+		detail = "operation failed"
+	}
+	return &Error{
+		Code:   svcErr.Code,
+		Detail: fmt.Sprintf("operations[%d]: %s", i, detail),
+		Fields: prefixFields(fmt.Sprintf("operations[%d].", i), svcErr.Fields),
+		Err:    svcErr.Err,
+	}
+}
+
+func prefixFields(prefix string, fields []FieldError) []FieldError {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	prefixed := make([]FieldError, len(fields))
+	for i, f := range fields {
+		f.Field = prefix + f.Field
+		prefixed[i] = f
+	}
+	return prefixed
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const (
+	maxAPIKeyNameLength = 100
+
+	apiKeyPrefix = "qbk_"
+)
+
+var collectionNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+type Collection interface {
+	Create(ctx context.Context, c *model.Collection) (*model.Collection, error)
+	List(ctx context.Context) ([]*model.Collection, error)
+	Delete(ctx context.Context, name string) error
+	Quotes(ctx context.Context, name string) (Quote, error)
+	Moderation(ctx context.Context, name string) (Moderation, error)
+
+	CreateKey(ctx context.Context, k *model.APIKey) (*model.APIKey, error)
+	ListKeys(ctx context.Context, collection string) ([]*model.APIKey, error)
+	DeleteKey(ctx context.Context, collection string, id int) error
+
+	Authorize(ctx context.Context, key, collection, permission string) error
+	AuthorizeAdmin(key string) error
+}
+
+// CollectionService manages tenant collections and their API keys. Quotes
+// of a collection go through a QuoteService of their own built with the
+// same options as the default one, its events carry the collection name.
+type CollectionService struct {
+	store        storage.CollectionStorage
+	adminKey     string
+	defaultLimit int
+	quoteOpts    []Option
+}
+
+type CollectionOption func(*CollectionService)
+
+// WithAdminKey sets the key allowed to manage collections and to access
+// every collection. Without it only collection keys are accepted.
+func WithAdminKey(key string) CollectionOption {
+	return func(s *CollectionService) {
+		s.adminKey = key
+	}
+}
+
+// WithDefaultQuotesLimit is the limit of collections created without one.
+func WithDefaultQuotesLimit(n int) CollectionOption {
+	return func(s *CollectionService) {
+		s.defaultLimit = n
+	}
+}
+
+// WithQuoteOptions configures the quote services of collections.
+func WithQuoteOptions(opts ...Option) CollectionOption {
+	return func(s *CollectionService) {
+		s.quoteOpts = opts
+	}
+}
+
+func NewCollectionService(store storage.CollectionStorage, opts ...CollectionOption) *CollectionService {
+	s := &CollectionService{
+		store:        store,
+		defaultLimit: 1000,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *CollectionService) Create(ctx context.Context, c *model.Collection) (*model.Collection, error) {
+	if c.QuotesLimit == 0 {
+		c.QuotesLimit = s.defaultLimit
+	}
+	if err := validateCollection(c); err != nil {
+		return nil, err
+	}
+	c.CreatedAt = time.Now().UTC()
+
+	created, err := s.store.CreateCollection(ctx, c)
+	if err != nil {
+		return nil, wrapCollectionError(err)
+	}
+
+	logger.FromContext(ctx, nil).Debug().Str("collection", created.Name).Msg("collection created")
+	return created, nil
+}
+
+func (s *CollectionService) List(ctx context.Context) ([]*model.Collection, error) {
+	collections, err := s.store.GetCollections(ctx)
+	return collections, wrapError(err)
+}
+
+// Delete drops the collection with all its quotes and API keys.
+func (s *CollectionService) Delete(ctx context.Context, name string) error {
+	if err := s.store.DeleteCollection(ctx, name); err != nil {
+		return wrapCollectionError(err)
+	}
+
+	logger.FromContext(ctx, nil).Debug().Str("collection", name).Msg("collection deleted")
+	return nil
+}
+
+// Quotes returns the quote service scoped to a collection.
+func (s *CollectionService) Quotes(ctx context.Context, name string) (Quote, error) {
+	quotes, err := s.quoteService(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return quotes, nil
+}
+
+// Moderation returns the review workflow scoped to a collection.
+func (s *CollectionService) Moderation(ctx context.Context, name string) (Moderation, error) {
+	quotes, err := s.quoteService(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return quotes, nil
+}
+
+func (s *CollectionService) quoteService(ctx context.Context, name string) (*QuoteService, error) {
+	_, store, err := s.store.GetCollection(ctx, name)
+	if err != nil {
+		return nil, wrapCollectionError(err)
+	}
+	return s.scoped(name, store), nil
+}
+
+// quoteServices returns the quote services of all collections, for the
+// Scheduler to run over.
+func (s *CollectionService) quoteServices(ctx context.Context) ([]*QuoteService, error) {
+	collections, err := s.store.GetCollections(ctx)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	services := make([]*QuoteService, 0, len(collections))
+	for _, c := range collections {
+		_, store, err := s.store.GetCollection(ctx, c.Name)
+		if errors.Is(err, storage.ErrNotFound) {
+			// Deleted since it was listed.
+			continue
+		}
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		services = append(services, s.scoped(c.Name, store))
+	}
+	return services, nil
+}
+
+func (s *CollectionService) scoped(name string, store storage.QuoteStorage) *QuoteService {
+	quotes := NewQuoteService(store, s.quoteOpts...)
+	if quotes.publisher != nil {
+		quotes.publisher = collectionPublisher{name: name, next: quotes.publisher}
+	}
+	return quotes
+}
+
+// collectionPublisher stamps the events of a collection with its name.
+type collectionPublisher struct {
+	name string
+	next Publisher
+}
+
+func (p collectionPublisher) Publish(e events.Event) {
+	e.Collection = p.name
+	p.next.Publish(e)
+}
+
+// CreateKey issues a key for k.Collection. The key itself is returned only
+// here, the storage keeps its hash.
+func (s *CollectionService) CreateKey(ctx context.Context, k *model.APIKey) (*model.APIKey, error) {
+	if err := validateAPIKey(k); err != nil {
+		return nil, err
+	}
+
+	key := newAPIKey()
+	k.Hash = hashAPIKey(key)
+	k.CreatedAt = time.Now().UTC()
+
+	created, err := s.store.CreateAPIKey(ctx, k)
+	if err != nil {
+		return nil, wrapCollectionError(err)
+	}
+
+	logger.FromContext(ctx, nil).Debug().Str("collection", created.Collection).Int("id", created.ID).Msg("api key created")
+
+	result := *created
+	result.Key = key
+	return &result, nil
+}
+
+func (s *CollectionService) ListKeys(ctx context.Context, collection string) ([]*model.APIKey, error) {
+	keys, err := s.store.GetAPIKeys(ctx, collection)
+	if err != nil {
+		return nil, wrapCollectionError(err)
+	}
+	return keys, nil
+}
+
+func (s *CollectionService) DeleteKey(ctx context.Context, collection string, id int) error {
+	if err := s.store.DeleteAPIKey(ctx, collection, id); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return NewNotFoundError("api key not found", err)
+		}
+		return wrapError(err)
+	}
+	return nil
+}
+
+// Authorize checks that key grants permission on collection. A missing or
+// unknown key is unauthorized, a key of another collection or without the
+// permission is forbidden. The admin key is allowed everything.
+func (s *CollectionService) Authorize(ctx context.Context, key, collection, permission string) error {
+	if err := s.AuthorizeAdmin(key); err == nil || key == "" {
+		return err
+	}
+
+	k, err := s.store.GetAPIKeyByHash(ctx, hashAPIKey(key))
+	if errors.Is(err, storage.ErrNotFound) {
+		return &Error{Code: CodeUnauthorized, Detail: "invalid API key"}
+	}
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if k.Collection != collection || !k.Allows(permission) {
+		return &Error{Code: CodeForbidden, Detail: fmt.Sprintf("API key does not grant %s access to collection %q", permission, collection)}
+	}
+	return nil
+}
+
+func (s *CollectionService) AuthorizeAdmin(key string) error {
+	return authorizeKey(key, s.adminKey, "admin API key required")
+}
+
+// authorizeKey accepts key if it is the configured want, an empty want
+// accepts nothing. forbidden is the detail of a wrong key.
+func authorizeKey(key, want, forbidden string) error {
+	switch {
+	case key == "":
+		return &Error{Code: CodeUnauthorized, Detail: "API key required"}
+	case want == "" || subtle.ConstantTimeCompare([]byte(key), []byte(want)) != 1:
+		return &Error{Code: CodeForbidden, Detail: forbidden}
+	default:
+		return nil
+	}
+}
+
+func validateCollection(c *model.Collection) error {
+	var fields []FieldError
+
+	if !collectionNamePattern.MatchString(c.Name) {
+		fields = append(fields, FieldError{
+			Field:   "name",
+			Code:    fieldCodeInvalid,
+			Message: "must be 1 to 63 lowercase letters, digits or dashes, starting with a letter or digit",
+		})
+	}
+	if c.QuotesLimit < 1 {
+		fields = append(fields, FieldError{Field: "quotes_limit", Code: fieldCodeInvalid, Message: "must be positive"})
+	}
+
+	if len(fields) > 0 {
+		return NewValidationError(fields...)
+	}
+	return nil
+}
+
+func validateAPIKey(k *model.APIKey) error {
+	var fields []FieldError
+
+	if utf8.RuneCountInString(k.Name) > maxAPIKeyNameLength {
+		fields = append(fields, FieldError{
+			Field:   "name",
+			Code:    fieldCodeTooLong,
+			Message: fmt.Sprintf("must be at most %d characters", maxAPIKeyNameLength),
+		})
+	}
+
+	if len(k.Permissions) == 0 {
+		fields = append(fields, FieldError{Field: "permissions", Code: fieldCodeRequired, Message: "must be non-empty"})
+	}
+	for i, p := range k.Permissions {
+		if p != model.PermissionRead && p != model.PermissionWrite {
+			fields = append(fields, FieldError{
+				Field:   fmt.Sprintf("permissions[%d]", i),
+				Code:    fieldCodeInvalid,
+				Message: "must be one of read, write",
+			})
+		}
+	}
+
+	if len(fields) > 0 {
+		return NewValidationError(fields...)
+	}
+	return nil
+}
+
+func wrapCollectionError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return NewNotFoundError("collection not found", err)
+	case errors.Is(err, storage.ErrAlreadyExists):
+		return &Error{Code: CodeConflict, Detail: "collection already exists", Err: err}
+	default:
+		return wrapError(err)
+	}
+}
+
+func newAPIKey() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return apiKeyPrefix + hex.EncodeToString(b)
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
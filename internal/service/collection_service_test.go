@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+func TestCollectionService(t *testing.T) {
+	ctx := context.Background()
+	const admin = "admin-secret"
+
+	newService := func(t *testing.T) *CollectionService {
+		t.Helper()
+		svc := NewCollectionService(storage.NewCollectionInMemory(), WithAdminKey(admin), WithDefaultQuotesLimit(3))
+		for _, name := range []string{"alpha", "beta"} {
+			if _, err := svc.Create(ctx, &model.Collection{Name: name}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return svc
+	}
+
+	t.Run("Create validates and rejects duplicates", func(t *testing.T) {
+		svc := newService(t)
+
+		list, _ := svc.List(ctx)
+		if len(list) != 2 || list[0].QuotesLimit != 3 {
+			t.Errorf("expected the default limit, got %+v", list)
+		}
+
+		_, err := svc.Create(ctx, &model.Collection{Name: "alpha"})
+		if !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+
+		_, err = svc.Create(ctx, &model.Collection{Name: "-Bad", QuotesLimit: -1})
+		var svcErr *Error
+		if !errors.As(err, &svcErr) || len(svcErr.Fields) != 2 {
+			t.Fatalf("expected two field errors, got %v", err)
+		}
+	})
+
+	t.Run("Keys are returned once and stored hashed", func(t *testing.T) {
+		svc := newService(t)
+
+		k, err := svc.CreateKey(ctx, &model.APIKey{Collection: "alpha", Permissions: []string{model.PermissionRead}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(k.Key, apiKeyPrefix) || k.Hash != hashAPIKey(k.Key) {
+			t.Errorf("unexpected key %+v", k)
+		}
+
+		keys, _ := svc.ListKeys(ctx, "alpha")
+		if len(keys) != 1 || keys[0].Key != "" {
+			t.Errorf("expected listed keys without the secret, got %+v", keys)
+		}
+
+		_, err = svc.CreateKey(ctx, &model.APIKey{Collection: "alpha"})
+		if !errors.Is(err, ErrValidation) {
+			t.Errorf("expected ErrValidation without permissions, got %v", err)
+		}
+		_, err = svc.CreateKey(ctx, &model.APIKey{Collection: "gamma", Permissions: []string{model.PermissionRead}})
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Authorize checks collection and permission", func(t *testing.T) {
+		svc := newService(t)
+
+		reader, _ := svc.CreateKey(ctx, &model.APIKey{Collection: "alpha", Permissions: []string{model.PermissionRead}})
+
+		tt := []struct {
+			name       string
+			key        string
+			collection string
+			permission string
+			want       error
+		}{
+			{name: "reader reads", key: reader.Key, collection: "alpha", permission: model.PermissionRead},
+			{name: "reader cannot write", key: reader.Key, collection: "alpha", permission: model.PermissionWrite, want: ErrForbidden},
+			{name: "key of another collection", key: reader.Key, collection: "beta", permission: model.PermissionRead, want: ErrForbidden},
+			{name: "unknown key", key: "qbk_nope", collection: "alpha", permission: model.PermissionRead, want: ErrUnauthorized},
+			{name: "missing key", collection: "alpha", permission: model.PermissionRead, want: ErrUnauthorized},
+			{name: "admin writes anywhere", key: admin, collection: "beta", permission: model.PermissionWrite},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.name, func(t *testing.T) {
+				err := svc.Authorize(ctx, tc.key, tc.collection, tc.permission)
+				if tc.want == nil && err != nil || tc.want != nil && !errors.Is(err, tc.want) {
+					t.Errorf("expected %v, got %v", tc.want, err)
+				}
+			})
+		}
+
+		if err := svc.AuthorizeAdmin(reader.Key); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected collection keys to be refused admin access, got %v", err)
+		}
+	})
+
+	t.Run("Quotes of collections are isolated", func(t *testing.T) {
+		svc := newService(t)
+
+		alpha, _ := svc.Quotes(ctx, "alpha")
+		beta, _ := svc.Quotes(ctx, "beta")
+		if _, err := alpha.Create(ctx, &model.Quote{Author: "Seneca", Quote: "Time heals what reason cannot."}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := beta.Get(ctx, 1); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected the quote to be invisible in beta, got %v", err)
+		}
+
+		if err := svc.Delete(ctx, "alpha"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := svc.Quotes(ctx, "alpha"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound after delete, got %v", err)
+		}
+	})
+
+	t.Run("Quotes of collections get the quote options", func(t *testing.T) {
+		bus := events.NewBus()
+		ch, cancel := bus.Subscribe(10)
+		defer cancel()
+
+		svc := NewCollectionService(storage.NewCollectionInMemory(),
+			WithQuoteOptions(WithPublisher(bus), WithModeration("reviewer")),
+		)
+		if _, err := svc.Create(ctx, &model.Collection{Name: "alpha"}); err != nil {
+			t.Fatal(err)
+		}
+
+		quotes, _ := svc.Quotes(ctx, "alpha")
+		now := time.Now().UTC()
+		scheduled, err := quotes.Create(ctx, &model.Quote{Author: "Seneca", Quote: "Begin at once to live.", PublishAt: now.Add(time.Hour)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if scheduled.Status != model.StatusPending {
+			t.Fatalf("expected the quote to be held for review, got %s", scheduled.Status)
+		}
+
+		moderation, err := svc.Moderation(ctx, "alpha")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := moderation.Approve(ctx, scheduled.ID); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case e := <-ch:
+			t.Fatalf("expected no event before publication, got %+v", e)
+		default:
+		}
+
+		scheduler := NewScheduler(NewQuoteService(storage.NewInMemory(0)), logger.New("error", "console"), time.Hour, WithCollectionSchedules(svc))
+		scheduler.last = now
+		scheduler.tick(now.Add(2 * time.Hour))
+
+		if e := <-ch; e.Type != events.QuotePublished || e.QuoteID != scheduled.ID || e.Collection != "alpha" {
+			t.Errorf("expected the collection quote to be announced, got %+v", e)
+		}
+	})
+}
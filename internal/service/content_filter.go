@@ -0,0 +1,84 @@
+package service
+
+import (
+	"slices"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/contentfilter"
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+)
+
+// ContentFilter screens the text of quotes, implemented by
+// contentfilter.Filter and by contentfilter.File, which reloads its rules.
+type ContentFilter interface {
+	Check(text string) contentfilter.Result
+}
+
+// WithContentFilter screens the author and text of created and updated
+// quotes and of translations. Prohibited content fails validation, masked
+// words are replaced and flagged quotes are held for review when moderation
+// is on, otherwise they are published with their flags recorded.
+func WithContentFilter(f ContentFilter) Option {
+	return func(s *QuoteService) {
+		s.filter = f
+	}
+}
+
+// screen applies the content filter to q, recording the rules that flag it
+// in q.Flags.
+func (s *QuoteService) screen(q *model.Quote) error {
+	if s.filter == nil {
+		return nil
+	}
+
+	var fields []FieldError
+	var flags []string
+	for _, f := range []struct {
+		name  string
+		value *string
+	}{{"author", &q.Author}, {"quote", &q.Quote}} {
+		res := s.filter.Check(*f.value)
+		if res.Action() == contentfilter.ActionReject {
+			fields = append(fields, prohibited(f.name))
+			continue
+		}
+		*f.value = res.Text
+		flags = append(flags, res.Rules(contentfilter.ActionFlag)...)
+	}
+
+	if len(fields) > 0 {
+		return NewValidationError(fields...)
+	}
+	slices.Sort(flags)
+	q.Flags = slices.Compact(flags)
+	return nil
+}
+
+// screenTranslation applies the content filter to t. Translations are not
+// reviewed, so flagged ones are refused like prohibited ones.
+func (s *QuoteService) screenTranslation(t *model.Translation) error {
+	if s.filter == nil {
+		return nil
+	}
+
+	var fields []FieldError
+	for _, f := range []struct {
+		name  string
+		value *string
+	}{{"quote", &t.Quote}, {"translator", &t.Translator}} {
+		res := s.filter.Check(*f.value)
+		if action := res.Action(); action == contentfilter.ActionReject || action == contentfilter.ActionFlag {
+			fields = append(fields, prohibited(f.name))
+			continue
+		}
+		*f.value = res.Text
+	}
+
+	if len(fields) > 0 {
+		return NewValidationError(fields...)
+	}
+	return nil
+}
+
+func prohibited(field string) FieldError {
+	return FieldError{Field: field, Code: fieldCodeProhibited, Message: "contains prohibited content"}
+}
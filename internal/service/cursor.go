@@ -0,0 +1,35 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const cursorPrefix = "after:"
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor returns an opaque pagination cursor pointing after quote id.
+func EncodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(id)))
+}
+
+// DecodeCursor returns the quote ID encoded by EncodeCursor, 0 for an empty cursor.
+func DecodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, errInvalidCursor
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || id < 0 {
+		return 0, errInvalidCursor
+	}
+	return id, nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+)
+
+// Code is a stable machine-readable error identifier exposed to API clients.
+type Code string
+
+const (
+	CodeValidation   Code = "validation_failed"
+	CodeNotFound     Code = "not_found"
+	CodeConflict     Code = "conflict"
+	CodeUnauthorized Code = "unauthorized"
+	CodeForbidden    Code = "forbidden"
+	CodeTimeout      Code = "timeout"
+	CodeCanceled     Code = "canceled"
+	CodeUnavailable  Code = "unavailable"
+	CodeInternal     Code = "internal_error"
+)
+
+var (
+	ErrValidation   = &Error{Code: CodeValidation}
+	ErrNotFound     = &Error{Code: CodeNotFound}
+	ErrConflict     = &Error{Code: CodeConflict}
+	ErrUnauthorized = &Error{Code: CodeUnauthorized}
+	ErrForbidden    = &Error{Code: CodeForbidden}
+	ErrTimeout      = &Error{Code: CodeTimeout}
+	ErrCanceled     = &Error{Code: CodeCanceled}
+	ErrUnavailable  = &Error{Code: CodeUnavailable}
+	ErrInternal     = &Error{Code: CodeInternal}
+)
+
+// Error is the domain error returned by the service. errors.Is matches any
+// two errors with the same Code, so callers compare against the Err* values.
+type Error struct {
+	Code   Code
+	Detail string
+	Fields []FieldError
+	Err    error
+}
+
+// FieldError describes why a single input field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString(string(e.Code))
+	if e.Detail != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Detail)
+	}
+	for _, f := range e.Fields {
+		b.WriteString("; ")
+		b.WriteString(f.Field)
+		b.WriteString(": ")
+		b.WriteString(f.Message)
+	}
+	if e.Err != nil {
+		b.WriteString(": ")
+		b.WriteString(e.Err.Error())
+	}
+	return b.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+func NewValidationError(fields ...FieldError) *Error {
+	return &Error{Code: CodeValidation, Detail: "request validation failed", Fields: fields}
+}
+
+func NewNotFoundError(detail string, err error) *Error {
+	return &Error{Code: CodeNotFound, Detail: detail, Err: err}
+}
+
+// wrapError converts storage and context errors into domain errors.
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var domainErr *Error
+	switch {
+	case errors.As(err, &domainErr):
+		return err
+	case errors.Is(err, storage.ErrNotFound):
+		return NewNotFoundError("quote not found", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Code: CodeTimeout, Detail: "request timed out", Err: err}
+	case errors.Is(err, context.Canceled):
+		return &Error{Code: CodeCanceled, Detail: "request canceled", Err: err}
+	default:
+		return &Error{Code: CodeInternal, Err: err}
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const maxRejectReasonLength = 500
+
+// Moderation moves quotes through the review workflow: drafts and rejected
+// quotes are submitted, reviewers approve or reject pending ones.
+type Moderation interface {
+	Submit(ctx context.Context, id int) (*model.Quote, error)
+	Approve(ctx context.Context, id int) (*model.Quote, error)
+	Reject(ctx context.Context, id int, reason string) (*model.Quote, error)
+	Pending(ctx context.Context, afterID, limit int) ([]*model.Quote, error)
+	GetForReview(ctx context.Context, id int) (*model.Quote, error)
+
+	AuthorizeReviewer(key string) error
+}
+
+// WithModeration holds new and submitted quotes for review by the holder
+// of reviewerKey instead of publishing them at once.
+func WithModeration(reviewerKey string) Option {
+	return func(s *QuoteService) {
+		s.moderated = true
+		s.reviewerKey = reviewerKey
+	}
+}
+
+// Submit sends a draft or rejected quote to review, or publishes it right
+// away without moderation.
+func (s *QuoteService) Submit(ctx context.Context, id int) (*model.Quote, error) {
+	return s.transition(ctx, id, "submitted", func(q *model.Quote) error {
+		if q.Status != model.StatusDraft && q.Status != model.StatusRejected {
+			return transitionError(q.Status, "only drafts and rejected quotes can be submitted")
+		}
+		q.Status = model.StatusPublished
+		if s.moderated {
+			q.Status = model.StatusPending
+		}
+		q.RejectReason = ""
+		return nil
+	})
+}
+
+// Approve publishes a pending quote, clearing the content filter flags.
+func (s *QuoteService) Approve(ctx context.Context, id int) (*model.Quote, error) {
+	return s.transition(ctx, id, "approved", func(q *model.Quote) error {
+		if q.Status != model.StatusPending {
+			return transitionError(q.Status, "only pending quotes can be approved")
+		}
+		q.Status = model.StatusPublished
+		q.Flags = nil
+		q.ReviewedAt = time.Now().UTC()
+		return nil
+	})
+}
+
+// Reject returns a pending quote to its author with reason, which is
+// required.
+func (s *QuoteService) Reject(ctx context.Context, id int, reason string) (*model.Quote, error) {
+	reason, fieldErr := normalizeField("reason", reason, maxRejectReasonLength)
+	if fieldErr != nil {
+		return nil, NewValidationError(*fieldErr)
+	}
+
+	return s.transition(ctx, id, "rejected", func(q *model.Quote) error {
+		if q.Status != model.StatusPending {
+			return transitionError(q.Status, "only pending quotes can be rejected")
+		}
+		q.Status = model.StatusRejected
+		q.RejectReason = reason
+		q.ReviewedAt = time.Now().UTC()
+		return nil
+	})
+}
+
+// Pending lists quotes awaiting review, oldest first.
+func (s *QuoteService) Pending(ctx context.Context, afterID, limit int) ([]*model.Quote, error) {
+	quotes, err := s.store.FindQuotes(ctx, storage.QuoteFilter{Status: model.StatusPending, AfterID: afterID, Limit: limit})
+	return quotes, wrapError(err)
+}
+
+// GetForReview returns the quote with the given ID whatever its status or
+// publication window, expired quotes awaiting purge included.
+func (s *QuoteService) GetForReview(ctx context.Context, id int) (*model.Quote, error) {
+	quote, err := s.store.GetQuoteByID(ctx, id)
+	return quote, wrapError(err)
+}
+
+func (s *QuoteService) AuthorizeReviewer(key string) error {
+	return authorizeKey(key, s.reviewerKey, "reviewer API key required")
+}
+
+// transition applies change to the stored quote atomically. Subscribers
+// learn about a quote once it is published, as if it was just created.
+func (s *QuoteService) transition(ctx context.Context, id int, action string, change func(q *model.Quote) error) (*model.Quote, error) {
+	updated, err := s.store.ModifyQuote(ctx, id, change)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	logger.FromContext(ctx, nil).Debug().Int("id", id).Str("status", string(updated.Status)).Msg("quote " + action)
+	s.publish(events.QuoteCreated, updated)
+	return updated, nil
+}
+
+func transitionError(status model.Status, detail string) error {
+	return &Error{Code: CodeConflict, Detail: fmt.Sprintf("quote is %s, %s", status, detail)}
+}
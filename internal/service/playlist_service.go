@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const (
+	maxPlaylistNameLength        = 100
+	maxPlaylistDescriptionLength = 500
+	// MaxPlaylistQuotes bounds the members of a playlist.
+	MaxPlaylistQuotes = 1000
+)
+
+type Playlist interface {
+	Create(ctx context.Context, p *model.Playlist) (*model.Playlist, error)
+	List(ctx context.Context) ([]*model.Playlist, error)
+	Get(ctx context.Context, id int) (*model.Playlist, error)
+	Update(ctx context.Context, id int, p *model.Playlist) (*model.Playlist, error)
+	Delete(ctx context.Context, id int) error
+	Add(ctx context.Context, id, quoteID int) (*model.Playlist, error)
+	Insert(ctx context.Context, id, quoteID, position int) (*model.Playlist, error)
+	Move(ctx context.Context, id, quoteID, position int) (*model.Playlist, error)
+	Remove(ctx context.Context, id, quoteID int) (*model.Playlist, error)
+	Quotes(ctx context.Context, id int) ([]*model.Quote, error)
+	Random(ctx context.Context, id int) (*model.Quote, error)
+	Next(ctx context.Context, id, afterQuoteID int) (*model.Quote, error)
+}
+
+// PlaylistService keeps ordered sets of quotes. Members may be in any
+// status, reads serve only the published ones.
+type PlaylistService struct {
+	store  storage.PlaylistStorage
+	quotes storage.QuoteStorage
+}
+
+func NewPlaylistService(store storage.PlaylistStorage, quotes storage.QuoteStorage) *PlaylistService {
+	return &PlaylistService{
+		store:  store,
+		quotes: quotes,
+	}
+}
+
+func (s *PlaylistService) Create(ctx context.Context, p *model.Playlist) (*model.Playlist, error) {
+	if err := s.validate(ctx, p); err != nil {
+		return nil, err
+	}
+	p.CreatedAt = time.Now().UTC()
+	p.UpdatedAt = time.Time{}
+
+	created, err := s.store.CreatePlaylist(ctx, p)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	logger.FromContext(ctx, nil).Debug().Int("id", created.ID).Msg("playlist created")
+	return created, nil
+}
+
+func (s *PlaylistService) List(ctx context.Context) ([]*model.Playlist, error) {
+	playlists, err := s.store.GetPlaylists(ctx)
+	return playlists, wrapError(err)
+}
+
+func (s *PlaylistService) Get(ctx context.Context, id int) (*model.Playlist, error) {
+	p, err := s.store.GetPlaylistByID(ctx, id)
+	return p, wrapPlaylistError(err)
+}
+
+// Update replaces name, description and members of a playlist.
+func (s *PlaylistService) Update(ctx context.Context, id int, p *model.Playlist) (*model.Playlist, error) {
+	if err := s.validate(ctx, p); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.modify(ctx, id, func(current *model.Playlist) error {
+		current.Name, current.Description, current.QuoteIDs = p.Name, p.Description, p.QuoteIDs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx, nil).Debug().Int("id", id).Msg("playlist updated")
+	return updated, nil
+}
+
+func (s *PlaylistService) Delete(ctx context.Context, id int) error {
+	if err := s.store.DeletePlaylist(ctx, id); err != nil {
+		return wrapPlaylistError(err)
+	}
+
+	logger.FromContext(ctx, nil).Debug().Int("id", id).Msg("playlist deleted")
+	return nil
+}
+
+// Add appends a quote to the playlist.
+func (s *PlaylistService) Add(ctx context.Context, id, quoteID int) (*model.Playlist, error) {
+	return s.insert(ctx, id, quoteID, func(p *model.Playlist) int { return len(p.QuoteIDs) })
+}
+
+// Insert puts a quote at position, counted from 0, shifting the members
+// from there on.
+func (s *PlaylistService) Insert(ctx context.Context, id, quoteID, position int) (*model.Playlist, error) {
+	return s.insert(ctx, id, quoteID, func(*model.Playlist) int { return position })
+}
+
+func (s *PlaylistService) insert(ctx context.Context, id, quoteID int, position func(p *model.Playlist) int) (*model.Playlist, error) {
+	if err := s.checkQuote(ctx, "quote_id", quoteID); err != nil {
+		return nil, NewValidationError(*err)
+	}
+
+	return s.modify(ctx, id, func(p *model.Playlist) error {
+		if p.Position(quoteID) >= 0 {
+			return &Error{Code: CodeConflict, Detail: "quote is already in the playlist"}
+		}
+		if len(p.QuoteIDs) >= MaxPlaylistQuotes {
+			return NewValidationError(FieldError{
+				Field:   "quote_id",
+				Code:    fieldCodeTooMany,
+				Message: fmt.Sprintf("playlist already has %d quotes", MaxPlaylistQuotes),
+			})
+		}
+
+		at := position(p)
+		if err := checkPosition(at, len(p.QuoteIDs)); err != nil {
+			return err
+		}
+		p.QuoteIDs = slices.Insert(p.QuoteIDs, at, quoteID)
+		return nil
+	})
+}
+
+// Move puts a member at position, counted from 0 in the playlist without
+// it.
+func (s *PlaylistService) Move(ctx context.Context, id, quoteID, position int) (*model.Playlist, error) {
+	return s.modify(ctx, id, func(p *model.Playlist) error {
+		from := p.Position(quoteID)
+		if from < 0 {
+			return errNotMember
+		}
+		if err := checkPosition(position, len(p.QuoteIDs)-1); err != nil {
+			return err
+		}
+		p.QuoteIDs = slices.Insert(slices.Delete(p.QuoteIDs, from, from+1), position, quoteID)
+		return nil
+	})
+}
+
+func (s *PlaylistService) Remove(ctx context.Context, id, quoteID int) (*model.Playlist, error) {
+	return s.modify(ctx, id, func(p *model.Playlist) error {
+		at := p.Position(quoteID)
+		if at < 0 {
+			return errNotMember
+		}
+		p.QuoteIDs = slices.Delete(p.QuoteIDs, at, at+1)
+		return nil
+	})
+}
+
+// Quotes returns the published members of the playlist in its order.
+func (s *PlaylistService) Quotes(ctx context.Context, id int) ([]*model.Quote, error) {
+	p, err := s.store.GetPlaylistByID(ctx, id)
+	if err != nil {
+		return nil, wrapPlaylistError(err)
+	}
+	return s.served(ctx, p.QuoteIDs)
+}
+
+// Random picks one of the published members of the playlist.
+func (s *PlaylistService) Random(ctx context.Context, id int) (*model.Quote, error) {
+	quotes, err := s.Quotes(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(quotes) == 0 {
+		return nil, errEmptyPlaylist
+	}
+	return quotes[rand.Intn(len(quotes))], nil
+}
+
+// Next returns the published member following afterQuoteID, the first one
+// when afterQuoteID is 0 and again the first one after the last.
+func (s *PlaylistService) Next(ctx context.Context, id, afterQuoteID int) (*model.Quote, error) {
+	p, err := s.store.GetPlaylistByID(ctx, id)
+	if err != nil {
+		return nil, wrapPlaylistError(err)
+	}
+
+	start := 0
+	if afterQuoteID != 0 {
+		at := p.Position(afterQuoteID)
+		if at < 0 {
+			return nil, errNotMember
+		}
+		start = at + 1
+	}
+
+	// Unpublished members are skipped, so the order is rotated to begin
+	// after the current one and the first served quote wins.
+	order := append(slices.Clone(p.QuoteIDs[start:]), p.QuoteIDs[:start]...)
+	for _, quoteID := range order {
+		quotes, err := s.served(ctx, []int{quoteID})
+		if err != nil {
+			return nil, err
+		}
+		if len(quotes) > 0 {
+			return quotes[0], nil
+		}
+	}
+	return nil, errEmptyPlaylist
+}
+
+// RemoveQuotes drops removed quotes from every playlist. It matches
+// RemovalHook, failures are only logged.
+func (s *PlaylistService) RemoveQuotes(ctx context.Context, quoteIDs []int) {
+	if err := s.store.RemoveQuotes(ctx, quoteIDs...); err != nil {
+		logger.FromContext(ctx, nil).Error().Err(err).Any("quote_ids", quoteIDs).Msg("failed to remove quotes from playlists")
+	}
+}
+
+func (s *PlaylistService) modify(ctx context.Context, id int, modify func(p *model.Playlist) error) (*model.Playlist, error) {
+	updated, err := s.store.ModifyPlaylist(ctx, id, func(p *model.Playlist) error {
+		if err := modify(p); err != nil {
+			return err
+		}
+		p.UpdatedAt = time.Now().UTC()
+		return nil
+	})
+	return updated, wrapPlaylistError(err)
+}
+
+// served looks the quotes up in order, leaving out the ones readers do not
+// see and members whose quote no longer exists.
+func (s *PlaylistService) served(ctx context.Context, quoteIDs []int) ([]*model.Quote, error) {
+	now := time.Now()
+	quotes := make([]*model.Quote, 0, len(quoteIDs))
+	for _, quoteID := range quoteIDs {
+		q, err := s.quotes.GetQuoteByID(ctx, quoteID)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		if q.Status == model.StatusPublished && q.Live(now) {
+			quotes = append(quotes, q)
+		}
+	}
+	return quotes, nil
+}
+
+func (s *PlaylistService) validate(ctx context.Context, p *model.Playlist) error {
+	var fields []FieldError
+
+	name, fieldErr := normalizeField("name", p.Name, maxPlaylistNameLength)
+	if fieldErr != nil {
+		fields = append(fields, *fieldErr)
+	}
+
+	description := p.Description
+	if description != "" {
+		description, fieldErr = normalizeField("description", description, maxPlaylistDescriptionLength)
+		if fieldErr != nil {
+			fields = append(fields, *fieldErr)
+		}
+	}
+
+	if len(p.QuoteIDs) > MaxPlaylistQuotes {
+		fields = append(fields, FieldError{
+			Field:   "quote_ids",
+			Code:    fieldCodeTooMany,
+			Message: fmt.Sprintf("must have at most %d items", MaxPlaylistQuotes),
+		})
+	} else {
+		for i, quoteID := range p.QuoteIDs {
+			field := fmt.Sprintf("quote_ids[%d]", i)
+			if slices.Index(p.QuoteIDs, quoteID) < i {
+				fields = append(fields, FieldError{Field: field, Code: fieldCodeInvalid, Message: "must not repeat"})
+				continue
+			}
+			if err := s.checkQuote(ctx, field, quoteID); err != nil {
+				fields = append(fields, *err)
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		return NewValidationError(fields...)
+	}
+
+	p.Name = name
+	p.Description = description
+	if p.QuoteIDs == nil {
+		p.QuoteIDs = []int{}
+	}
+	return nil
+}
+
+// checkQuote reports a field error unless the quote is published and within
+// its publication window. The check runs outside the playlist lock, so a
+// quote removed concurrently may still be stored as a member after its
+// removal hook ran. Such dangling members are harmless: served leaves out
+// missing quotes.
+func (s *PlaylistService) checkQuote(ctx context.Context, field string, quoteID int) *FieldError {
+	q, err := s.quotes.GetQuoteByID(ctx, quoteID)
+	if err != nil || q.Status != model.StatusPublished || !q.Live(time.Now()) {
+		return &FieldError{Field: field, Code: fieldCodeInvalid, Message: "must be the ID of a published quote"}
+	}
+	return nil
+}
+
+func checkPosition(position, last int) error {
+	if position < 0 || position > last {
+		return NewValidationError(FieldError{
+			Field:   "position",
+			Code:    fieldCodeInvalid,
+			Message: fmt.Sprintf("must be between 0 and %d", last),
+		})
+	}
+	return nil
+}
+
+var (
+	errNotMember     = NewNotFoundError("quote is not in the playlist", nil)
+	errEmptyPlaylist = NewNotFoundError("playlist has no published quotes", nil)
+)
+
+func wrapPlaylistError(err error) error {
+	if errors.Is(err, storage.ErrNotFound) {
+		return NewNotFoundError("playlist not found", err)
+	}
+	return wrapError(err)
+}
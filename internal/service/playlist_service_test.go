@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+)
+
+func TestPlaylistService(t *testing.T) {
+	ctx := context.Background()
+
+	// newServices returns playlists over five published quotes but the
+	// third, a draft, in a moderated storage that keeps at most five.
+	newServices := func(t *testing.T) (*PlaylistService, *QuoteService) {
+		t.Helper()
+		store := storage.NewInMemory(5)
+		playlists := NewPlaylistService(storage.NewPlaylistInMemory(), store)
+		quotes := NewQuoteService(store, WithModeration("reviewer"), WithRemovalHook(playlists.RemoveQuotes))
+		for _, status := range []model.Status{"", "", model.StatusDraft, "", ""} {
+			q, err := quotes.Create(ctx, &model.Quote{Author: "A", Quote: "Q", Status: status})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status == "" {
+				if _, err := quotes.Approve(ctx, q.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		return playlists, quotes
+	}
+
+	t.Run("Create validates members", func(t *testing.T) {
+		playlists, _ := newServices(t)
+
+		_, err := playlists.Create(ctx, &model.Playlist{Name: " ", QuoteIDs: []int{1, 1, 3, 9}})
+		var svcErr *Error
+		if !errors.As(err, &svcErr) || len(svcErr.Fields) != 4 {
+			t.Fatalf("expected four field errors, got %v", err)
+		}
+
+		p, err := playlists.Create(ctx, &model.Playlist{Name: " Morning "})
+		if err != nil || p.Name != "Morning" || p.QuoteIDs == nil {
+			t.Errorf("unexpected playlist %+v, %v", p, err)
+		}
+	})
+
+	t.Run("Members keep their order", func(t *testing.T) {
+		playlists, _ := newServices(t)
+		p, _ := playlists.Create(ctx, &model.Playlist{Name: "p", QuoteIDs: []int{1}})
+
+		steps := []func() (*model.Playlist, error){
+			func() (*model.Playlist, error) { return playlists.Add(ctx, p.ID, 2) },
+			func() (*model.Playlist, error) { return playlists.Insert(ctx, p.ID, 4, 0) },
+			func() (*model.Playlist, error) { return playlists.Move(ctx, p.ID, 1, 2) },
+		}
+		for _, step := range steps {
+			if _, err := step(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got, _ := playlists.Get(ctx, p.ID); !slices.Equal(got.QuoteIDs, []int{4, 2, 1}) {
+			t.Errorf("expected members 4, 2, 1, got %v", got.QuoteIDs)
+		}
+
+		if _, err := playlists.Add(ctx, p.ID, 2); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+		if _, err := playlists.Add(ctx, p.ID, 3); !errors.Is(err, ErrValidation) {
+			t.Errorf("expected ErrValidation for a draft, got %v", err)
+		}
+		if _, err := playlists.Insert(ctx, p.ID, 5, 4); !errors.Is(err, ErrValidation) {
+			t.Errorf("expected ErrValidation for a position past the end, got %v", err)
+		}
+		if _, err := playlists.Move(ctx, p.ID, 5, 0); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a non-member, got %v", err)
+		}
+	})
+
+	t.Run("Reads serve published quotes", func(t *testing.T) {
+		playlists, quotes := newServices(t)
+		p, _ := playlists.Create(ctx, &model.Playlist{Name: "p", QuoteIDs: []int{2, 4, 1}})
+		empty, _ := playlists.Create(ctx, &model.Playlist{Name: "e", QuoteIDs: []int{4}})
+		// The edit sends quote 4 back to review.
+		if _, err := quotes.Update(ctx, 4, &model.Quote{Author: "A", Quote: "Q2"}); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := playlists.Quotes(ctx, p.ID)
+		if err != nil || len(got) != 2 || got[0].ID != 2 || got[1].ID != 1 {
+			t.Errorf("expected quotes 2 and 1, got %v, %v", got, err)
+		}
+		if q, err := playlists.Random(ctx, p.ID); err != nil || q.ID == 4 {
+			t.Errorf("expected a published quote, got %v, %v", q, err)
+		}
+
+		for after, want := range map[int]int{0: 2, 2: 1, 4: 1, 1: 2} {
+			if q, err := playlists.Next(ctx, p.ID, after); err != nil || q.ID != want {
+				t.Errorf("expected quote %d after %d, got %v, %v", want, after, q, err)
+			}
+		}
+
+		if _, err := playlists.Next(ctx, empty.ID, 0); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a playlist without published quotes, got %v", err)
+		}
+	})
+
+	t.Run("Deleted and evicted quotes leave playlists", func(t *testing.T) {
+		playlists, quotes := newServices(t)
+		p, _ := playlists.Create(ctx, &model.Playlist{Name: "p", QuoteIDs: []int{1, 2, 4}})
+
+		if err := quotes.Delete(ctx, 2); err != nil {
+			t.Fatal(err)
+		}
+		// The first quote fills the storage, the second evicts the oldest one.
+		if _, err := quotes.Create(ctx, &model.Quote{Author: "A", Quote: "Q"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := quotes.Create(ctx, &model.Quote{Author: "A", Quote: "Q"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, _ := playlists.Get(ctx, p.ID); !slices.Equal(got.QuoteIDs, []int{4}) {
+			t.Errorf("expected only member 4 left, got %v", got.QuoteIDs)
+		}
+	})
+
+	t.Run("Removal outlives the request", func(t *testing.T) {
+		store := storage.NewInMemory(4)
+		playlists := NewPlaylistService(storage.NewPlaylistInMemory(), store)
+		reqCtx, cancel := context.WithCancel(ctx)
+		// The client goes away once the quote is deleted.
+		quotes := NewQuoteService(store, WithRemovalHook(func(ctx context.Context, ids []int) {
+			cancel()
+			playlists.RemoveQuotes(ctx, ids)
+		}))
+		q, _ := quotes.Create(ctx, &model.Quote{Author: "A", Quote: "Q"})
+		p, _ := playlists.Create(ctx, &model.Playlist{Name: "p", QuoteIDs: []int{q.ID}})
+
+		if err := quotes.Delete(reqCtx, q.ID); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := playlists.Get(ctx, p.ID); len(got.QuoteIDs) != 0 {
+			t.Errorf("expected the quote to leave the playlist, got %v", got.QuoteIDs)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+)
+
+// DefaultPopularityHalfLife is how long a like or rating takes to lose half
+// of its weight in the top listing.
+const DefaultPopularityHalfLife = 7 * 24 * time.Hour
+
+const maxUserLength = 100
+
+// WithPopularityHalfLife overrides DefaultPopularityHalfLife, zero disables
+// the decay.
+func WithPopularityHalfLife(d time.Duration) Option {
+	return func(s *QuoteService) {
+		s.halfLife = d
+	}
+}
+
+// SetLike likes the quote on behalf of user, or takes the like back. A
+// user's repeated likes count once. Only published quotes take votes.
+func (s *QuoteService) SetLike(ctx context.Context, id int, user string, liked bool) (model.Popularity, error) {
+	user, err := normalizeUser(user)
+	if err != nil {
+		return model.Popularity{}, err
+	}
+
+	if _, err := s.Get(ctx, id); err != nil {
+		return model.Popularity{}, err
+	}
+
+	p, err := s.store.SetLike(ctx, id, user, liked, time.Now().UTC())
+	return p, wrapError(err)
+}
+
+// Rate records a rating from MinRating to MaxRating, replacing the previous
+// rating of the same user.
+func (s *QuoteService) Rate(ctx context.Context, id int, user string, rating int) (model.Popularity, error) {
+	user, err := normalizeUser(user)
+	if err != nil {
+		return model.Popularity{}, err
+	}
+	if rating < model.MinRating || rating > model.MaxRating {
+		return model.Popularity{}, NewValidationError(FieldError{
+			Field:   "rating",
+			Code:    fieldCodeInvalid,
+			Message: fmt.Sprintf("must be between %d and %d", model.MinRating, model.MaxRating),
+		})
+	}
+
+	if _, err := s.Get(ctx, id); err != nil {
+		return model.Popularity{}, err
+	}
+
+	p, err := s.store.SetRating(ctx, id, user, rating, time.Now().UTC())
+	return p, wrapError(err)
+}
+
+// Unrate removes the rating of user, if any.
+func (s *QuoteService) Unrate(ctx context.Context, id int, user string) (model.Popularity, error) {
+	user, err := normalizeUser(user)
+	if err != nil {
+		return model.Popularity{}, err
+	}
+
+	if _, err := s.Get(ctx, id); err != nil {
+		return model.Popularity{}, err
+	}
+
+	p, err := s.store.SetRating(ctx, id, user, 0, time.Now().UTC())
+	return p, wrapError(err)
+}
+
+func (s *QuoteService) Popularity(ctx context.Context, id int) (model.Popularity, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return model.Popularity{}, err
+	}
+
+	p, err := s.store.GetPopularity(ctx, id)
+	return p, wrapError(err)
+}
+
+// Top ranks quotes by likes and ratings, recent ones weighing more.
+func (s *QuoteService) Top(ctx context.Context, limit int) ([]model.RankedQuote, error) {
+	now := time.Now().UTC()
+	filter := storage.QuoteFilter{Status: model.StatusPublished, At: now, Limit: limit}
+	ranked, err := s.store.TopQuotes(ctx, filter, storage.Decay{Now: now, HalfLife: s.halfLife})
+	return ranked, wrapError(err)
+}
+
+// normalizeUser validates the opaque ID votes are deduplicated by.
+func normalizeUser(user string) (string, error) {
+	user = strings.TrimSpace(user)
+	switch {
+	case user == "":
+		return "", NewValidationError(FieldError{Field: "user", Code: fieldCodeRequired, Message: "must be non-empty"})
+	case !utf8.ValidString(user):
+		return "", NewValidationError(FieldError{Field: "user", Code: fieldCodeInvalidEncoding, Message: "must be valid UTF-8"})
+	case utf8.RuneCountInString(user) > maxUserLength:
+		return "", NewValidationError(FieldError{
+			Field:   "user",
+			Code:    fieldCodeTooLong,
+			Message: fmt.Sprintf("must be at most %d characters", maxUserLength),
+		})
+	}
+	return user, nil
+}
@@ -1,42 +1,397 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
 	"github.com/zonder12120/brandscout-quotebook/internal/model"
 	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
 )
 
 type Quote interface {
-	Create(q *model.Quote) (*model.Quote, error)
-	List() ([]*model.Quote, error)
-	GetRandom() (*model.Quote, error)
-	GetByAuthor(author string) ([]*model.Quote, error)
-	Delete(id int) error
+	Create(ctx context.Context, q *model.Quote) (*model.Quote, error)
+	List(ctx context.Context) ([]*model.Quote, error)
+	ListPage(ctx context.Context, afterID, limit int) ([]*model.Quote, error)
+	Get(ctx context.Context, id int) (*model.Quote, error)
+	GetRandom(ctx context.Context) (*model.Quote, error)
+	FindRandom(ctx context.Context, filter storage.QuoteFilter) (*model.Quote, error)
+	GetByAuthor(ctx context.Context, author string) ([]*model.Quote, error)
+	GetByAuthors(ctx context.Context, authors []string) (map[string][]*model.Quote, error)
+	Find(ctx context.Context, filter storage.QuoteFilter) ([]*model.Quote, error)
+	Update(ctx context.Context, id int, q *model.Quote) (*model.Quote, error)
+	Delete(ctx context.Context, id int) error
+	Batch(ctx context.Context, ops []BatchOp, atomic bool) ([]BatchResult, error)
+	AddTranslation(ctx context.Context, id int, t *model.Translation) (*model.Translation, error)
+	DeleteTranslation(ctx context.Context, id int, lang string) error
+	SetLike(ctx context.Context, id int, user string, liked bool) (model.Popularity, error)
+	Rate(ctx context.Context, id int, user string, rating int) (model.Popularity, error)
+	Unrate(ctx context.Context, id int, user string) (model.Popularity, error)
+	Popularity(ctx context.Context, id int) (model.Popularity, error)
+	Top(ctx context.Context, limit int) ([]model.RankedQuote, error)
+}
+
+// Publisher receives quote change events, implemented by events.Bus.
+type Publisher interface {
+	Publish(e events.Event)
 }
 
+// QuoteService serves published quotes unless asked for another status,
+// reviewers look up single quotes of any status with GetForReview.
 type QuoteService struct {
-	store storage.QuoteStorage
+	store       storage.QuoteStorage
+	limits      Limits
+	publisher   Publisher
+	halfLife    time.Duration
+	moderated   bool
+	reviewerKey string
+	filter      ContentFilter
+	onRemove    RemovalHook
+	maxBatch    int
+}
+
+type Option func(*QuoteService)
+
+// WithLimits overrides DefaultLimits, zero values disable the check.
+func WithLimits(limits Limits) Option {
+	return func(s *QuoteService) {
+		s.limits = limits
+	}
+}
+
+// RemovalHook is told the IDs of quotes removed from storage, whether
+// deleted, evicted or expired and whatever their status.
+type RemovalHook func(ctx context.Context, ids []int)
+
+// WithRemovalHook calls hook after quotes are removed, for example to drop
+// them from playlists.
+func WithRemovalHook(hook RemovalHook) Option {
+	return func(s *QuoteService) {
+		s.onRemove = hook
+	}
+}
+
+// WithPublisher makes the service publish an event for every change.
+func WithPublisher(p Publisher) Option {
+	return func(s *QuoteService) {
+		s.publisher = p
+	}
+}
+
+func NewQuoteService(store storage.QuoteStorage, opts ...Option) *QuoteService {
+	s := &QuoteService{
+		store:    store,
+		limits:   DefaultLimits(),
+		halfLife: DefaultPopularityHalfLife,
+		maxBatch: DefaultMaxBatchSize,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *QuoteService) Create(ctx context.Context, q *model.Quote) (*model.Quote, error) {
+	if err := s.prepareCreate(q); err != nil {
+		return nil, err
+	}
+
+	created, evicted, err := s.store.CreateQuote(ctx, q)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	logger.FromContext(ctx, nil).Debug().Int("id", created.ID).Msg("quote created")
+	if evicted != nil {
+		s.removed(ctx, evicted.ID)
+		s.publish(events.QuoteEvicted, evicted)
+	}
+	s.publish(events.QuoteCreated, created)
+	return created, nil
+}
+
+// prepareCreate validates a new quote and sets its status and creation
+// time.
+func (s *QuoteService) prepareCreate(q *model.Quote) error {
+	if err := s.initialStatus(q); err != nil {
+		return err
+	}
+	if err := s.normalizeQuote(q); err != nil {
+		return err
+	}
+	if err := s.screen(q); err != nil {
+		return err
+	}
+	if s.moderated && len(q.Flags) > 0 && q.Status == model.StatusPublished {
+		q.Status = model.StatusPending
+	}
+	q.CreatedAt = time.Now().UTC()
+	q.UpdatedAt = time.Time{}
+	return nil
+}
+
+// initialStatus starts q as a draft when asked to, otherwise as pending
+// review or, without moderation, published.
+func (s *QuoteService) initialStatus(q *model.Quote) error {
+	switch {
+	case q.Status == model.StatusDraft:
+	case q.Status != "":
+		return NewValidationError(FieldError{Field: "status", Code: fieldCodeInvalid, Message: "must be draft or omitted"})
+	case s.moderated:
+		q.Status = model.StatusPending
+	default:
+		q.Status = model.StatusPublished
+	}
+	return nil
+}
+
+func (s *QuoteService) List(ctx context.Context) ([]*model.Quote, error) {
+	quotes, err := s.store.FindQuotes(ctx, storage.QuoteFilter{Status: model.StatusPublished, At: time.Now().UTC()})
+	return quotes, wrapError(err)
+}
+
+func (s *QuoteService) ListPage(ctx context.Context, afterID, limit int) ([]*model.Quote, error) {
+	quotes, err := s.store.FindQuotes(ctx, storage.QuoteFilter{Status: model.StatusPublished, At: time.Now().UTC(), AfterID: afterID, Limit: limit})
+	return quotes, wrapError(err)
+}
+
+// Get returns the published quote with the given ID. Quotes under review
+// and quotes outside their publication window are not found, reviewers see
+// them with GetForReview.
+func (s *QuoteService) Get(ctx context.Context, id int) (*model.Quote, error) {
+	quote, err := s.store.GetQuoteByID(ctx, id)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if quote.Status != model.StatusPublished || !quote.Live(time.Now()) {
+		return nil, wrapError(storage.ErrNotFound)
+	}
+	return quote, nil
+}
+
+func (s *QuoteService) GetRandom(ctx context.Context) (*model.Quote, error) {
+	quote, err := s.store.FindRandomQuote(ctx, storage.QuoteFilter{Status: model.StatusPublished, At: time.Now().UTC()})
+	return quote, wrapError(err)
+}
+
+// FindRandom picks a random quote among those matching filter. Quotes in
+// another language than filter.Lang match too when they have a translation
+// into it, and are returned localized.
+func (s *QuoteService) FindRandom(ctx context.Context, filter storage.QuoteFilter) (*model.Quote, error) {
+	filter = normalizeFilter(filter)
+	filter.Translated = filter.Lang != ""
+
+	quote, err := s.store.FindRandomQuote(ctx, filter)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return quote.Localize(filter.Lang), nil
+}
+
+func (s *QuoteService) GetByAuthor(ctx context.Context, author string) ([]*model.Quote, error) {
+	quotes, err := s.store.FindQuotes(ctx, storage.QuoteFilter{Author: normalizeText(author), Status: model.StatusPublished, At: time.Now().UTC()})
+	return quotes, wrapError(err)
+}
+
+// GetByAuthors returns quotes keyed by lower-cased author name.
+func (s *QuoteService) GetByAuthors(ctx context.Context, authors []string) (map[string][]*model.Quote, error) {
+	normalized := make([]string, 0, len(authors))
+	for _, a := range authors {
+		normalized = append(normalized, normalizeText(a))
+	}
+
+	quotes, err := s.store.GetQuotesByAuthors(ctx, normalized)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	for author, list := range quotes {
+		published := list[:0:0]
+		for _, q := range list {
+			if q.Status == model.StatusPublished {
+				published = append(published, q)
+			}
+		}
+		quotes[author] = published
+	}
+	return quotes, nil
+}
+
+func (s *QuoteService) Find(ctx context.Context, filter storage.QuoteFilter) ([]*model.Quote, error) {
+	quotes, err := s.store.FindQuotes(ctx, normalizeFilter(filter))
+	return quotes, wrapError(err)
+}
+
+// normalizeFilter brings filter values to the form quotes are stored in
+// and limits it to published quotes live now unless it asks for another
+// status.
+func normalizeFilter(filter storage.QuoteFilter) storage.QuoteFilter {
+	if filter.Status == "" {
+		filter.Status = model.StatusPublished
+	}
+	if filter.Status == model.StatusPublished && filter.At.IsZero() {
+		filter.At = time.Now().UTC()
+	}
+	filter.Author = normalizeText(filter.Author)
+	filter.Tag = NormalizeTag(filter.Tag)
+	filter.Text = normalizeText(filter.Text)
+	filter.Lang = strings.ToLower(strings.TrimSpace(filter.Lang))
+	return filter
+}
+
+func (s *QuoteService) Update(ctx context.Context, id int, q *model.Quote) (*model.Quote, error) {
+	if err := s.prepareUpdate(q); err != nil {
+		return nil, err
+	}
+
+	edit := s.edit(q)
+	updated, err := s.store.ModifyQuote(ctx, id, edit.apply)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	logger.FromContext(ctx, nil).Debug().Int("id", id).Msg("quote updated")
+	for _, c := range edit.changes(updated) {
+		s.publish(c.typ, c.quote)
+	}
+	return updated, nil
 }
 
-func NewQuoteService(store storage.QuoteStorage) *QuoteService {
-	return &QuoteService{store: store}
+func (s *QuoteService) prepareUpdate(q *model.Quote) error {
+	if err := s.normalizeQuote(q); err != nil {
+		return err
+	}
+	return s.screen(q)
 }
 
-func (s *QuoteService) Create(q *model.Quote) (*model.Quote, error) {
-	return s.store.CreateQuote(q)
+// edit applies the prepared q to the stored quote. The edit keeps the
+// moderation state, except that with moderation a published quote goes
+// back to review whenever it changes.
+func (s *QuoteService) edit(q *model.Quote) *quoteEdit {
+	return &quoteEdit{q: q, moderated: s.moderated}
 }
 
-func (s *QuoteService) List() ([]*model.Quote, error) {
-	return s.store.GetQuotesList()
+type quoteEdit struct {
+	q         *model.Quote
+	moderated bool
+	// withdrawn is the quote as it was published before the edit sent it
+	// back to review.
+	withdrawn *model.Quote
+}
+
+func (e *quoteEdit) apply(current *model.Quote) error {
+	if e.moderated && current.Status == model.StatusPublished {
+		published := *current
+		e.withdrawn = &published
+		current.Status = model.StatusPending
+	}
+	current.Author, current.Quote, current.Tags, current.Lang = e.q.Author, e.q.Quote, e.q.Tags, e.q.Lang
+	current.PublishAt, current.ExpireAt = e.q.PublishAt, e.q.ExpireAt
+	current.Flags = e.q.Flags
+	current.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// changes are the events of the edit: an update, or the deletion of the
+// quote subscribers have seen when it was withdrawn for review.
+func (e *quoteEdit) changes(updated *model.Quote) []batchChange {
+	if e.withdrawn != nil {
+		return []batchChange{{typ: events.QuoteDeleted, quote: e.withdrawn}}
+	}
+	return []batchChange{{typ: events.QuoteUpdated, quote: updated}}
+}
+
+func (s *QuoteService) Delete(ctx context.Context, id int) error {
+	// Subscribers get the deleted quote, so look it up while it still exists.
+	deleted := &model.Quote{ID: id}
+	if s.publisher != nil {
+		if q, err := s.store.GetQuoteByID(ctx, id); err == nil {
+			deleted = q
+		}
+	}
+
+	if err := s.store.DeleteByID(ctx, id); err != nil {
+		return wrapError(err)
+	}
+
+	logger.FromContext(ctx, nil).Debug().Int("id", id).Msg("quote deleted")
+	s.removed(ctx, id)
+	s.publish(events.QuoteDeleted, deleted)
+	return nil
+}
+
+// AddTranslation attaches a translation to the quote with the given ID, one
+// per language other than the quote's own.
+func (s *QuoteService) AddTranslation(ctx context.Context, id int, t *model.Translation) (*model.Translation, error) {
+	if err := s.normalizeTranslation(t); err != nil {
+		return nil, err
+	}
+	if err := s.screenTranslation(t); err != nil {
+		return nil, err
+	}
+
+	current, err := s.store.GetQuoteByID(ctx, id)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if current.Lang == t.Lang {
+		return nil, NewValidationError(FieldError{Field: "lang", Code: fieldCodeInvalid, Message: "must differ from the language of the quote"})
+	}
+
+	t.CreatedAt = time.Now().UTC()
+	updated, err := s.store.AddTranslation(ctx, id, *t)
+	if err != nil {
+		return nil, wrapTranslationError(err)
+	}
+
+	logger.FromContext(ctx, nil).Debug().Int("id", id).Str("lang", t.Lang).Msg("translation added")
+	s.publish(events.QuoteUpdated, updated)
+	return t, nil
+}
+
+func (s *QuoteService) DeleteTranslation(ctx context.Context, id int, lang string) error {
+	updated, err := s.store.DeleteTranslation(ctx, id, strings.ToLower(strings.TrimSpace(lang)))
+	if err != nil {
+		return wrapTranslationError(err)
+	}
+
+	logger.FromContext(ctx, nil).Debug().Int("id", id).Str("lang", lang).Msg("translation deleted")
+	s.publish(events.QuoteUpdated, updated)
+	return nil
 }
 
-func (s *QuoteService) GetRandom() (*model.Quote, error) {
-	return s.store.GetRandomQuote()
+func wrapTranslationError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrTranslationNotFound):
+		return NewNotFoundError("translation not found", err)
+	case errors.Is(err, storage.ErrAlreadyExists):
+		return &Error{Code: CodeConflict, Detail: "quote already has a translation into this language", Err: err}
+	default:
+		return wrapError(err)
+	}
 }
 
-func (s *QuoteService) GetByAuthor(author string) ([]*model.Quote, error) {
-	return s.store.GetQuotesByAuthor(author)
+// removed runs the removal hook for quotes already gone from the storage.
+// The cleanup must not be lost when the client disconnects or the request
+// times out, so the hook gets ctx without its cancellation.
+func (s *QuoteService) removed(ctx context.Context, ids ...int) {
+	if s.onRemove != nil && len(ids) > 0 {
+		s.onRemove(context.WithoutCancel(ctx), ids)
+	}
 }
 
-func (s *QuoteService) Delete(id int) error {
-	return s.store.DeleteByID(id)
+// publish notifies subscribers of changes to published quotes, the rest
+// are not public yet. Neither are quotes outside their publication window,
+// whose start and end the Scheduler checks and announces itself.
+func (s *QuoteService) publish(t events.Type, q *model.Quote) {
+	if s.publisher == nil || q.Status != model.StatusPublished {
+		return
+	}
+	if t != events.QuotePublished && t != events.QuoteExpired && !q.Live(time.Now()) {
+		return
+	}
+	s.publisher.Publish(events.Event{Type: t, QuoteID: q.ID, Quote: *q})
 }
@@ -1,11 +1,17 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/zonder12120/brandscout-quotebook/internal/contentfilter"
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
 	"github.com/zonder12120/brandscout-quotebook/internal/model"
 	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
 )
 
 var (
@@ -26,29 +32,81 @@ type mockStorage struct {
 	calledWith   int
 }
 
-func (m *mockStorage) CreateQuote(q *model.Quote) (*model.Quote, error) {
-	return m.createdQuote, m.createErr
+func (m *mockStorage) CreateQuote(_ context.Context, q *model.Quote) (*model.Quote, *model.Quote, error) {
+	return m.createdQuote, nil, m.createErr
 }
 
-func (m *mockStorage) GetQuotesList() ([]*model.Quote, error) {
-	return m.quotesList, m.listErr
+func (m *mockStorage) GetQuoteByID(_ context.Context, id int) (*model.Quote, error) {
+	m.calledWith = id
+	return m.createdQuote, m.getRandomErr
 }
 
-func (m *mockStorage) GetRandomQuote() (*model.Quote, error) {
+func (m *mockStorage) FindRandomQuote(_ context.Context, filter storage.QuoteFilter) (*model.Quote, error) {
 	return m.createdQuote, m.getRandomErr
 }
 
-func (m *mockStorage) GetQuotesByAuthor(author string) ([]*model.Quote, error) {
-	m.authorArg = author
-	return m.quotesList, m.getByAuthorErr
+func (m *mockStorage) GetQuotesByAuthors(_ context.Context, authors []string) (map[string][]*model.Quote, error) {
+	return map[string][]*model.Quote{}, m.getByAuthorErr
 }
 
-func (m *mockStorage) DeleteByID(id int) error {
+func (m *mockStorage) FindQuotes(_ context.Context, filter storage.QuoteFilter) ([]*model.Quote, error) {
+	m.authorArg = filter.Author
+	return m.quotesList, m.listErr
+}
+
+func (m *mockStorage) ModifyQuote(_ context.Context, id int, modify func(q *model.Quote) error) (*model.Quote, error) {
+	m.calledWith = id
+	return m.createdQuote, m.createErr
+}
+
+func (m *mockStorage) DeleteByID(_ context.Context, id int) error {
 	m.calledWith = id
 	return m.deleteErr
 }
 
+func (m *mockStorage) Batch(_ context.Context, fn func(tx storage.QuoteTx) error) error {
+	return m.createErr
+}
+
+func (m *mockStorage) ScheduledQuotes(_ context.Context, after, until time.Time) ([]*model.Quote, error) {
+	return m.quotesList, m.listErr
+}
+
+func (m *mockStorage) PurgeExpired(_ context.Context, at time.Time) ([]*model.Quote, error) {
+	return nil, m.deleteErr
+}
+
+func (m *mockStorage) AddTranslation(_ context.Context, id int, t model.Translation) (*model.Quote, error) {
+	m.calledWith = id
+	return m.createdQuote, m.createErr
+}
+
+func (m *mockStorage) DeleteTranslation(_ context.Context, id int, lang string) (*model.Quote, error) {
+	m.calledWith = id
+	return m.createdQuote, m.deleteErr
+}
+
+func (m *mockStorage) SetLike(_ context.Context, id int, user string, liked bool, at time.Time) (model.Popularity, error) {
+	m.calledWith = id
+	return model.Popularity{QuoteID: id}, m.createErr
+}
+
+func (m *mockStorage) SetRating(_ context.Context, id int, user string, rating int, at time.Time) (model.Popularity, error) {
+	m.calledWith = id
+	return model.Popularity{QuoteID: id}, m.createErr
+}
+
+func (m *mockStorage) GetPopularity(_ context.Context, id int) (model.Popularity, error) {
+	m.calledWith = id
+	return model.Popularity{QuoteID: id}, m.getRandomErr
+}
+
+func (m *mockStorage) TopQuotes(_ context.Context, filter storage.QuoteFilter, decay storage.Decay) ([]model.RankedQuote, error) {
+	return nil, m.listErr
+}
+
 func TestQuoteService(t *testing.T) {
+	ctx := context.Background()
 	testQuote := &model.Quote{ID: 1, Author: "Test", Quote: "Test"}
 	testQuotes := []*model.Quote{testQuote}
 
@@ -69,7 +127,7 @@ func TestQuoteService(t *testing.T) {
 			{
 				name:        "storage error",
 				mock:        &mockStorage{createErr: errStorage},
-				input:       testQuote,
+				input:       &model.Quote{Author: "Test", Quote: "Test"},
 				expectedErr: errStorage,
 			},
 		}
@@ -77,7 +135,7 @@ func TestQuoteService(t *testing.T) {
 		for _, tc := range tt {
 			t.Run(tc.name, func(t *testing.T) {
 				service := NewQuoteService(tc.mock)
-				result, err := service.Create(tc.input)
+				result, err := service.Create(ctx, tc.input)
 
 				if !errors.Is(err, tc.expectedErr) {
 					t.Errorf("expected error %v, got %v", tc.expectedErr, err)
@@ -116,7 +174,7 @@ func TestQuoteService(t *testing.T) {
 		for _, tc := range tt {
 			t.Run(tc.name, func(t *testing.T) {
 				service := NewQuoteService(tc.mock)
-				err := service.Delete(tc.inputID)
+				err := service.Delete(ctx, tc.inputID)
 
 				if !errors.Is(err, tc.expectedErr) {
 					t.Errorf("expected error %v, got %v", tc.expectedErr, err)
@@ -151,7 +209,7 @@ func TestQuoteService(t *testing.T) {
 		for _, tc := range tt {
 			t.Run(tc.name, func(t *testing.T) {
 				service := NewQuoteService(tc.mock)
-				result, err := service.List()
+				result, err := service.List(ctx)
 
 				if !errors.Is(err, tc.expectedErr) {
 					t.Errorf("expected error %v, got %v", tc.expectedErr, err)
@@ -179,14 +237,19 @@ func TestQuoteService(t *testing.T) {
 			{
 				name:        "not found",
 				mock:        &mockStorage{getRandomErr: storage.ErrNotFound},
-				expectedErr: storage.ErrNotFound,
+				expectedErr: ErrNotFound,
+			},
+			{
+				name:        "timeout",
+				mock:        &mockStorage{getRandomErr: context.DeadlineExceeded},
+				expectedErr: ErrTimeout,
 			},
 		}
 
 		for _, tc := range tt {
 			t.Run(tc.name, func(t *testing.T) {
 				service := NewQuoteService(tc.mock)
-				result, err := service.GetRandom()
+				result, err := service.GetRandom(ctx)
 
 				if !errors.Is(err, tc.expectedErr) {
 					t.Errorf("expected error %v, got %v", tc.expectedErr, err)
@@ -217,7 +280,7 @@ func TestQuoteService(t *testing.T) {
 			},
 			{
 				name:        "storage error",
-				mock:        &mockStorage{getByAuthorErr: errDB},
+				mock:        &mockStorage{listErr: errDB},
 				inputAuthor: "Error",
 				expectedErr: errDB,
 				expectedArg: "Error",
@@ -227,7 +290,7 @@ func TestQuoteService(t *testing.T) {
 		for _, tc := range tt {
 			t.Run(tc.name, func(t *testing.T) {
 				service := NewQuoteService(tc.mock)
-				result, err := service.GetByAuthor(tc.inputAuthor)
+				result, err := service.GetByAuthor(ctx, tc.inputAuthor)
 
 				if !errors.Is(err, tc.expectedErr) {
 					t.Errorf("expected error %v, got %v", tc.expectedErr, err)
@@ -244,3 +307,544 @@ func TestQuoteService(t *testing.T) {
 		}
 	})
 }
+
+func TestQuoteValidation(t *testing.T) {
+	ctx := context.Background()
+	limits := Limits{MaxAuthorLength: 10, MaxQuoteLength: 20}
+
+	tt := []struct {
+		name       string
+		input      *model.Quote
+		wantAuthor string
+		wantQuote  string
+		wantLang   string
+		wantFields []string
+	}{
+		{
+			name:       "normalises whitespace and NFC",
+			input:      &model.Quote{Author: "  Jose\u0301 ", Quote: "Life \n\t is   simple"},
+			wantAuthor: "Jos\u00e9",
+			wantQuote:  "Life is simple",
+			wantLang:   "en",
+		},
+		{
+			name:       "detects the language when omitted",
+			input:      &model.Quote{Author: "Seneca", Quote: "Knowledge is power"},
+			wantAuthor: "Seneca",
+			wantQuote:  "Knowledge is power",
+			wantLang:   "en",
+		},
+		{
+			name:       "keeps a given language",
+			input:      &model.Quote{Author: "Seneca", Quote: "Knowledge is power", Lang: " LA "},
+			wantAuthor: "Seneca",
+			wantQuote:  "Knowledge is power",
+			wantLang:   "la",
+		},
+		{
+			name:       "rejects languages other than ISO 639-1",
+			input:      &model.Quote{Author: "Seneca", Quote: "Fortes fortuna", Lang: "lat"},
+			wantFields: []string{"lang:invalid"},
+		},
+		{
+			name:       "reports every failing field",
+			input:      &model.Quote{Author: "   ", Quote: "\u0000oops"},
+			wantFields: []string{"author:required", "quote:control_characters"},
+		},
+		{
+			name:       "rejects invalid UTF-8",
+			input:      &model.Quote{Author: "A\xff", Quote: "ok"},
+			wantFields: []string{"author:invalid_encoding"},
+		},
+		{
+			name:       "counts characters not bytes",
+			input:      &model.Quote{Author: "Пушкин", Quote: "Я помню чудное мгновенье"},
+			wantFields: []string{"quote:too_long"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mockStorage{createdQuote: tc.input}
+			service := NewQuoteService(mock, WithLimits(limits))
+			result, err := service.Create(ctx, tc.input)
+
+			if len(tc.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if result.Author != tc.wantAuthor || result.Quote != tc.wantQuote || result.Lang != tc.wantLang {
+					t.Errorf("expected %q/%q/%q, got %q/%q/%q", tc.wantAuthor, tc.wantQuote, tc.wantLang, result.Author, result.Quote, result.Lang)
+				}
+				return
+			}
+
+			var svcErr *Error
+			if !errors.As(err, &svcErr) || !errors.Is(err, ErrValidation) {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+
+			got := make([]string, 0, len(svcErr.Fields))
+			for _, f := range svcErr.Fields {
+				got = append(got, f.Field+":"+f.Code)
+			}
+			if strings.Join(got, ",") != strings.Join(tc.wantFields, ",") {
+				t.Errorf("expected fields %v, got %v", tc.wantFields, got)
+			}
+		})
+	}
+}
+
+func TestQuoteTranslations(t *testing.T) {
+	ctx := context.Background()
+	service := NewQuoteService(storage.NewInMemory(10))
+	original, err := service.Create(ctx, &model.Quote{Author: "Seneca", Quote: "While we wait for life, life passes."})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	added, err := service.AddTranslation(ctx, original.ID, &model.Translation{Lang: " RU ", Quote: " Пока мы  откладываем жизнь, она проходит. ", Translator: "Anon"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.Lang != "ru" || added.Quote != "Пока мы откладываем жизнь, она проходит." || added.CreatedAt.IsZero() {
+		t.Errorf("expected a normalised translation, got %+v", added)
+	}
+
+	tests := []struct {
+		name string
+		in   model.Translation
+		want error
+	}{
+		{name: "duplicate language", in: model.Translation{Lang: "ru", Quote: "Q"}, want: ErrConflict},
+		{name: "language of the original", in: model.Translation{Lang: "en", Quote: "Q"}, want: ErrValidation},
+		{name: "missing fields", in: model.Translation{}, want: ErrValidation},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := service.AddTranslation(ctx, original.ID, &tc.in); !errors.Is(err, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+
+	random, err := service.FindRandom(ctx, storage.QuoteFilter{Lang: "RU"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if random.Quote != added.Quote || random.Lang != "ru" || random.TranslatedFrom != "en" || random.Translator != "Anon" {
+		t.Errorf("expected the quote served in Russian, got %+v", random)
+	}
+	if random, _ := service.FindRandom(ctx, storage.QuoteFilter{Lang: "en"}); random.Quote != original.Quote {
+		t.Errorf("expected the original, got %+v", random)
+	}
+
+	if err := service.DeleteTranslation(ctx, original.ID, "ru"); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.DeleteTranslation(ctx, original.ID, "ru"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := service.FindRandom(ctx, storage.QuoteFilter{Lang: "ru"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestQuotePopularity(t *testing.T) {
+	ctx := context.Background()
+	service := NewQuoteService(storage.NewInMemory(10))
+	for _, text := range []string{"Q1", "Q2"} {
+		if _, err := service.Create(ctx, &model.Quote{Author: "A", Quote: text}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := service.SetLike(ctx, 1, " alice ", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.SetLike(ctx, 1, "bob", true); err != nil {
+		t.Fatal(err)
+	}
+	p, err := service.SetLike(ctx, 1, "alice", true)
+	if err != nil || p.Likes != 2 {
+		t.Errorf("expected one like per user, got %+v, %v", p, err)
+	}
+	if p, _ := service.Rate(ctx, 2, "alice", 5); p.Ratings != 1 || p.AverageRating != 5 {
+		t.Errorf("unexpected popularity %+v", p)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{name: "missing user", call: func() error { _, err := service.SetLike(ctx, 1, " ", true); return err }, want: ErrValidation},
+		{name: "long user", call: func() error { _, err := service.Rate(ctx, 1, strings.Repeat("u", 101), 3); return err }, want: ErrValidation},
+		{name: "rating out of range", call: func() error { _, err := service.Rate(ctx, 1, "alice", 6); return err }, want: ErrValidation},
+		{name: "missing quote", call: func() error { _, err := service.Rate(ctx, 9, "alice", 3); return err }, want: ErrNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.call(); !errors.Is(err, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+
+	ranked, err := service.Top(ctx, 10)
+	if err != nil || len(ranked) != 2 || ranked[0].Quote.ID != 1 || ranked[1].Quote.ID != 2 {
+		t.Errorf("unexpected top quotes %+v, %v", ranked, err)
+	}
+
+	if p, _ := service.Unrate(ctx, 2, "alice"); p.Ratings != 0 {
+		t.Errorf("expected the rating to be removed, got %+v", p)
+	}
+	if p, _ := service.Popularity(ctx, 1); p.Likes != 2 || p.Score != 0 {
+		t.Errorf("unexpected popularity %+v", p)
+	}
+}
+
+func TestQuoteEvents(t *testing.T) {
+	ctx := context.Background()
+	bus := events.NewBus()
+	ch, cancel := bus.Subscribe(10)
+	defer cancel()
+
+	service := NewQuoteService(storage.NewInMemory(2), WithPublisher(bus))
+	for _, text := range []string{"Q1", "Q2", "Q3"} {
+		if _, err := service.Create(ctx, &model.Quote{Author: "A", Quote: text}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := service.Update(ctx, 2, &model.Quote{Author: "A", Quote: "Q2 updated"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Delete(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		typ events.Type
+		id  int
+	}{
+		{events.QuoteCreated, 1},
+		{events.QuoteCreated, 2},
+		{events.QuoteEvicted, 1},
+		{events.QuoteCreated, 3},
+		{events.QuoteUpdated, 2},
+		{events.QuoteDeleted, 3},
+	}
+	for _, w := range want {
+		e := <-ch
+		if e.Type != w.typ || e.QuoteID != w.id || e.Quote.ID != w.id {
+			t.Errorf("expected %s %d, got %+v", w.typ, w.id, e)
+		}
+	}
+}
+
+func TestQuoteModeration(t *testing.T) {
+	ctx := context.Background()
+	bus := events.NewBus()
+	ch, cancel := bus.Subscribe(10)
+	defer cancel()
+
+	service := NewQuoteService(storage.NewInMemory(10), WithModeration("reviewer"), WithPublisher(bus))
+	pending, err := service.Create(ctx, &model.Quote{Author: "A", Quote: "Q1"})
+	if err != nil || pending.Status != model.StatusPending {
+		t.Fatalf("expected a pending quote, got %v, %v", pending, err)
+	}
+	draft, err := service.Create(ctx, &model.Quote{Author: "A", Quote: "Q2", Status: model.StatusDraft})
+	if err != nil || draft.Status != model.StatusDraft {
+		t.Fatalf("expected a draft, got %v, %v", draft, err)
+	}
+	if _, err := service.Create(ctx, &model.Quote{Author: "A", Quote: "Q3", Status: model.StatusPublished}); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation, got %v", err)
+	}
+
+	if quotes, _ := service.GetByAuthor(ctx, "A"); len(quotes) != 0 {
+		t.Errorf("expected unpublished quotes to be hidden, got %v", quotes)
+	}
+	if _, err := service.GetRandom(ctx); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := service.Get(ctx, draft.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected drafts to be hidden by ID, got %v", err)
+	}
+	if got, err := service.GetForReview(ctx, draft.ID); err != nil || got.Status != model.StatusDraft {
+		t.Errorf("expected the draft for review, got %v, %v", got, err)
+	}
+	if _, err := service.SetLike(ctx, pending.ID, "alice", true); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected votes on unpublished quotes to be refused, got %v", err)
+	}
+
+	if _, err := service.Submit(ctx, draft.ID); err != nil {
+		t.Fatal(err)
+	}
+	queue, err := service.Pending(ctx, 0, 10)
+	if err != nil || len(queue) != 2 || queue[0].ID != pending.ID {
+		t.Fatalf("unexpected queue %v, %v", queue, err)
+	}
+
+	approved, err := service.Approve(ctx, pending.ID)
+	if err != nil || approved.Status != model.StatusPublished || approved.ReviewedAt.IsZero() {
+		t.Fatalf("unexpected approval %v, %v", approved, err)
+	}
+	if _, err := service.Reject(ctx, draft.ID, " "); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation, got %v", err)
+	}
+	rejected, err := service.Reject(ctx, draft.ID, "Misattributed")
+	if err != nil || rejected.Status != model.StatusRejected || rejected.RejectReason != "Misattributed" {
+		t.Fatalf("unexpected rejection %v, %v", rejected, err)
+	}
+
+	transitions := []struct {
+		name string
+		call func() error
+	}{
+		{name: "approve published", call: func() error { _, err := service.Approve(ctx, pending.ID); return err }},
+		{name: "reject rejected", call: func() error { _, err := service.Reject(ctx, draft.ID, "again"); return err }},
+		{name: "submit published", call: func() error { _, err := service.Submit(ctx, pending.ID); return err }},
+	}
+	for _, tc := range transitions {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.call(); !errors.Is(err, ErrConflict) {
+				t.Errorf("expected ErrConflict, got %v", err)
+			}
+		})
+	}
+
+	if resubmitted, _ := service.Submit(ctx, draft.ID); resubmitted.Status != model.StatusPending || resubmitted.RejectReason != "" {
+		t.Errorf("expected a pending quote without reason, got %v", resubmitted)
+	}
+	if quotes, _ := service.List(ctx); len(quotes) != 1 || quotes[0].ID != pending.ID {
+		t.Errorf("expected only the approved quote, got %v", quotes)
+	}
+
+	// Only the approval is announced.
+	if e := <-ch; e.Type != events.QuoteCreated || e.QuoteID != pending.ID {
+		t.Errorf("unexpected event %+v", e)
+	}
+	select {
+	case e := <-ch:
+		t.Errorf("unexpected event %+v", e)
+	default:
+	}
+
+	// Edits of published quotes are reviewed again, whichever way they come.
+	edited, err := service.Update(ctx, pending.ID, &model.Quote{Author: "A", Quote: "Q1 rewritten"})
+	if err != nil || edited.Status != model.StatusPending {
+		t.Fatalf("expected the edited quote to await review, got %v, %v", edited, err)
+	}
+	if quotes, _ := service.List(ctx); len(quotes) != 0 {
+		t.Errorf("expected the edited quote to be hidden, got %v", quotes)
+	}
+	if _, err := service.Approve(ctx, pending.ID); err != nil {
+		t.Fatal(err)
+	}
+	results, err := service.Batch(ctx, []BatchOp{{Action: BatchUpdate, ID: pending.ID, Quote: &model.Quote{Author: "A", Quote: "Q1 again"}}}, true)
+	if err != nil || results[0].Quote.Status != model.StatusPending {
+		t.Errorf("expected the batch edit to await review, got %+v, %v", results, err)
+	}
+
+	// Subscribers drop a withdrawn quote until it is approved again.
+	for _, want := range []struct {
+		typ   events.Type
+		quote string
+	}{{events.QuoteDeleted, "Q1"}, {events.QuoteCreated, "Q1 rewritten"}, {events.QuoteDeleted, "Q1 rewritten"}} {
+		if e := <-ch; e.Type != want.typ || e.QuoteID != pending.ID || e.Quote.Quote != want.quote {
+			t.Errorf("expected %s of %q, got %+v", want.typ, want.quote, e)
+		}
+	}
+
+	if err := service.AuthorizeReviewer(""); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+	if err := service.AuthorizeReviewer("wrong"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got %v", err)
+	}
+	if err := service.AuthorizeReviewer("reviewer"); err != nil {
+		t.Errorf("expected the reviewer key to be accepted, got %v", err)
+	}
+}
+
+func TestQuoteContentFilter(t *testing.T) {
+	ctx := context.Background()
+	filter, err := contentfilter.New([]contentfilter.Rule{
+		{Name: "insults", Action: contentfilter.ActionMask, Words: []string{"дурак"}},
+		{Name: "politics", Action: contentfilter.ActionFlag, Words: []string{"выборы"}},
+		{Name: "spam", Action: contentfilter.ActionReject, Patterns: []string{`https?://`}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewQuoteService(storage.NewInMemory(10), WithContentFilter(filter), WithModeration("key"))
+
+	masked, err := service.Create(ctx, &model.Quote{Author: "A", Quote: "Не спорь с дураками"})
+	if err != nil || masked.Quote != "Не спорь с ********" || masked.Status != model.StatusPending || masked.Flags != nil {
+		t.Fatalf("expected a masked quote without flags, got %v, %v", masked, err)
+	}
+	if _, err := service.Approve(ctx, masked.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = service.Create(ctx, &model.Quote{Author: "http://spam", Quote: "Q"})
+	var svcErr *Error
+	if !errors.As(err, &svcErr) || len(svcErr.Fields) != 1 || svcErr.Fields[0].Field != "author" || svcErr.Fields[0].Code != fieldCodeProhibited {
+		t.Errorf("expected the author to be prohibited, got %v", err)
+	}
+
+	flagged, err := service.Update(ctx, masked.ID, &model.Quote{Author: "A", Quote: "Все на выборы"})
+	if err != nil || flagged.Status != model.StatusPending || len(flagged.Flags) != 1 || flagged.Flags[0] != "politics" {
+		t.Fatalf("expected the quote to be held for review, got %v, %v", flagged, err)
+	}
+	approved, err := service.Approve(ctx, masked.ID)
+	if err != nil || approved.Status != model.StatusPublished || approved.Flags != nil {
+		t.Errorf("expected approval to clear the flags, got %v, %v", approved, err)
+	}
+
+	if _, err := service.AddTranslation(ctx, masked.ID, &model.Translation{Lang: "en", Quote: "Go vote: выборы"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected flagged translations to be refused, got %v", err)
+	}
+	unmoderated := NewQuoteService(storage.NewInMemory(10), WithContentFilter(filter))
+	published, err := unmoderated.Create(ctx, &model.Quote{Author: "A", Quote: "Все на выборы"})
+	if err != nil || published.Status != model.StatusPublished || len(published.Flags) != 1 || published.Flags[0] != "politics" {
+		t.Errorf("expected the flagged quote to be published without moderation, got %v, %v", published, err)
+	}
+}
+
+func TestQuoteSchedule(t *testing.T) {
+	ctx := context.Background()
+	bus := events.NewBus()
+	ch, cancel := bus.Subscribe(10)
+	defer cancel()
+
+	service := NewQuoteService(storage.NewInMemory(10), WithPublisher(bus))
+	now := time.Now()
+	if _, err := service.Create(ctx, &model.Quote{Author: "A", Quote: "Q", ExpireAt: now.Add(-time.Minute)}); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation for a past expiry, got %v", err)
+	}
+	if _, err := service.Create(ctx, &model.Quote{Author: "A", Quote: "Q", PublishAt: now.Add(time.Hour), ExpireAt: now.Add(time.Minute)}); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation for an expiry before publication, got %v", err)
+	}
+
+	scheduled, err := service.Create(ctx, &model.Quote{Author: "A", Quote: "Later", PublishAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiring, err := service.Create(ctx, &model.Quote{Author: "A", Quote: "Soon gone", ExpireAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := <-ch; e.Type != events.QuoteCreated || e.QuoteID != expiring.ID {
+		t.Fatalf("expected only the live quote to be announced, got %+v", e)
+	}
+
+	if quotes, _ := service.GetByAuthor(ctx, "A"); len(quotes) != 1 || quotes[0].ID != expiring.ID {
+		t.Errorf("expected the scheduled quote to be hidden, got %v", quotes)
+	}
+	if _, err := service.Get(ctx, scheduled.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound by ID before publication, got %v", err)
+	}
+	if q, err := service.GetRandom(ctx); err != nil || q.ID != expiring.ID {
+		t.Errorf("expected the live quote, got %v, %v", q, err)
+	}
+
+	scheduler := NewScheduler(service, logger.New("error", "console"), time.Hour)
+	scheduler.last = now
+	scheduler.tick(now.Add(2 * time.Hour))
+
+	for _, want := range []struct {
+		typ events.Type
+		id  int
+	}{{events.QuotePublished, scheduled.ID}, {events.QuoteExpired, expiring.ID}} {
+		if e := <-ch; e.Type != want.typ || e.QuoteID != want.id {
+			t.Errorf("expected %s of quote %d, got %+v", want.typ, want.id, e)
+		}
+	}
+	if _, err := service.Get(ctx, expiring.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the expired quote to be purged, got %v", err)
+	}
+
+	if err := scheduler.Stop(ctx); err != nil {
+		t.Errorf("unexpected error stopping the scheduler: %v", err)
+	}
+}
+
+func TestQuoteBatch(t *testing.T) {
+	ctx := context.Background()
+	bus := events.NewBus()
+	ch, cancel := bus.Subscribe(10)
+	defer cancel()
+
+	var removed []int
+	service := NewQuoteService(storage.NewInMemory(10), WithPublisher(bus), WithMaxBatchSize(3),
+		WithRemovalHook(func(_ context.Context, ids []int) { removed = append(removed, ids...) }))
+	existing, err := service.Create(ctx, &model.Quote{Author: "A", Quote: "Q"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-ch
+
+	newQuote := func(text string) *model.Quote { return &model.Quote{Author: "B", Quote: text} }
+
+	if _, err := service.Batch(ctx, make([]BatchOp, 4), true); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation for a batch over the limit, got %v", err)
+	}
+
+	_, err = service.Batch(ctx, []BatchOp{
+		{Action: BatchCreate, TempID: "a", Quote: newQuote("one")},
+		{Action: BatchCreate, TempID: "a", Quote: &model.Quote{Quote: "two"}},
+		{Action: BatchUpdate, TempID: "b", Quote: newQuote("three")},
+	}, true)
+	var svcErr *Error
+	if !errors.As(err, &svcErr) || len(svcErr.Fields) != 3 || svcErr.Fields[0].Field != "operations[1].temp_id" ||
+		svcErr.Fields[1].Field != "operations[1].quote.author" || svcErr.Fields[2].Field != "operations[2].temp_id" {
+		t.Fatalf("expected prefixed field errors, got %v", err)
+	}
+
+	_, err = service.Batch(ctx, []BatchOp{
+		{Action: BatchCreate, Quote: newQuote("one")},
+		{Action: BatchDelete, ID: 99},
+	}, true)
+	if !errors.Is(err, ErrNotFound) || !strings.HasPrefix(err.Error(), "not_found: operations[1]") {
+		t.Fatalf("expected ErrNotFound naming the operation, got %v", err)
+	}
+	if quotes, _ := service.List(ctx); len(quotes) != 1 {
+		t.Errorf("expected the failed batch to be undone, got %v", quotes)
+	}
+
+	results, err := service.Batch(ctx, []BatchOp{
+		{Action: BatchCreate, TempID: "a", Quote: newQuote("one")},
+		{Action: BatchUpdate, TempID: "a", Quote: newQuote("one, edited")},
+		{Action: BatchDelete, ID: existing.ID},
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := results[1].Quote; q.ID != results[0].Quote.ID || q.Quote != "one, edited" {
+		t.Errorf("expected the temporary ID to address the created quote, got %+v", q)
+	}
+	for _, want := range []events.Type{events.QuoteCreated, events.QuoteUpdated, events.QuoteDeleted} {
+		if e := <-ch; e.Type != want {
+			t.Errorf("expected %s, got %+v", want, e)
+		}
+	}
+	if len(removed) != 1 || removed[0] != existing.ID {
+		t.Errorf("expected the removal hook for the deleted quote, got %v", removed)
+	}
+
+	results, err = service.Batch(ctx, []BatchOp{
+		{Action: BatchCreate, TempID: "c", Quote: &model.Quote{Author: "B"}},
+		{Action: BatchDelete, TempID: "c"},
+		{Action: BatchCreate, Quote: newQuote("two")},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(results[0].Err, ErrValidation) || !errors.Is(results[1].Err, ErrNotFound) || results[2].Err != nil {
+		t.Errorf("expected per operation results, got %+v", results)
+	}
+	if quotes, _ := service.List(ctx); len(quotes) != 2 {
+		t.Errorf("expected the valid operation to be applied, got %v", quotes)
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const DefaultScheduleInterval = 5 * time.Second
+
+// Scheduler announces quotes whose publication window started and purges
+// those whose window ended. Reads check the window themselves, so the
+// interval only delays the events and the purge.
+type Scheduler struct {
+	quotes      *QuoteService
+	collections *CollectionService
+	logger      *logger.Logger
+	interval    time.Duration
+	// last is the end of the previous window, quotes published up to it
+	// have been announced.
+	last time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+type SchedulerOption func(*Scheduler)
+
+// WithCollectionSchedules makes the scheduler run over the quotes of every
+// collection as well.
+func WithCollectionSchedules(c *CollectionService) SchedulerOption {
+	return func(s *Scheduler) {
+		s.collections = c
+	}
+}
+
+func NewScheduler(quotes *QuoteService, logger *logger.Logger, interval time.Duration, opts ...SchedulerOption) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	if interval <= 0 {
+		interval = DefaultScheduleInterval
+	}
+	s := &Scheduler{
+		quotes:   quotes,
+		logger:   logger,
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Start runs the scheduler in the background until Stop.
+func (s *Scheduler) Start() {
+	s.last = time.Now().UTC()
+	s.done.Add(1)
+	go func() {
+		defer s.done.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.tick(now.UTC())
+			}
+		}
+	}()
+}
+
+// Stop ends the scheduler and waits for a running pass until ctx is done.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.done.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tick announces the quotes published since the previous pass and purges
+// the expired ones, of the default collection and of every other one. A
+// failed pass is repeated over a longer window on the next tick, which may
+// announce quotes of the other collections again.
+func (s *Scheduler) tick(now time.Time) {
+	quotes := []*QuoteService{s.quotes}
+	if s.collections != nil {
+		scoped, err := s.collections.quoteServices(s.ctx)
+		if err != nil {
+			s.logger.Error().Err(err).Msg("failed to load collections")
+			return
+		}
+		quotes = append(quotes, scoped...)
+	}
+
+	ok := true
+	for _, q := range quotes {
+		ok = s.pass(q, now) && ok
+	}
+	if ok {
+		s.last = now
+	}
+}
+
+// pass runs a tick over the quotes of one service and reports whether the
+// scheduled ones were announced.
+func (s *Scheduler) pass(quotes *QuoteService, now time.Time) bool {
+	published, err := quotes.store.ScheduledQuotes(s.ctx, s.last, now)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to load scheduled quotes")
+		return false
+	}
+	for _, q := range published {
+		quotes.publish(events.QuotePublished, q)
+	}
+
+	expired, err := quotes.store.PurgeExpired(s.ctx, now)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to purge expired quotes")
+		return true
+	}
+	ids := make([]int, 0, len(expired))
+	for _, q := range expired {
+		ids = append(ids, q.ID)
+		quotes.publish(events.QuoteExpired, q)
+	}
+	quotes.removed(s.ctx, ids...)
+	if len(expired) > 0 {
+		s.logger.Info().Int("count", len(expired)).Msg("expired quotes purged")
+	}
+	return true
+}
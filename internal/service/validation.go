@@ -0,0 +1,238 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/langdetect"
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+)
+
+const (
+	DefaultMaxAuthorLength = 200
+	DefaultMaxQuoteLength  = 2000
+	DefaultMaxTags         = 10
+	DefaultMaxTagLength    = 50
+)
+
+const (
+	fieldCodeRequired        = "required"
+	fieldCodeTooLong         = "too_long"
+	fieldCodeInvalidEncoding = "invalid_encoding"
+	fieldCodeControlChars    = "control_characters"
+	fieldCodeTooMany         = "too_many"
+	fieldCodeProhibited      = "prohibited_content"
+)
+
+// Limits bounds the length of quote fields in characters (runes) measured
+// after normalisation.
+type Limits struct {
+	MaxAuthorLength int
+	MaxQuoteLength  int
+	MaxTags         int
+	MaxTagLength    int
+}
+
+func DefaultLimits() Limits {
+	return Limits{
+		MaxAuthorLength: DefaultMaxAuthorLength,
+		MaxQuoteLength:  DefaultMaxQuoteLength,
+		MaxTags:         DefaultMaxTags,
+		MaxTagLength:    DefaultMaxTagLength,
+	}
+}
+
+// normalizeQuote validates author and quote, rewrites them to their
+// normalised form and reports every failing field at once.
+func (s *QuoteService) normalizeQuote(q *model.Quote) error {
+	var fields []FieldError
+
+	author, fieldErr := normalizeField("author", q.Author, s.limits.MaxAuthorLength)
+	if fieldErr != nil {
+		fields = append(fields, *fieldErr)
+	}
+
+	text, fieldErr := normalizeField("quote", q.Quote, s.limits.MaxQuoteLength)
+	if fieldErr != nil {
+		fields = append(fields, *fieldErr)
+	}
+
+	tags, tagErrs := s.normalizeTags(q.Tags)
+	fields = append(fields, tagErrs...)
+
+	lang, ok := ParseLang(q.Lang)
+	if q.Lang != "" && !ok {
+		fields = append(fields, FieldError{Field: "lang", Code: fieldCodeInvalid, Message: "must be an ISO 639-1 language code"})
+	}
+
+	if !q.ExpireAt.IsZero() {
+		switch {
+		case !q.ExpireAt.After(q.PublishAt):
+			fields = append(fields, FieldError{Field: "expire_at", Code: fieldCodeInvalid, Message: "must be after publish_at"})
+		case !q.ExpireAt.After(time.Now()):
+			fields = append(fields, FieldError{Field: "expire_at", Code: fieldCodeInvalid, Message: "must be in the future"})
+		}
+	}
+
+	if len(fields) > 0 {
+		return NewValidationError(fields...)
+	}
+
+	// Undetected languages stay empty, such quotes only match unfiltered
+	// queries.
+	if lang == "" {
+		lang = langdetect.Detect(text)
+	}
+
+	q.Author = author
+	q.Quote = text
+	q.Tags = tags
+	q.Lang = lang
+	q.PublishAt = q.PublishAt.UTC()
+	q.ExpireAt = q.ExpireAt.UTC()
+	// Translations are managed with AddTranslation, the rest is only set on
+	// localized copies.
+	q.Translations = nil
+	q.TranslatedFrom = ""
+	q.Translator = ""
+	// Reviews are recorded by Approve and Reject only, flags by the
+	// content filter.
+	q.RejectReason = ""
+	q.ReviewedAt = time.Time{}
+	q.Flags = nil
+	return nil
+}
+
+// normalizeTranslation validates a translation like the quote it belongs
+// to, its language is required rather than detected.
+func (s *QuoteService) normalizeTranslation(t *model.Translation) error {
+	var fields []FieldError
+
+	lang, ok := ParseLang(t.Lang)
+	switch {
+	case strings.TrimSpace(t.Lang) == "":
+		fields = append(fields, FieldError{Field: "lang", Code: fieldCodeRequired, Message: "must be non-empty"})
+	case !ok:
+		fields = append(fields, FieldError{Field: "lang", Code: fieldCodeInvalid, Message: "must be an ISO 639-1 language code"})
+	}
+
+	text, fieldErr := normalizeField("quote", t.Quote, s.limits.MaxQuoteLength)
+	if fieldErr != nil {
+		fields = append(fields, *fieldErr)
+	}
+
+	var translator string
+	if strings.TrimSpace(t.Translator) != "" {
+		translator, fieldErr = normalizeField("translator", t.Translator, s.limits.MaxAuthorLength)
+		if fieldErr != nil {
+			fields = append(fields, *fieldErr)
+		}
+	}
+
+	if len(fields) > 0 {
+		return NewValidationError(fields...)
+	}
+
+	t.Lang = lang
+	t.Quote = text
+	t.Translator = translator
+	return nil
+}
+
+// ParseLang returns the lower-case ISO 639-1 code of lang, accepting any
+// case and surrounding spaces. It reports false for anything else,
+// including three-letter codes and region subtags.
+func ParseLang(lang string) (string, bool) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if len(lang) != 2 {
+		return "", false
+	}
+	base, err := language.ParseBase(lang)
+	if err != nil || base.String() != lang {
+		return "", false
+	}
+	return lang, true
+}
+
+// normalizeTags lower-cases tags and drops duplicates, keeping input order.
+func (s *QuoteService) normalizeTags(tags []string) ([]string, []FieldError) {
+	if s.limits.MaxTags > 0 && len(tags) > s.limits.MaxTags {
+		return nil, []FieldError{{
+			Field:   "tags",
+			Code:    fieldCodeTooMany,
+			Message: fmt.Sprintf("must contain at most %d tags", s.limits.MaxTags),
+		}}
+	}
+
+	var fields []FieldError
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+
+	for i, tag := range tags {
+		tag, fieldErr := normalizeField(fmt.Sprintf("tags[%d]", i), tag, s.limits.MaxTagLength)
+		if fieldErr != nil {
+			fields = append(fields, *fieldErr)
+			continue
+		}
+
+		tag = NormalizeTag(tag)
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+
+	if len(normalized) == 0 {
+		normalized = nil
+	}
+	return normalized, fields
+}
+
+// NormalizeTag brings a tag to the form it is stored and filtered in.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(normalizeText(tag))
+}
+
+// NormalizeAuthor brings an author name to the form it is stored in.
+func NormalizeAuthor(author string) string {
+	return normalizeText(author)
+}
+
+// normalizeField rejects invalid UTF-8 and control characters, then applies
+// NFC and collapses whitespace runs into single spaces.
+func normalizeField(name, value string, maxLength int) (string, *FieldError) {
+	if !utf8.ValidString(value) || strings.ContainsRune(value, utf8.RuneError) {
+		return "", &FieldError{Field: name, Code: fieldCodeInvalidEncoding, Message: "must be valid UTF-8"}
+	}
+
+	for _, r := range value {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return "", &FieldError{Field: name, Code: fieldCodeControlChars, Message: "must not contain control characters"}
+		}
+	}
+
+	value = normalizeText(value)
+	if value == "" {
+		return "", &FieldError{Field: name, Code: fieldCodeRequired, Message: "must be non-empty"}
+	}
+
+	if maxLength > 0 && utf8.RuneCountInString(value) > maxLength {
+		return "", &FieldError{
+			Field:   name,
+			Code:    fieldCodeTooLong,
+			Message: fmt.Sprintf("must be at most %d characters", maxLength),
+		}
+	}
+
+	return value, nil
+}
+
+func normalizeText(value string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(value)), " ")
+}
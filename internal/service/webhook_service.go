@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const (
+	maxWebhookURLLength = 2048
+	minSecretLength     = 16
+	maxSecretLength     = 256
+
+	fieldCodeInvalid  = "invalid"
+	fieldCodeTooShort = "too_short"
+)
+
+// webhookEvents are the event types a webhook may subscribe to.
+var webhookEvents = map[string]bool{
+	string(events.QuoteCreated):   true,
+	string(events.QuoteUpdated):   true,
+	string(events.QuoteDeleted):   true,
+	string(events.QuoteEvicted):   true,
+	string(events.QuotePublished): true,
+	string(events.QuoteExpired):   true,
+}
+
+type Webhook interface {
+	Create(ctx context.Context, w *model.Webhook) (*model.Webhook, error)
+	List(ctx context.Context) ([]*model.Webhook, error)
+	Get(ctx context.Context, id int) (*model.Webhook, error)
+	Update(ctx context.Context, id int, w *model.Webhook) (*model.Webhook, error)
+	Delete(ctx context.Context, id int) error
+	ListDeadLetters(ctx context.Context) ([]*model.DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, id int) error
+	DeleteDeadLetter(ctx context.Context, id int) error
+	AuthorizeAdmin(key string) error
+}
+
+// Replayer queues a dead letter for another delivery attempt, implemented
+// by webhook.Dispatcher.
+type Replayer interface {
+	Replay(d *model.DeadLetter) error
+}
+
+type WebhookService struct {
+	store    storage.WebhookStorage
+	replayer Replayer
+	adminKey string
+}
+
+type WebhookOption func(*WebhookService)
+
+// WithWebhookAdminKey sets the key allowed to manage webhooks and dead
+// letters. Without it management is refused: webhooks choose where the
+// service sends requests and dead letters hold delivered payloads.
+func WithWebhookAdminKey(key string) WebhookOption {
+	return func(s *WebhookService) {
+		s.adminKey = key
+	}
+}
+
+func NewWebhookService(store storage.WebhookStorage, replayer Replayer, opts ...WebhookOption) *WebhookService {
+	s := &WebhookService{
+		store:    store,
+		replayer: replayer,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Create validates w and generates a secret unless one is given. The secret
+// is returned only here, reads hide it.
+func (s *WebhookService) Create(ctx context.Context, w *model.Webhook) (*model.Webhook, error) {
+	if err := validateWebhook(w); err != nil {
+		return nil, err
+	}
+
+	if w.Secret == "" {
+		w.Secret = newSecret()
+	}
+	w.CreatedAt = time.Now().UTC()
+
+	created, err := s.store.CreateWebhook(ctx, w)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	logger.FromContext(ctx, nil).Debug().Int("id", created.ID).Str("url", created.URL).Msg("webhook created")
+
+	result := *created
+	return &result, nil
+}
+
+func (s *WebhookService) List(ctx context.Context) ([]*model.Webhook, error) {
+	webhooks, err := s.store.GetWebhooks(ctx)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	result := make([]*model.Webhook, 0, len(webhooks))
+	for _, w := range webhooks {
+		result = append(result, withoutSecret(w))
+	}
+	return result, nil
+}
+
+func (s *WebhookService) Get(ctx context.Context, id int) (*model.Webhook, error) {
+	w, err := s.store.GetWebhookByID(ctx, id)
+	if err != nil {
+		return nil, wrapWebhookError(err)
+	}
+	return withoutSecret(w), nil
+}
+
+// Update replaces URL and events of a webhook, the secret is rotated only
+// when a new one is given.
+func (s *WebhookService) Update(ctx context.Context, id int, w *model.Webhook) (*model.Webhook, error) {
+	if err := validateWebhook(w); err != nil {
+		return nil, err
+	}
+
+	current, err := s.store.GetWebhookByID(ctx, id)
+	if err != nil {
+		return nil, wrapWebhookError(err)
+	}
+
+	w.ID = id
+	w.CreatedAt = current.CreatedAt
+	if w.Secret == "" {
+		w.Secret = current.Secret
+	}
+
+	updated, err := s.store.UpdateWebhook(ctx, w)
+	if err != nil {
+		return nil, wrapWebhookError(err)
+	}
+
+	logger.FromContext(ctx, nil).Debug().Int("id", id).Msg("webhook updated")
+	return withoutSecret(updated), nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id int) error {
+	if err := s.store.DeleteWebhook(ctx, id); err != nil {
+		return wrapWebhookError(err)
+	}
+
+	logger.FromContext(ctx, nil).Debug().Int("id", id).Msg("webhook deleted")
+	return nil
+}
+
+func (s *WebhookService) ListDeadLetters(ctx context.Context) ([]*model.DeadLetter, error) {
+	deadLetters, err := s.store.GetDeadLetters(ctx)
+	return deadLetters, wrapError(err)
+}
+
+// ReplayDeadLetter queues the delivery again and removes it from the list,
+// it comes back with a fresh attempt count if it fails again.
+func (s *WebhookService) ReplayDeadLetter(ctx context.Context, id int) error {
+	d, err := s.store.GetDeadLetterByID(ctx, id)
+	if err != nil {
+		return wrapDeadLetterError(err)
+	}
+
+	if _, err := s.store.GetWebhookByID(ctx, d.WebhookID); err != nil {
+		return wrapWebhookError(err)
+	}
+
+	if err := s.replayer.Replay(d); err != nil {
+		return &Error{Code: CodeUnavailable, Detail: "delivery queue is unavailable", Err: err}
+	}
+
+	if err := s.store.DeleteDeadLetter(ctx, id); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return wrapError(err)
+	}
+
+	logger.FromContext(ctx, nil).Debug().Int("id", id).Int("webhook_id", d.WebhookID).Msg("dead letter replayed")
+	return nil
+}
+
+func (s *WebhookService) DeleteDeadLetter(ctx context.Context, id int) error {
+	return wrapDeadLetterError(s.store.DeleteDeadLetter(ctx, id))
+}
+
+func (s *WebhookService) AuthorizeAdmin(key string) error {
+	return authorizeKey(key, s.adminKey, "admin API key required")
+}
+
+func validateWebhook(w *model.Webhook) error {
+	var fields []FieldError
+
+	switch u, err := url.Parse(w.URL); {
+	case w.URL == "":
+		fields = append(fields, FieldError{Field: "url", Code: fieldCodeRequired, Message: "must be non-empty"})
+	case len(w.URL) > maxWebhookURLLength:
+		fields = append(fields, FieldError{
+			Field:   "url",
+			Code:    fieldCodeTooLong,
+			Message: fmt.Sprintf("must be at most %d characters", maxWebhookURLLength),
+		})
+	case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+		fields = append(fields, FieldError{Field: "url", Code: fieldCodeInvalid, Message: "must be an absolute http or https URL"})
+	}
+
+	for i, e := range w.Events {
+		if !webhookEvents[e] {
+			fields = append(fields, FieldError{
+				Field:   fmt.Sprintf("events[%d]", i),
+				Code:    fieldCodeInvalid,
+				Message: "must be one of created, updated, deleted, evicted, published, expired",
+			})
+		}
+	}
+
+	if n := utf8.RuneCountInString(w.Secret); w.Secret != "" && n < minSecretLength {
+		fields = append(fields, FieldError{
+			Field:   "secret",
+			Code:    fieldCodeTooShort,
+			Message: fmt.Sprintf("must be at least %d characters", minSecretLength),
+		})
+	} else if n > maxSecretLength {
+		fields = append(fields, FieldError{
+			Field:   "secret",
+			Code:    fieldCodeTooLong,
+			Message: fmt.Sprintf("must be at most %d characters", maxSecretLength),
+		})
+	}
+
+	if len(fields) > 0 {
+		return NewValidationError(fields...)
+	}
+	return nil
+}
+
+func withoutSecret(w *model.Webhook) *model.Webhook {
+	result := *w
+	result.Secret = ""
+	return &result
+}
+
+func newSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func wrapWebhookError(err error) error {
+	if errors.Is(err, storage.ErrNotFound) {
+		return NewNotFoundError("webhook not found", err)
+	}
+	return wrapError(err)
+}
+
+func wrapDeadLetterError(err error) error {
+	if errors.Is(err, storage.ErrNotFound) {
+		return NewNotFoundError("dead letter not found", err)
+	}
+	return wrapError(err)
+}
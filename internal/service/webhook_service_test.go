@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+)
+
+type mockReplayer struct {
+	replayed []*model.DeadLetter
+	err      error
+}
+
+func (m *mockReplayer) Replay(d *model.DeadLetter) error {
+	m.replayed = append(m.replayed, d)
+	return m.err
+}
+
+func TestWebhookValidation(t *testing.T) {
+	ctx := context.Background()
+
+	tt := []struct {
+		name       string
+		input      *model.Webhook
+		wantFields []string
+	}{
+		{
+			name:  "accepts a valid webhook",
+			input: &model.Webhook{URL: "https://example.com/hook", Events: []string{"created", "evicted"}},
+		},
+		{
+			name:       "requires an absolute http URL",
+			input:      &model.Webhook{URL: "ftp://example.com"},
+			wantFields: []string{"url:invalid"},
+		},
+		{
+			name:       "rejects unknown events and short secrets",
+			input:      &model.Webhook{URL: "http://x", Events: []string{"created", "liked"}, Secret: "short"},
+			wantFields: []string{"events[1]:invalid", "secret:too_short"},
+		},
+		{
+			name:       "requires a URL",
+			input:      &model.Webhook{},
+			wantFields: []string{"url:required"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewWebhookService(storage.NewWebhookInMemory(10), &mockReplayer{})
+			created, err := svc.Create(ctx, tc.input)
+
+			if len(tc.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(created.Secret) != 64 {
+					t.Errorf("expected generated secret, got %q", created.Secret)
+				}
+				return
+			}
+
+			var svcErr *Error
+			if !errors.As(err, &svcErr) || !errors.Is(err, ErrValidation) {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+
+			got := make([]string, 0, len(svcErr.Fields))
+			for _, f := range svcErr.Fields {
+				got = append(got, f.Field+":"+f.Code)
+			}
+			if strings.Join(got, ",") != strings.Join(tc.wantFields, ",") {
+				t.Errorf("expected fields %v, got %v", tc.wantFields, got)
+			}
+		})
+	}
+}
+
+func TestWebhookService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Reads hide the secret and updates keep it", func(t *testing.T) {
+		store := storage.NewWebhookInMemory(10)
+		svc := NewWebhookService(store, &mockReplayer{})
+
+		created, _ := svc.Create(ctx, &model.Webhook{URL: "http://a", Secret: "0123456789abcdef"})
+		got, err := svc.Get(ctx, created.ID)
+		if err != nil || got.Secret != "" {
+			t.Errorf("expected secret to be hidden, got %+v, %v", got, err)
+		}
+
+		if _, err := svc.Update(ctx, created.ID, &model.Webhook{URL: "http://b"}); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		stored, _ := store.GetWebhookByID(ctx, created.ID)
+		if stored.URL != "http://b" || stored.Secret != "0123456789abcdef" {
+			t.Errorf("unexpected stored webhook %+v", stored)
+		}
+
+		if err := svc.Delete(ctx, 42); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected not found, got %v", err)
+		}
+	})
+
+	t.Run("ReplayDeadLetter", func(t *testing.T) {
+		store := storage.NewWebhookInMemory(10)
+		w, _ := store.CreateWebhook(ctx, &model.Webhook{URL: "http://a"})
+		dl, _ := store.AddDeadLetter(ctx, &model.DeadLetter{WebhookID: w.ID})
+		orphan, _ := store.AddDeadLetter(ctx, &model.DeadLetter{WebhookID: 99})
+
+		full := &mockReplayer{err: errors.New("queue full")}
+		if err := NewWebhookService(store, full).ReplayDeadLetter(ctx, dl.ID); !errors.Is(err, ErrUnavailable) {
+			t.Errorf("expected unavailable, got %v", err)
+		}
+
+		replayer := &mockReplayer{}
+		svc := NewWebhookService(store, replayer)
+		if err := svc.ReplayDeadLetter(ctx, dl.ID); err != nil {
+			t.Fatalf("replay: %v", err)
+		}
+		if len(replayer.replayed) != 1 {
+			t.Errorf("expected dead letter to be replayed, got %d", len(replayer.replayed))
+		}
+		if _, err := store.GetDeadLetterByID(ctx, dl.ID); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("expected replayed dead letter to be removed, got %v", err)
+		}
+
+		if err := svc.ReplayDeadLetter(ctx, orphan.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected not found for deleted webhook, got %v", err)
+		}
+	})
+}
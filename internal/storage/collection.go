@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+)
+
+var ErrAlreadyExists = fmt.Errorf("already exists")
+
+// CollectionStorage scopes quote storage by collection. Every collection
+// has its own QuoteStorage, so IDs and limits never interfere, and the API
+// keys issued for it.
+type CollectionStorage interface {
+	CreateCollection(ctx context.Context, c *model.Collection) (*model.Collection, error)
+	GetCollections(ctx context.Context) ([]*model.Collection, error)
+	GetCollection(ctx context.Context, name string) (*model.Collection, QuoteStorage, error)
+	DeleteCollection(ctx context.Context, name string) error
+
+	CreateAPIKey(ctx context.Context, k *model.APIKey) (*model.APIKey, error)
+	GetAPIKeys(ctx context.Context, collection string) ([]*model.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (*model.APIKey, error)
+	DeleteAPIKey(ctx context.Context, collection string, id int) error
+}
+
+type collection struct {
+	meta   *model.Collection
+	quotes *MemoryStorage
+}
+
+type MemoryCollectionStorage struct {
+	mu          sync.RWMutex
+	collections map[string]*collection
+	keys        map[int]*model.APIKey
+	nextKeyID   int
+}
+
+func NewCollectionInMemory() *MemoryCollectionStorage {
+	return &MemoryCollectionStorage{
+		collections: make(map[string]*collection),
+		keys:        make(map[int]*model.APIKey),
+		nextKeyID:   1,
+	}
+}
+
+// CreateCollection adds an empty collection holding up to c.QuotesLimit
+// quotes, names are unique.
+func (r *MemoryCollectionStorage) CreateCollection(ctx context.Context, c *model.Collection) (*model.Collection, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.collections[c.Name]; ok {
+		return nil, ErrAlreadyExists
+	}
+	r.collections[c.Name] = &collection{meta: c, quotes: NewInMemory(c.QuotesLimit)}
+
+	return c, nil
+}
+
+func (r *MemoryCollectionStorage) GetCollections(ctx context.Context) ([]*model.Collection, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*model.Collection, 0, len(r.collections))
+	for _, c := range r.collections {
+		result = append(result, c.meta)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+func (r *MemoryCollectionStorage) GetCollection(ctx context.Context, name string) (*model.Collection, QuoteStorage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.collections[name]
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+	return c.meta, c.quotes, nil
+}
+
+// DeleteCollection drops the collection with its quotes and API keys.
+func (r *MemoryCollectionStorage) DeleteCollection(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.collections[name]; !ok {
+		return ErrNotFound
+	}
+	delete(r.collections, name)
+
+	for id, k := range r.keys {
+		if k.Collection == name {
+			delete(r.keys, id)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryCollectionStorage) CreateAPIKey(ctx context.Context, k *model.APIKey) (*model.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.collections[k.Collection]; !ok {
+		return nil, ErrNotFound
+	}
+
+	k.ID = r.nextKeyID
+	r.keys[k.ID] = k
+	r.nextKeyID++
+
+	return k, nil
+}
+
+func (r *MemoryCollectionStorage) GetAPIKeys(ctx context.Context, collection string) ([]*model.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.collections[collection]; !ok {
+		return nil, ErrNotFound
+	}
+
+	result := make([]*model.APIKey, 0)
+	for _, k := range r.keys {
+		if k.Collection == collection {
+			result = append(result, k)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+
+	return result, nil
+}
+
+func (r *MemoryCollectionStorage) GetAPIKeyByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, k := range r.keys {
+		if k.Hash == hash {
+			return k, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryCollectionStorage) DeleteAPIKey(ctx context.Context, collection string, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k, ok := r.keys[id]
+	if !ok || k.Collection != collection {
+		return ErrNotFound
+	}
+	delete(r.keys, id)
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+)
+
+func TestMemoryCollectionStorage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Collections have isolated quotes and limits", func(t *testing.T) {
+		s := NewCollectionInMemory()
+
+		_, _ = s.CreateCollection(ctx, &model.Collection{Name: "b", QuotesLimit: 1})
+		_, _ = s.CreateCollection(ctx, &model.Collection{Name: "a", QuotesLimit: 10})
+		if _, err := s.CreateCollection(ctx, &model.Collection{Name: "a"}); !errors.Is(err, ErrAlreadyExists) {
+			t.Errorf("expected ErrAlreadyExists, got %v", err)
+		}
+
+		list, _ := s.GetCollections(ctx)
+		if len(list) != 2 || list[0].Name != "a" || list[1].Name != "b" {
+			t.Errorf("unexpected collections %+v", list)
+		}
+
+		_, a, _ := s.GetCollection(ctx, "a")
+		_, b, _ := s.GetCollection(ctx, "b")
+		for i := 0; i < 2; i++ {
+			_, _, _ = a.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "a"})
+			_, _, _ = b.CreateQuote(ctx, &model.Quote{Author: "B", Quote: "b"})
+		}
+
+		quotesA, _ := a.FindQuotes(ctx, QuoteFilter{Limit: 10})
+		quotesB, _ := b.FindQuotes(ctx, QuoteFilter{Limit: 10})
+		if len(quotesA) != 2 || quotesA[0].ID != 1 {
+			t.Errorf("unexpected quotes in a %+v", quotesA)
+		}
+		if len(quotesB) != 1 || quotesB[0].ID != 2 || quotesB[0].Author != "B" {
+			t.Errorf("expected b to keep only its newest quote, got %+v", quotesB)
+		}
+
+		if _, _, err := s.GetCollection(ctx, "c"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("API keys belong to one collection", func(t *testing.T) {
+		s := NewCollectionInMemory()
+		_, _ = s.CreateCollection(ctx, &model.Collection{Name: "a", QuotesLimit: 10})
+		_, _ = s.CreateCollection(ctx, &model.Collection{Name: "b", QuotesLimit: 10})
+
+		if _, err := s.CreateAPIKey(ctx, &model.APIKey{Collection: "c", Hash: "x"}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for a missing collection, got %v", err)
+		}
+		_, _ = s.CreateAPIKey(ctx, &model.APIKey{Collection: "a", Hash: "ha"})
+		_, _ = s.CreateAPIKey(ctx, &model.APIKey{Collection: "b", Hash: "hb"})
+
+		k, err := s.GetAPIKeyByHash(ctx, "hb")
+		if err != nil || k.Collection != "b" || k.ID != 2 {
+			t.Fatalf("unexpected key %+v, %v", k, err)
+		}
+
+		if err := s.DeleteAPIKey(ctx, "a", 2); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound deleting a key of another collection, got %v", err)
+		}
+
+		if err := s.DeleteCollection(ctx, "b"); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := s.GetAPIKeyByHash(ctx, "hb"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected keys of a deleted collection to be gone, got %v", err)
+		}
+		keys, _ := s.GetAPIKeys(ctx, "a")
+		if len(keys) != 1 || keys[0].Hash != "ha" {
+			t.Errorf("unexpected keys %+v", keys)
+		}
+	})
+}
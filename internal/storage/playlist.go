@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+)
+
+type PlaylistStorage interface {
+	CreatePlaylist(ctx context.Context, p *model.Playlist) (*model.Playlist, error)
+	GetPlaylists(ctx context.Context) ([]*model.Playlist, error)
+	GetPlaylistByID(ctx context.Context, id int) (*model.Playlist, error)
+	ModifyPlaylist(ctx context.Context, id int, modify func(p *model.Playlist) error) (*model.Playlist, error)
+	DeletePlaylist(ctx context.Context, id int) error
+	RemoveQuotes(ctx context.Context, quoteIDs ...int) error
+}
+
+// MemoryPlaylistStorage keeps playlists in memory. Stored playlists are
+// shared with readers and replaced, never changed in place.
+type MemoryPlaylistStorage struct {
+	mu        sync.RWMutex
+	playlists map[int]*model.Playlist
+	nextID    int
+}
+
+func NewPlaylistInMemory() *MemoryPlaylistStorage {
+	return &MemoryPlaylistStorage{
+		playlists: make(map[int]*model.Playlist),
+		nextID:    1,
+	}
+}
+
+func (r *MemoryPlaylistStorage) CreatePlaylist(ctx context.Context, p *model.Playlist) (*model.Playlist, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p.ID = r.nextID
+	r.playlists[p.ID] = p
+	r.nextID++
+
+	return p, nil
+}
+
+// GetPlaylists returns every playlist in ascending ID order.
+func (r *MemoryPlaylistStorage) GetPlaylists(ctx context.Context) ([]*model.Playlist, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	playlists := make([]*model.Playlist, 0, len(r.playlists))
+	for _, p := range r.playlists {
+		playlists = append(playlists, p)
+	}
+
+	sort.Slice(playlists, func(i, j int) bool {
+		return playlists[i].ID < playlists[j].ID
+	})
+	return playlists, nil
+}
+
+func (r *MemoryPlaylistStorage) GetPlaylistByID(ctx context.Context, id int) (*model.Playlist, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.playlists[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return p, nil
+}
+
+// ModifyPlaylist applies modify to a copy of the stored playlist and stores
+// the result unless modify fails, whose error is then returned as is. The
+// lock is held throughout, so modify sees no concurrent changes and must
+// not call back into the storage.
+func (r *MemoryPlaylistStorage) ModifyPlaylist(ctx context.Context, id int, modify func(p *model.Playlist) error) (*model.Playlist, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.playlists[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	updated := *current
+	updated.QuoteIDs = slices.Clone(current.QuoteIDs)
+	if err := modify(&updated); err != nil {
+		return nil, err
+	}
+	updated.ID = id
+	r.playlists[id] = &updated
+	return &updated, nil
+}
+
+func (r *MemoryPlaylistStorage) DeletePlaylist(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.playlists[id]; !ok {
+		return ErrNotFound
+	}
+
+	delete(r.playlists, id)
+	return nil
+}
+
+// RemoveQuotes drops the quotes from every playlist they belong to, the
+// order of the remaining members is kept.
+func (r *MemoryPlaylistStorage) RemoveQuotes(ctx context.Context, quoteIDs ...int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, p := range r.playlists {
+		kept := slices.DeleteFunc(slices.Clone(p.QuoteIDs), func(quoteID int) bool {
+			return slices.Contains(quoteIDs, quoteID)
+		})
+		if len(kept) == len(p.QuoteIDs) {
+			continue
+		}
+
+		updated := *p
+		updated.QuoteIDs = kept
+		r.playlists[id] = &updated
+	}
+	return nil
+}
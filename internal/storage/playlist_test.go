@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+)
+
+func TestMemoryPlaylistStorage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Playlist CRUD", func(t *testing.T) {
+		s := NewPlaylistInMemory()
+
+		created, err := s.CreatePlaylist(ctx, &model.Playlist{Name: "a", QuoteIDs: []int{1, 2}})
+		if err != nil || created.ID != 1 {
+			t.Fatalf("unexpected create result %+v, %v", created, err)
+		}
+		_, _ = s.CreatePlaylist(ctx, &model.Playlist{Name: "b"})
+
+		updated, err := s.ModifyPlaylist(ctx, 1, func(p *model.Playlist) error {
+			p.QuoteIDs = append(p.QuoteIDs, 3)
+			return nil
+		})
+		if err != nil || !slices.Equal(updated.QuoteIDs, []int{1, 2, 3}) {
+			t.Fatalf("unexpected modify result %+v, %v", updated, err)
+		}
+		if !slices.Equal(created.QuoteIDs, []int{1, 2}) {
+			t.Errorf("stored playlist changed in place: %v", created.QuoteIDs)
+		}
+
+		failure := errors.New("rejected")
+		if _, err := s.ModifyPlaylist(ctx, 1, func(*model.Playlist) error { return failure }); !errors.Is(err, failure) {
+			t.Errorf("expected modify error, got %v", err)
+		}
+		if _, err := s.ModifyPlaylist(ctx, 9, func(*model.Playlist) error { return nil }); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+
+		list, _ := s.GetPlaylists(ctx)
+		if len(list) != 2 || len(list[0].QuoteIDs) != 3 || list[1].ID != 2 {
+			t.Errorf("unexpected playlists %+v", list)
+		}
+
+		if err := s.DeletePlaylist(ctx, 1); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := s.GetPlaylistByID(ctx, 1); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Remove quotes", func(t *testing.T) {
+		s := NewPlaylistInMemory()
+		first, _ := s.CreatePlaylist(ctx, &model.Playlist{QuoteIDs: []int{3, 1, 2}})
+		_, _ = s.CreatePlaylist(ctx, &model.Playlist{QuoteIDs: []int{4}})
+
+		if err := s.RemoveQuotes(ctx, 1, 4); err != nil {
+			t.Fatalf("remove quotes: %v", err)
+		}
+
+		p, _ := s.GetPlaylistByID(ctx, 1)
+		if !slices.Equal(p.QuoteIDs, []int{3, 2}) {
+			t.Errorf("expected members 3 and 2, got %v", p.QuoteIDs)
+		}
+		if !slices.Equal(first.QuoteIDs, []int{3, 1, 2}) {
+			t.Errorf("stored playlist changed in place: %v", first.QuoteIDs)
+		}
+		if p, _ := s.GetPlaylistByID(ctx, 2); len(p.QuoteIDs) != 0 {
+			t.Errorf("expected an empty playlist, got %v", p.QuoteIDs)
+		}
+	})
+}
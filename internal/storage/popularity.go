@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+)
+
+// Unrated quotes are treated as having ratingPriorWeight ratings of
+// ratingPrior, so a single 5 does not outrank many 4s.
+const (
+	ratingPrior       = 3
+	ratingPriorWeight = 5
+)
+
+// Decay weighs votes by age for popularity scores: a vote loses half of its
+// weight every HalfLife before Now.
+type Decay struct {
+	Now      time.Time
+	HalfLife time.Duration
+}
+
+func (d Decay) weight(at time.Time) float64 {
+	if d.HalfLife <= 0 {
+		return 1
+	}
+	age := d.Now.Sub(at)
+	if age < 0 {
+		age = 0
+	}
+	return math.Exp2(-float64(age) / float64(d.HalfLife))
+}
+
+type rating struct {
+	value int
+	at    time.Time
+}
+
+// votes are the likes and ratings of one quote keyed by user, with the
+// running rating sum so aggregates need no pass over the users.
+type votes struct {
+	likes     map[string]time.Time
+	ratings   map[string]rating
+	ratingSum int
+}
+
+func newVotes() *votes {
+	return &votes{likes: make(map[string]time.Time), ratings: make(map[string]rating)}
+}
+
+func (v *votes) popularity(id int) model.Popularity {
+	p := model.Popularity{QuoteID: id}
+	if v == nil {
+		return p
+	}
+
+	p.Likes = len(v.likes)
+	p.Ratings = len(v.ratings)
+	if p.Ratings > 0 {
+		p.AverageRating = float64(v.ratingSum) / float64(p.Ratings)
+	}
+	return p
+}
+
+// score adds up the decayed votes: a like weighs 1, a rating from 0 for
+// one star to 1 for five.
+func (v *votes) score(d Decay) float64 {
+	var score float64
+	for _, at := range v.likes {
+		score += d.weight(at)
+	}
+	for _, r := range v.ratings {
+		score += float64(r.value-model.MinRating) / (model.MaxRating - model.MinRating) * d.weight(r.at)
+	}
+	return score
+}
+
+// ratingWeight is the squared smoothed average rating, the relative chance
+// of the quote in FindRandomQuote with ByRating.
+func (v *votes) ratingWeight() float64 {
+	sum, n := float64(ratingPrior*ratingPriorWeight), float64(ratingPriorWeight)
+	if v != nil {
+		sum += float64(v.ratingSum)
+		n += float64(len(v.ratings))
+	}
+	avg := sum / n
+	return avg * avg
+}
+
+// SetLike records that user likes the quote, or no longer does. Repeated
+// likes by the same user count once.
+func (r *MemoryStorage) SetLike(ctx context.Context, id int, user string, liked bool, at time.Time) (model.Popularity, error) {
+	if err := ctx.Err(); err != nil {
+		return model.Popularity{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.quotes[id]; !ok {
+		return model.Popularity{}, ErrNotFound
+	}
+
+	v := r.votesOf(id)
+	if !liked {
+		delete(v.likes, user)
+	} else if _, ok := v.likes[user]; !ok {
+		v.likes[user] = at
+	}
+	return v.popularity(id), nil
+}
+
+// SetRating records the rating of the quote by user, replacing the previous
+// one. A zero rating removes it.
+func (r *MemoryStorage) SetRating(ctx context.Context, id int, user string, value int, at time.Time) (model.Popularity, error) {
+	if err := ctx.Err(); err != nil {
+		return model.Popularity{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.quotes[id]; !ok {
+		return model.Popularity{}, ErrNotFound
+	}
+
+	v := r.votesOf(id)
+	if prev, ok := v.ratings[user]; ok {
+		v.ratingSum -= prev.value
+		delete(v.ratings, user)
+	}
+	if value != 0 {
+		v.ratings[user] = rating{value: value, at: at}
+		v.ratingSum += value
+	}
+	return v.popularity(id), nil
+}
+
+func (r *MemoryStorage) GetPopularity(ctx context.Context, id int) (model.Popularity, error) {
+	if err := ctx.Err(); err != nil {
+		return model.Popularity{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.quotes[id]; !ok {
+		return model.Popularity{}, ErrNotFound
+	}
+	return r.votes[id].popularity(id), nil
+}
+
+// TopQuotes returns quotes matching filter by descending decayed score, up
+// to filter.Limit of them. Quotes without a positive score are not ranked.
+func (r *MemoryStorage) TopQuotes(ctx context.Context, filter QuoteFilter, decay Decay) ([]model.RankedQuote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	filter.AfterID = 0
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ranked := make([]model.RankedQuote, 0, len(r.votes))
+	for id, v := range r.votes {
+		q := r.quotes[id]
+		if !filter.match(q) {
+			continue
+		}
+		p := v.popularity(id)
+		if p.Score = v.score(decay); p.Score > 0 {
+			ranked = append(ranked, model.RankedQuote{Quote: q, Popularity: p})
+		}
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Popularity.Score != ranked[j].Popularity.Score {
+			return ranked[i].Popularity.Score > ranked[j].Popularity.Score
+		}
+		return ranked[i].Quote.ID < ranked[j].Quote.ID
+	})
+
+	if filter.Limit > 0 && len(ranked) > filter.Limit {
+		ranked = ranked[:filter.Limit]
+	}
+	return ranked, nil
+}
+
+// votesOf returns the votes of a quote, creating them on first use. The
+// caller must hold the write lock.
+func (r *MemoryStorage) votesOf(id int) *votes {
+	v, ok := r.votes[id]
+	if !ok {
+		v = newVotes()
+		r.votes[id] = v
+	}
+	return v
+}
+
+// pickByRating draws one of ids with a chance proportional to its rating
+// weight. The caller must hold the lock.
+func (r *MemoryStorage) pickByRating(ids []int) int {
+	weights := make([]float64, len(ids))
+	var total float64
+	for i, id := range ids {
+		total += r.votes[id].ratingWeight()
+		weights[i] = total
+	}
+
+	n := rand.Float64() * total
+	i := sort.SearchFloat64s(weights, n)
+	if i == len(ids) {
+		i--
+	}
+	return ids[i]
+}
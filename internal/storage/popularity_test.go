@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+)
+
+func TestMemoryStoragePopularity(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Votes are deduplicated per user", func(t *testing.T) {
+		s := NewInMemory(10)
+		created, _, _ := s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "Q"})
+
+		for _, user := range []string{"u1", "u1", "u2"} {
+			if _, err := s.SetLike(ctx, created.ID, user, true, now); err != nil {
+				t.Fatal(err)
+			}
+		}
+		_, _ = s.SetRating(ctx, created.ID, "u1", 2, now)
+		_, _ = s.SetRating(ctx, created.ID, "u1", 5, now)
+		p, _ := s.SetRating(ctx, created.ID, "u2", 4, now)
+		if p.Likes != 2 || p.Ratings != 2 || p.AverageRating != 4.5 {
+			t.Errorf("unexpected popularity %+v", p)
+		}
+
+		_, _ = s.SetLike(ctx, created.ID, "u1", false, now)
+		p, _ = s.SetRating(ctx, created.ID, "u2", 0, now)
+		if p.Likes != 1 || p.Ratings != 1 || p.AverageRating != 5 {
+			t.Errorf("unexpected popularity after removals %+v", p)
+		}
+
+		if _, err := s.SetLike(ctx, 99, "u1", true, now); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Votes go away with their quote", func(t *testing.T) {
+		s := NewInMemory(1)
+		first, _, _ := s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "Q"})
+		_, _ = s.SetLike(ctx, first.ID, "u1", true, now)
+		second, _, _ := s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "Q2"})
+		_, _ = s.SetLike(ctx, second.ID, "u1", true, now)
+		_ = s.DeleteByID(ctx, second.ID)
+
+		if len(s.votes) != 0 {
+			t.Errorf("expected votes of evicted and deleted quotes to be dropped, got %d", len(s.votes))
+		}
+	})
+
+	t.Run("TopQuotes decays old votes", func(t *testing.T) {
+		s := NewInMemory(10)
+		for i := 0; i < 4; i++ {
+			_, _, _ = s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "Q"})
+		}
+		// Quote 1 has three month-old likes, quote 2 two fresh ones, quote 3
+		// a fresh five-star rating and quote 4 only a one-star rating.
+		for _, user := range []string{"u1", "u2", "u3"} {
+			_, _ = s.SetLike(ctx, 1, user, true, now.Add(-30*24*time.Hour))
+		}
+		_, _ = s.SetLike(ctx, 2, "u1", true, now)
+		_, _ = s.SetLike(ctx, 2, "u2", true, now)
+		_, _ = s.SetRating(ctx, 3, "u1", 5, now)
+		_, _ = s.SetRating(ctx, 4, "u1", 1, now)
+
+		ranked, err := s.TopQuotes(ctx, QuoteFilter{Limit: 10}, Decay{Now: now, HalfLife: 7 * 24 * time.Hour})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int
+		for _, rq := range ranked {
+			ids = append(ids, rq.Quote.ID)
+		}
+		if len(ids) != 3 || ids[0] != 2 || ids[1] != 3 || ids[2] != 1 {
+			t.Errorf("expected quotes 2, 3, 1, got %v", ids)
+		}
+		if ranked[0].Popularity.Score != 2 {
+			t.Errorf("expected fresh likes to keep their weight, got %v", ranked[0].Popularity.Score)
+		}
+
+		ranked, _ = s.TopQuotes(ctx, QuoteFilter{Limit: 1}, Decay{Now: now})
+		if len(ranked) != 1 || ranked[0].Quote.ID != 1 {
+			t.Errorf("expected quote 1 on top without decay, got %+v", ranked)
+		}
+	})
+
+	t.Run("FindRandomQuote favours high ratings", func(t *testing.T) {
+		s := NewInMemory(10)
+		low, _, _ := s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "Q"})
+		high, _, _ := s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "Q"})
+		for _, user := range []string{"u1", "u2", "u3", "u4", "u5", "u6", "u7", "u8", "u9", "u10"} {
+			_, _ = s.SetRating(ctx, low.ID, user, 1, now)
+			_, _ = s.SetRating(ctx, high.ID, user, 5, now)
+		}
+
+		picks := make(map[int]int)
+		for i := 0; i < 1000; i++ {
+			q, err := s.FindRandomQuote(ctx, QuoteFilter{ByRating: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			picks[q.ID]++
+		}
+		// The weights are 1.67² and 4.33², high should win about 87% of draws.
+		if picks[high.ID] < 750 || picks[low.ID] == 0 {
+			t.Errorf("expected a bias towards the highly rated quote, got %v", picks)
+		}
+	})
+}
@@ -1,28 +1,100 @@
 package storage
 
 import (
+	"context"
 	"fmt"
+	"maps"
 	"math/rand"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
 )
 
 var ErrNotFound = fmt.Errorf("not found")
 
+// ErrTranslationNotFound is returned for a missing translation of an existing
+// quote, it matches ErrNotFound.
+var ErrTranslationNotFound = fmt.Errorf("translation %w", ErrNotFound)
+
+// QuoteFilter selects quotes for FindQuotes, zero fields match everything.
+// Text matches a case-insensitive substring of the quote or its author.
+type QuoteFilter struct {
+	Author string
+	Tag    string
+	Text   string
+	Lang   string
+	Status model.Status
+	// At keeps the quotes live at that time, see model.Quote.Live.
+	At time.Time
+	// Translated makes Lang also match quotes translated into it.
+	Translated bool
+	// ByRating makes FindRandomQuote favour highly rated quotes.
+	ByRating bool
+	AfterID  int
+	Limit    int
+}
+
+func (f QuoteFilter) match(q *model.Quote) bool {
+	if q.ID <= f.AfterID {
+		return false
+	}
+	if f.Author != "" && !strings.EqualFold(q.Author, f.Author) {
+		return false
+	}
+	if f.Tag != "" && !q.HasTag(f.Tag) {
+		return false
+	}
+	if f.Status != "" && q.Status != f.Status {
+		return false
+	}
+	if !f.At.IsZero() && !q.Live(f.At) {
+		return false
+	}
+	if f.Lang != "" && q.Lang != f.Lang {
+		if _, ok := q.Translation(f.Lang); !f.Translated || !ok {
+			return false
+		}
+	}
+	if f.Text != "" && !containsFold(q.Quote, f.Text) && !containsFold(q.Author, f.Text) {
+		return false
+	}
+	return true
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
 type QuoteStorage interface {
-	CreateQuote(q *model.Quote) (*model.Quote, error)
-	GetQuotesList() ([]*model.Quote, error)
-	GetRandomQuote() (*model.Quote, error)
-	GetQuotesByAuthor(author string) ([]*model.Quote, error)
-	DeleteByID(id int) error
+	CreateQuote(ctx context.Context, q *model.Quote) (created, evicted *model.Quote, err error)
+	GetQuoteByID(ctx context.Context, id int) (*model.Quote, error)
+	FindRandomQuote(ctx context.Context, filter QuoteFilter) (*model.Quote, error)
+	GetQuotesByAuthors(ctx context.Context, authors []string) (map[string][]*model.Quote, error)
+	FindQuotes(ctx context.Context, filter QuoteFilter) ([]*model.Quote, error)
+	ModifyQuote(ctx context.Context, id int, modify func(q *model.Quote) error) (*model.Quote, error)
+	DeleteByID(ctx context.Context, id int) error
+	Batch(ctx context.Context, fn func(tx QuoteTx) error) error
+	ScheduledQuotes(ctx context.Context, after, until time.Time) ([]*model.Quote, error)
+	PurgeExpired(ctx context.Context, at time.Time) ([]*model.Quote, error)
+	AddTranslation(ctx context.Context, id int, t model.Translation) (*model.Quote, error)
+	DeleteTranslation(ctx context.Context, id int, lang string) (*model.Quote, error)
+	SetLike(ctx context.Context, id int, user string, liked bool, at time.Time) (model.Popularity, error)
+	SetRating(ctx context.Context, id int, user string, rating int, at time.Time) (model.Popularity, error)
+	GetPopularity(ctx context.Context, id int) (model.Popularity, error)
+	TopQuotes(ctx context.Context, filter QuoteFilter, decay Decay) ([]model.RankedQuote, error)
 }
 
 type MemoryStorage struct {
 	limit  int
 	mu     sync.RWMutex
 	quotes map[int]*model.Quote
+	// votes are guarded by mu too and dropped together with their quote.
+	votes  map[int]*votes
 	nextID int
 	minID  int
 }
@@ -31,17 +103,36 @@ func NewInMemory(limitQuotes int) *MemoryStorage {
 	return &MemoryStorage{
 		limit:  limitQuotes,
 		quotes: make(map[int]*model.Quote),
+		votes:  make(map[int]*votes),
 		nextID: 1,
 		minID:  1,
 	}
 }
 
-func (r *MemoryStorage) CreateQuote(q *model.Quote) (*model.Quote, error) {
+// CreateQuote stores q under the next ID. When the limit is reached the
+// oldest quote is evicted and returned as evicted.
+func (r *MemoryStorage) CreateQuote(ctx context.Context, q *model.Quote) (created, evicted *model.Quote, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	created, evicted = r.create(ctx, q)
+	return created, evicted, nil
+}
+
+func (r *MemoryStorage) create(ctx context.Context, q *model.Quote) (created, evicted *model.Quote) {
 	if len(r.quotes) >= r.limit {
+		// Quotes deleted explicitly leave gaps below the oldest live ID.
+		for r.quotes[r.minID] == nil && r.minID < r.nextID {
+			r.minID++
+		}
+		evicted = r.quotes[r.minID]
 		delete(r.quotes, r.minID)
+		delete(r.votes, r.minID)
+		logger.FromContext(ctx, nil).Debug().Int("id", r.minID).Msg("quote evicted")
 		r.minID++
 	}
 
@@ -49,60 +140,324 @@ func (r *MemoryStorage) CreateQuote(q *model.Quote) (*model.Quote, error) {
 	r.quotes[q.ID] = q
 	r.nextID++
 
-	return q, nil
+	return q, evicted
 }
 
-func (r *MemoryStorage) GetQuotesList() ([]*model.Quote, error) {
+// FindQuotes returns quotes matching filter in ascending ID order.
+func (r *MemoryStorage) FindQuotes(ctx context.Context, filter QuoteFilter) ([]*model.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	quotes := make([]*model.Quote, 0, len(r.quotes))
 	for _, q := range r.quotes {
-		quotes = append(quotes, q)
+		if filter.match(q) {
+			quotes = append(quotes, q)
+		}
 	}
 
+	sort.Slice(quotes, func(i, j int) bool {
+		return quotes[i].ID < quotes[j].ID
+	})
+
+	if filter.Limit > 0 && len(quotes) > filter.Limit {
+		quotes = quotes[:filter.Limit]
+	}
 	return quotes, nil
 }
 
-func (r *MemoryStorage) GetRandomQuote() (*model.Quote, error) {
+func (r *MemoryStorage) GetQuoteByID(ctx context.Context, id int) (*model.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	if len(r.quotes) == 0 {
+	q, ok := r.quotes[id]
+	if !ok {
 		return nil, ErrNotFound
 	}
+	return q, nil
+}
+
+// FindRandomQuote picks a random quote among those matching filter, its
+// AfterID and Limit are ignored.
+func (r *MemoryStorage) FindRandomQuote(ctx context.Context, filter QuoteFilter) (*model.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	filter.AfterID = 0
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
 	ids := make([]int, 0, len(r.quotes))
-	for id := range r.quotes {
-		ids = append(ids, id)
+	for id, q := range r.quotes {
+		if filter.match(q) {
+			ids = append(ids, id)
+		}
 	}
 
+	if len(ids) == 0 {
+		return nil, ErrNotFound
+	}
+
+	if filter.ByRating {
+		return r.quotes[r.pickByRating(ids)], nil
+	}
 	randomID := ids[rand.Intn(len(ids))]
 	return r.quotes[randomID], nil
 }
 
-func (r *MemoryStorage) GetQuotesByAuthor(author string) ([]*model.Quote, error) {
+// GetQuotesByAuthors fetches live quotes of several authors in one pass,
+// keyed by the lower-cased author name.
+func (r *MemoryStorage) GetQuotesByAuthors(ctx context.Context, authors []string) (map[string][]*model.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]*model.Quote, len(authors))
+	for _, a := range authors {
+		result[strings.ToLower(a)] = []*model.Quote{}
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	quotes := make([]*model.Quote, 0, len(r.quotes))
+	now := time.Now()
+	for _, q := range r.quotes {
+		key := strings.ToLower(q.Author)
+		if list, ok := result[key]; ok && q.Live(now) {
+			result[key] = append(list, q)
+		}
+	}
+
+	for _, list := range result {
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].ID < list[j].ID
+		})
+	}
+	return result, nil
+}
+
+// ModifyQuote applies modify to a copy of the stored quote and stores the
+// result unless modify fails, whose error is then returned as is. The lock
+// is held throughout, so modify sees no concurrent changes and must not
+// call back into the storage.
+func (r *MemoryStorage) ModifyQuote(ctx context.Context, id int, modify func(q *model.Quote) error) (*model.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.modify(id, modify)
+}
+
+func (r *MemoryStorage) modify(id int, modify func(q *model.Quote) error) (*model.Quote, error) {
+	current, ok := r.quotes[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	updated := *current
+	if err := modify(&updated); err != nil {
+		return nil, err
+	}
+	updated.ID = id
+	r.quotes[id] = &updated
+	return &updated, nil
+}
+
+func (r *MemoryStorage) DeleteByID(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.delete(id)
+	return err
+}
+
+func (r *MemoryStorage) delete(id int) (*model.Quote, error) {
+	q, ok := r.quotes[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	delete(r.quotes, id)
+	delete(r.votes, id)
+	return q, nil
+}
+
+// QuoteTx changes quotes inside Batch, each call sees the changes made
+// before it.
+type QuoteTx interface {
+	CreateQuote(q *model.Quote) (created, evicted *model.Quote)
+	GetQuoteByID(id int) (*model.Quote, error)
+	ModifyQuote(id int, modify func(q *model.Quote) error) (*model.Quote, error)
+	// DeleteByID returns the deleted quote.
+	DeleteByID(id int) (*model.Quote, error)
+}
+
+// Batch runs fn with the write lock held, so readers see either none or
+// all of its changes. When fn fails every change is undone, evicted and
+// deleted quotes are restored with their votes, and its error is returned.
+func (r *MemoryStorage) Batch(ctx context.Context, fn func(tx QuoteTx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Stored quotes are replaced, never changed in place, so copies of the
+	// maps are enough to roll back.
+	quotes, votes := maps.Clone(r.quotes), maps.Clone(r.votes)
+	nextID, minID := r.nextID, r.minID
+
+	if err := fn(memoryTx{r: r, ctx: ctx}); err != nil {
+		r.quotes, r.votes = quotes, votes
+		r.nextID, r.minID = nextID, minID
+		return err
+	}
+	return nil
+}
+
+type memoryTx struct {
+	r   *MemoryStorage
+	ctx context.Context
+}
+
+func (tx memoryTx) CreateQuote(q *model.Quote) (created, evicted *model.Quote) {
+	return tx.r.create(tx.ctx, q)
+}
+
+func (tx memoryTx) GetQuoteByID(id int) (*model.Quote, error) {
+	q, ok := tx.r.quotes[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return q, nil
+}
+
+func (tx memoryTx) ModifyQuote(id int, modify func(q *model.Quote) error) (*model.Quote, error) {
+	return tx.r.modify(id, modify)
+}
+
+func (tx memoryTx) DeleteByID(id int) (*model.Quote, error) {
+	return tx.r.delete(id)
+}
 
+// ScheduledQuotes returns the quotes that were created ahead of their
+// PublishAt, which lies in (after, until], in ascending ID order.
+func (r *MemoryStorage) ScheduledQuotes(ctx context.Context, after, until time.Time) ([]*model.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var quotes []*model.Quote
 	for _, q := range r.quotes {
-		if strings.EqualFold(q.Author, author) {
+		if q.PublishAt.After(after) && !q.PublishAt.After(until) && q.PublishAt.After(q.CreatedAt) {
 			quotes = append(quotes, q)
 		}
 	}
+
+	sort.Slice(quotes, func(i, j int) bool {
+		return quotes[i].ID < quotes[j].ID
+	})
 	return quotes, nil
 }
 
-func (r *MemoryStorage) DeleteByID(id int) error {
+// PurgeExpired deletes the quotes whose ExpireAt is not after at and
+// returns them in ascending ID order.
+func (r *MemoryStorage) PurgeExpired(ctx context.Context, at time.Time) ([]*model.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, ok := r.quotes[id]; !ok {
-		return ErrNotFound
+	var purged []*model.Quote
+	for id, q := range r.quotes {
+		if !q.ExpireAt.IsZero() && !q.ExpireAt.After(at) {
+			purged = append(purged, q)
+			delete(r.quotes, id)
+			delete(r.votes, id)
+		}
+	}
+
+	sort.Slice(purged, func(i, j int) bool {
+		return purged[i].ID < purged[j].ID
+	})
+	return purged, nil
+}
+
+// AddTranslation attaches t to the quote with the given ID and returns the
+// updated quote. It fails with ErrAlreadyExists when the quote already has a
+// translation into t.Lang.
+func (r *MemoryStorage) AddTranslation(ctx context.Context, id int, t model.Translation) (*model.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	delete(r.quotes, id)
-	return nil
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.quotes[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if _, ok := current.Translation(t.Lang); ok {
+		return nil, ErrAlreadyExists
+	}
+
+	// Stored quotes are shared with readers, so the change goes to a copy.
+	updated := *current
+	updated.Translations = append(slices.Clip(current.Translations), t)
+	r.quotes[id] = &updated
+	return &updated, nil
+}
+
+// DeleteTranslation removes the translation into lang from the quote with
+// the given ID and returns the updated quote.
+func (r *MemoryStorage) DeleteTranslation(ctx context.Context, id int, lang string) (*model.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.quotes[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	translations := slices.DeleteFunc(slices.Clone(current.Translations), func(t model.Translation) bool {
+		return t.Lang == lang
+	})
+	if len(translations) == len(current.Translations) {
+		return nil, ErrTranslationNotFound
+	}
+	if len(translations) == 0 {
+		translations = nil
+	}
+
+	updated := *current
+	updated.Translations = translations
+	r.quotes[id] = &updated
+	return &updated, nil
 }
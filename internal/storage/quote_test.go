@@ -1,21 +1,25 @@
 package storage
 
 import (
+	"context"
 	"errors"
 	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/zonder12120/brandscout-quotebook/internal/model"
 )
 
 func TestMemoryStorage(t *testing.T) {
-	t.Run("CreateQuote and GetQuotesList", func(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("CreateQuote and FindQuotes", func(t *testing.T) {
 		s := NewInMemory(10)
 		q := &model.Quote{Author: "Test", Quote: "Test quote"}
 
-		created, err := s.CreateQuote(q)
+		created, _, err := s.CreateQuote(ctx, q)
 		if err != nil {
 			t.Fatalf("createQuote failed: %v", err)
 		}
@@ -24,9 +28,9 @@ func TestMemoryStorage(t *testing.T) {
 			t.Errorf("expected ID 1, got %d", created.ID)
 		}
 
-		list, err := s.GetQuotesList()
+		list, err := s.FindQuotes(ctx, QuoteFilter{})
 		if err != nil {
-			t.Fatalf("getQuotesList failed: %v", err)
+			t.Fatalf("findQuotes failed: %v", err)
 		}
 
 		if len(list) != 1 {
@@ -39,7 +43,7 @@ func TestMemoryStorage(t *testing.T) {
 		s := NewInMemory(limit)
 
 		for i := 0; i < limit+2; i++ {
-			_, err := s.CreateQuote(&model.Quote{
+			_, _, err := s.CreateQuote(ctx, &model.Quote{
 				Author: "Author",
 				Quote:  "Quote " + strconv.Itoa(i+1),
 			})
@@ -48,7 +52,7 @@ func TestMemoryStorage(t *testing.T) {
 			}
 		}
 
-		list, _ := s.GetQuotesList()
+		list, _ := s.FindQuotes(ctx, QuoteFilter{})
 		if len(list) != limit {
 			t.Fatalf("expected %d quotes, got %d", limit, len(list))
 		}
@@ -60,10 +64,30 @@ func TestMemoryStorage(t *testing.T) {
 		}
 	})
 
-	t.Run("GetRandomQuote", func(t *testing.T) {
+	t.Run("Eviction skips deleted quotes and reports the evicted one", func(t *testing.T) {
+		s := NewInMemory(2)
+		_, _, _ = s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "Q1"})
+		_, _, _ = s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "Q2"})
+		_ = s.DeleteByID(ctx, 1)
+
+		_, evicted, _ := s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "Q3"})
+		if evicted != nil {
+			t.Errorf("expected no eviction below the limit, got %+v", evicted)
+		}
+
+		_, evicted, _ = s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "Q4"})
+		if evicted == nil || evicted.ID != 2 {
+			t.Errorf("expected quote 2 to be evicted, got %+v", evicted)
+		}
+		if list, _ := s.FindQuotes(ctx, QuoteFilter{}); len(list) != 2 {
+			t.Errorf("expected 2 quotes, got %d", len(list))
+		}
+	})
+
+	t.Run("FindRandomQuote", func(t *testing.T) {
 		s := NewInMemory(10)
 
-		_, err := s.GetRandomQuote()
+		_, err := s.FindRandomQuote(ctx, QuoteFilter{})
 		if !errors.Is(err, ErrNotFound) {
 			t.Errorf("expected ErrNotFound, got %v", err)
 		}
@@ -71,12 +95,12 @@ func TestMemoryStorage(t *testing.T) {
 		quotes := make([]*model.Quote, 5)
 		for i := 0; i < 5; i++ {
 			q := &model.Quote{Author: "A", Quote: "Q" + strconv.Itoa(i+1)}
-			quotes[i], _ = s.CreateQuote(q)
+			quotes[i], _, _ = s.CreateQuote(ctx, q)
 		}
 
 		found := make(map[int]bool)
 		for i := 0; i < 100; i++ {
-			q, err := s.GetRandomQuote()
+			q, err := s.FindRandomQuote(ctx, QuoteFilter{})
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -88,16 +112,39 @@ func TestMemoryStorage(t *testing.T) {
 		}
 	})
 
+	t.Run("FindRandomQuote picks among matching quotes", func(t *testing.T) {
+		s := NewInMemory(10)
+		for i, lang := range []string{"en", "ru", "en", "ru", ""} {
+			_, _, _ = s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "Q" + strconv.Itoa(i+1), Lang: lang})
+		}
+
+		found := make(map[int]bool)
+		for i := 0; i < 50; i++ {
+			q, err := s.FindRandomQuote(ctx, QuoteFilter{Lang: "ru", AfterID: 100, Limit: 1})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			found[q.ID] = true
+		}
+		if len(found) != 2 || !found[2] || !found[4] {
+			t.Errorf("expected quotes 2 and 4, got %v", found)
+		}
+
+		if _, err := s.FindRandomQuote(ctx, QuoteFilter{Lang: "de"}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
 	t.Run("DeleteByID", func(t *testing.T) {
 		s := NewInMemory(10)
 
-		err := s.DeleteByID(999)
+		err := s.DeleteByID(ctx, 999)
 		if !errors.Is(err, ErrNotFound) {
 			t.Errorf("expected ErrNotFound, got %v", err)
 		}
 
-		created, _ := s.CreateQuote(&model.Quote{Author: "A", Quote: "Q"})
-		if err := s.DeleteByID(created.ID); err != nil {
+		created, _, _ := s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "Q"})
+		if err := s.DeleteByID(ctx, created.ID); err != nil {
 			t.Fatalf("delete failed: %v", err)
 		}
 
@@ -106,12 +153,12 @@ func TestMemoryStorage(t *testing.T) {
 		}
 	})
 
-	t.Run("GetQuotesByAuthor", func(t *testing.T) {
+	t.Run("FindQuotes by author", func(t *testing.T) {
 		s := NewInMemory(10)
 
 		authors := []string{"AuthorA", "AuthorB", "authorA"}
 		for i, author := range authors {
-			_, err := s.CreateQuote(&model.Quote{
+			_, _, err := s.CreateQuote(ctx, &model.Quote{
 				Author: author,
 				Quote:  "Quote " + strconv.Itoa(i+1),
 			})
@@ -120,7 +167,7 @@ func TestMemoryStorage(t *testing.T) {
 			}
 		}
 
-		quotes, err := s.GetQuotesByAuthor("authora")
+		quotes, err := s.FindQuotes(ctx, QuoteFilter{Author: "authora"})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -135,7 +182,7 @@ func TestMemoryStorage(t *testing.T) {
 			}
 		}
 
-		quotes, err = s.GetQuotesByAuthor("Unknown")
+		quotes, err = s.FindQuotes(ctx, QuoteFilter{Author: "Unknown"})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -152,7 +199,7 @@ func TestMemoryStorage(t *testing.T) {
 			wg.Add(1)
 			go func(n int) {
 				defer wg.Done()
-				_, err := s.CreateQuote(&model.Quote{
+				_, _, err := s.CreateQuote(ctx, &model.Quote{
 					Author: "Author" + strconv.Itoa(n),
 					Quote:  "Quote" + strconv.Itoa(n),
 				})
@@ -166,7 +213,7 @@ func TestMemoryStorage(t *testing.T) {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				_, err := s.GetQuotesList()
+				_, err := s.FindQuotes(ctx, QuoteFilter{})
 				if err != nil {
 					t.Errorf("list error: %v", err)
 				}
@@ -174,9 +221,266 @@ func TestMemoryStorage(t *testing.T) {
 		}
 
 		wg.Wait()
-		list, _ := s.GetQuotesList()
+		list, _ := s.FindQuotes(ctx, QuoteFilter{})
 		if len(list) != 100 {
 			t.Errorf("expected 100 quotes, got %d", len(list))
 		}
 	})
+
+	t.Run("FindQuotes pages and GetQuoteByID", func(t *testing.T) {
+		s := NewInMemory(10)
+		for i := 0; i < 5; i++ {
+			_, _, _ = s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "Q" + strconv.Itoa(i+1)})
+		}
+		_ = s.DeleteByID(ctx, 2)
+
+		page, err := s.FindQuotes(ctx, QuoteFilter{Limit: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page) != 2 || page[0].ID != 1 || page[1].ID != 3 {
+			t.Fatalf("unexpected first page: %v", page)
+		}
+
+		page, _ = s.FindQuotes(ctx, QuoteFilter{AfterID: page[1].ID, Limit: 2})
+		if len(page) != 2 || page[0].ID != 4 || page[1].ID != 5 {
+			t.Fatalf("unexpected second page: %v", page)
+		}
+
+		q, err := s.GetQuoteByID(ctx, 4)
+		if err != nil || q.Quote != "Q4" {
+			t.Errorf("expected quote 4, got %v, %v", q, err)
+		}
+		if _, err := s.GetQuoteByID(ctx, 2); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("FindQuotes filters by author, tag and text", func(t *testing.T) {
+		s := NewInMemory(10)
+		_, _, _ = s.CreateQuote(ctx, &model.Quote{Author: "Seneca", Quote: "Q1", Tags: []string{"stoic"}})
+		_, _, _ = s.CreateQuote(ctx, &model.Quote{Author: "Confucius", Quote: "Q2", Tags: []string{"stoic"}})
+		_, _, _ = s.CreateQuote(ctx, &model.Quote{Author: "seneca", Quote: "Q3"})
+
+		quotes, err := s.FindQuotes(ctx, QuoteFilter{Author: "SENECA"})
+		if err != nil || len(quotes) != 2 || quotes[0].ID != 1 || quotes[1].ID != 3 {
+			t.Errorf("unexpected quotes by author: %v, %v", quotes, err)
+		}
+
+		quotes, _ = s.FindQuotes(ctx, QuoteFilter{Tag: "stoic", AfterID: 1})
+		if len(quotes) != 1 || quotes[0].ID != 2 {
+			t.Errorf("unexpected quotes by tag: %v", quotes)
+		}
+
+		quotes, _ = s.FindQuotes(ctx, QuoteFilter{Text: "q3"})
+		if len(quotes) != 1 || quotes[0].ID != 3 {
+			t.Errorf("unexpected quotes by text: %v", quotes)
+		}
+		quotes, _ = s.FindQuotes(ctx, QuoteFilter{Text: "confu"})
+		if len(quotes) != 1 || quotes[0].ID != 2 {
+			t.Errorf("unexpected quotes by author text: %v", quotes)
+		}
+	})
+
+	t.Run("GetQuotesByAuthors", func(t *testing.T) {
+		s := NewInMemory(10)
+		_, _, _ = s.CreateQuote(ctx, &model.Quote{Author: "Seneca", Quote: "Q1"})
+		_, _, _ = s.CreateQuote(ctx, &model.Quote{Author: "Confucius", Quote: "Q2"})
+		_, _, _ = s.CreateQuote(ctx, &model.Quote{Author: "seneca", Quote: "Q3"})
+
+		byAuthor, err := s.GetQuotesByAuthors(ctx, []string{"Seneca", "Unknown"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(byAuthor["seneca"]) != 2 {
+			t.Errorf("expected 2 quotes for seneca, got %d", len(byAuthor["seneca"]))
+		}
+		if quotes, ok := byAuthor["unknown"]; !ok || len(quotes) != 0 {
+			t.Errorf("expected empty list for unknown author, got %v", quotes)
+		}
+		if _, ok := byAuthor["confucius"]; ok {
+			t.Error("unexpected author in result")
+		}
+	})
+
+	t.Run("ModifyQuote", func(t *testing.T) {
+		s := NewInMemory(10)
+		created, _, _ := s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "Q", Status: model.StatusPending})
+		_, _, _ = s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "Q2", Status: model.StatusPublished})
+
+		errRefused := errors.New("refused")
+		if _, err := s.ModifyQuote(ctx, created.ID, func(q *model.Quote) error {
+			q.Status = model.StatusRejected
+			return errRefused
+		}); !errors.Is(err, errRefused) {
+			t.Errorf("expected the modify error, got %v", err)
+		}
+
+		updated, err := s.ModifyQuote(ctx, created.ID, func(q *model.Quote) error {
+			q.Status = model.StatusPublished
+			return nil
+		})
+		if err != nil || updated.Status != model.StatusPublished {
+			t.Fatalf("unexpected result %v, %v", updated, err)
+		}
+		if created.Status != model.StatusPending {
+			t.Error("previously returned quote must not change")
+		}
+		if found, _ := s.FindQuotes(ctx, QuoteFilter{Status: model.StatusPublished}); len(found) != 2 {
+			t.Errorf("expected 2 published quotes, got %d", len(found))
+		}
+
+		if _, err := s.ModifyQuote(ctx, 99, func(*model.Quote) error { return nil }); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		s := NewInMemory(2)
+		first, _, _ := s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "first"})
+		_, _ = s.SetLike(ctx, first.ID, "u", true, time.Now())
+
+		err := s.Batch(ctx, func(tx QuoteTx) error {
+			created, evicted := tx.CreateQuote(&model.Quote{Author: "B", Quote: "second"})
+			if evicted != nil || created.ID != 2 {
+				t.Errorf("unexpected create result %+v, %+v", created, evicted)
+			}
+			if _, evicted = tx.CreateQuote(&model.Quote{Author: "C", Quote: "third"}); evicted == nil || evicted.ID != first.ID {
+				t.Errorf("expected quote 1 to be evicted, got %+v", evicted)
+			}
+			if _, err := tx.GetQuoteByID(first.ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected the eviction to be visible, got %v", err)
+			}
+			if deleted, err := tx.DeleteByID(created.ID); err != nil || deleted.Quote != "second" {
+				t.Errorf("unexpected delete result %+v, %v", deleted, err)
+			}
+			return ErrNotFound
+		})
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected the error of fn, got %v", err)
+		}
+
+		list, _ := s.FindQuotes(ctx, QuoteFilter{})
+		if len(list) != 1 || list[0].ID != first.ID {
+			t.Fatalf("expected the batch to be undone, got %v", list)
+		}
+		if p, _ := s.GetPopularity(ctx, first.ID); p.Likes != 1 {
+			t.Errorf("expected the votes to be restored, got %+v", p)
+		}
+
+		err = s.Batch(ctx, func(tx QuoteTx) error {
+			created, _ := tx.CreateQuote(&model.Quote{Author: "B", Quote: "second"})
+			_, err := tx.ModifyQuote(created.ID, func(q *model.Quote) error {
+				q.Quote = "edited"
+				return nil
+			})
+			return err
+		})
+		if q, _ := s.GetQuoteByID(ctx, 2); err != nil || q.Quote != "edited" {
+			t.Errorf("expected the batch to be stored, got %+v, %v", q, err)
+		}
+	})
+
+	t.Run("Schedule", func(t *testing.T) {
+		s := NewInMemory(10)
+		now := time.Now()
+		created := now.Add(-time.Hour)
+		_, _, _ = s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "always", CreatedAt: created})
+		scheduled, _, _ := s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "later", CreatedAt: created, PublishAt: now.Add(time.Hour)})
+		expired, _, _ := s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "gone", CreatedAt: created, ExpireAt: now.Add(-time.Minute)})
+
+		if found, _ := s.FindQuotes(ctx, QuoteFilter{At: now}); len(found) != 1 {
+			t.Errorf("expected 1 live quote, got %d", len(found))
+		}
+		if found, _ := s.FindQuotes(ctx, QuoteFilter{Author: "A", At: now}); len(found) != 1 {
+			t.Errorf("expected 1 live quote of the author, got %d", len(found))
+		}
+		for range 20 {
+			if q, err := s.FindRandomQuote(ctx, QuoteFilter{At: now}); err != nil || q.Quote != "always" {
+				t.Fatalf("expected the live quote, got %v, %v", q, err)
+			}
+		}
+
+		due, err := s.ScheduledQuotes(ctx, now, now.Add(2*time.Hour))
+		if err != nil || len(due) != 1 || due[0].ID != scheduled.ID {
+			t.Errorf("expected the scheduled quote, got %v, %v", due, err)
+		}
+		if due, _ := s.ScheduledQuotes(ctx, now.Add(time.Hour), now.Add(2*time.Hour)); len(due) != 0 {
+			t.Errorf("expected the window to exclude its start, got %v", due)
+		}
+
+		purged, err := s.PurgeExpired(ctx, now)
+		if err != nil || len(purged) != 1 || purged[0].ID != expired.ID {
+			t.Fatalf("expected the expired quote to be purged, got %v, %v", purged, err)
+		}
+		if _, err := s.GetQuoteByID(ctx, expired.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Translations", func(t *testing.T) {
+		s := NewInMemory(10)
+		created, _, _ := s.CreateQuote(ctx, &model.Quote{Author: "A", Quote: "Q", Lang: "en"})
+		_, _, _ = s.CreateQuote(ctx, &model.Quote{Author: "B", Quote: "Q", Lang: "en"})
+
+		updated, err := s.AddTranslation(ctx, created.ID, model.Translation{Lang: "ru", Quote: "Ц"})
+		if err != nil || len(updated.Translations) != 1 {
+			t.Fatalf("unexpected result %v, %v", updated, err)
+		}
+		if len(created.Translations) != 0 {
+			t.Error("previously returned quote must not change")
+		}
+		if _, err := s.AddTranslation(ctx, created.ID, model.Translation{Lang: "ru", Quote: "Ц2"}); !errors.Is(err, ErrAlreadyExists) {
+			t.Errorf("expected ErrAlreadyExists, got %v", err)
+		}
+		if _, err := s.AddTranslation(ctx, 99, model.Translation{Lang: "ru", Quote: "Ц"}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+
+		if _, err := s.ModifyQuote(ctx, created.ID, func(q *model.Quote) error {
+			q.Quote = "Q2"
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if q, _ := s.GetQuoteByID(ctx, created.ID); len(q.Translations) != 1 {
+			t.Errorf("expected translations to survive an update, got %v", q.Translations)
+		}
+
+		if found, _ := s.FindQuotes(ctx, QuoteFilter{Lang: "ru"}); len(found) != 0 {
+			t.Errorf("expected Lang to ignore translations by default, got %d quotes", len(found))
+		}
+		for i := 0; i < 10; i++ {
+			q, err := s.FindRandomQuote(ctx, QuoteFilter{Lang: "ru", Translated: true})
+			if err != nil || q.ID != created.ID {
+				t.Fatalf("expected the translated quote, got %v, %v", q, err)
+			}
+		}
+
+		if _, err := s.DeleteTranslation(ctx, created.ID, "ru"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.DeleteTranslation(ctx, created.ID, "ru"); !errors.Is(err, ErrTranslationNotFound) {
+			t.Errorf("expected ErrTranslationNotFound, got %v", err)
+		}
+		if _, err := s.DeleteTranslation(ctx, 99, "ru"); !errors.Is(err, ErrNotFound) || errors.Is(err, ErrTranslationNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Canceled context", func(t *testing.T) {
+		s := NewInMemory(10)
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		if _, _, err := s.CreateQuote(canceled, &model.Quote{Author: "A", Quote: "Q"}); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+		if len(s.quotes) != 0 {
+			t.Error("quote must not be stored with canceled context")
+		}
+		if _, err := s.FindQuotes(canceled, QuoteFilter{}); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
 }
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+)
+
+// DefaultMaxDeadLetters bounds the dead-letter list, the oldest entries are
+// dropped first.
+const DefaultMaxDeadLetters = 1000
+
+type WebhookStorage interface {
+	CreateWebhook(ctx context.Context, w *model.Webhook) (*model.Webhook, error)
+	GetWebhooks(ctx context.Context) ([]*model.Webhook, error)
+	GetWebhookByID(ctx context.Context, id int) (*model.Webhook, error)
+	UpdateWebhook(ctx context.Context, w *model.Webhook) (*model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+
+	AddDeadLetter(ctx context.Context, d *model.DeadLetter) (*model.DeadLetter, error)
+	GetDeadLetters(ctx context.Context) ([]*model.DeadLetter, error)
+	GetDeadLetterByID(ctx context.Context, id int) (*model.DeadLetter, error)
+	DeleteDeadLetter(ctx context.Context, id int) error
+}
+
+type MemoryWebhookStorage struct {
+	mu             sync.RWMutex
+	webhooks       map[int]*model.Webhook
+	nextID         int
+	deadLetters    []*model.DeadLetter
+	nextDeadID     int
+	maxDeadLetters int
+}
+
+func NewWebhookInMemory(maxDeadLetters int) *MemoryWebhookStorage {
+	return &MemoryWebhookStorage{
+		webhooks:       make(map[int]*model.Webhook),
+		nextID:         1,
+		nextDeadID:     1,
+		maxDeadLetters: maxDeadLetters,
+	}
+}
+
+func (r *MemoryWebhookStorage) CreateWebhook(ctx context.Context, w *model.Webhook) (*model.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w.ID = r.nextID
+	r.webhooks[w.ID] = w
+	r.nextID++
+
+	return w, nil
+}
+
+// GetWebhooks returns every webhook in ascending ID order.
+func (r *MemoryWebhookStorage) GetWebhooks(ctx context.Context) ([]*model.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhooks := make([]*model.Webhook, 0, len(r.webhooks))
+	for _, w := range r.webhooks {
+		webhooks = append(webhooks, w)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+	return webhooks, nil
+}
+
+func (r *MemoryWebhookStorage) GetWebhookByID(ctx context.Context, id int) (*model.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.webhooks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return w, nil
+}
+
+func (r *MemoryWebhookStorage) UpdateWebhook(ctx context.Context, w *model.Webhook) (*model.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[w.ID]; !ok {
+		return nil, ErrNotFound
+	}
+
+	r.webhooks[w.ID] = w
+	return w, nil
+}
+
+func (r *MemoryWebhookStorage) DeleteWebhook(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[id]; !ok {
+		return ErrNotFound
+	}
+
+	delete(r.webhooks, id)
+	return nil
+}
+
+func (r *MemoryWebhookStorage) AddDeadLetter(ctx context.Context, d *model.DeadLetter) (*model.DeadLetter, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.maxDeadLetters > 0 && len(r.deadLetters) >= r.maxDeadLetters {
+		r.deadLetters = r.deadLetters[1:]
+	}
+
+	d.ID = r.nextDeadID
+	r.deadLetters = append(r.deadLetters, d)
+	r.nextDeadID++
+
+	return d, nil
+}
+
+// GetDeadLetters returns dead letters oldest first.
+func (r *MemoryWebhookStorage) GetDeadLetters(ctx context.Context) ([]*model.DeadLetter, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deadLetters := make([]*model.DeadLetter, len(r.deadLetters))
+	copy(deadLetters, r.deadLetters)
+	return deadLetters, nil
+}
+
+func (r *MemoryWebhookStorage) GetDeadLetterByID(ctx context.Context, id int) (*model.DeadLetter, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if i := r.deadLetterIndex(id); i >= 0 {
+		return r.deadLetters[i], nil
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryWebhookStorage) DeleteDeadLetter(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.deadLetterIndex(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+
+	r.deadLetters = append(r.deadLetters[:i], r.deadLetters[i+1:]...)
+	return nil
+}
+
+// deadLetterIndex finds id in the list, which is sorted by ID.
+func (r *MemoryWebhookStorage) deadLetterIndex(id int) int {
+	i := sort.Search(len(r.deadLetters), func(i int) bool {
+		return r.deadLetters[i].ID >= id
+	})
+	if i < len(r.deadLetters) && r.deadLetters[i].ID == id {
+		return i
+	}
+	return -1
+}
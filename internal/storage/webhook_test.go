@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+)
+
+func TestMemoryWebhookStorage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Webhook CRUD", func(t *testing.T) {
+		s := NewWebhookInMemory(10)
+
+		created, err := s.CreateWebhook(ctx, &model.Webhook{URL: "http://a"})
+		if err != nil || created.ID != 1 {
+			t.Fatalf("unexpected create result %+v, %v", created, err)
+		}
+		_, _ = s.CreateWebhook(ctx, &model.Webhook{URL: "http://b"})
+
+		if _, err := s.UpdateWebhook(ctx, &model.Webhook{ID: 1, URL: "http://c"}); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		if _, err := s.UpdateWebhook(ctx, &model.Webhook{ID: 9}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+
+		list, _ := s.GetWebhooks(ctx)
+		if len(list) != 2 || list[0].URL != "http://c" || list[1].ID != 2 {
+			t.Errorf("unexpected webhooks %+v", list)
+		}
+
+		if err := s.DeleteWebhook(ctx, 1); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := s.GetWebhookByID(ctx, 1); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Dead letters are bounded", func(t *testing.T) {
+		s := NewWebhookInMemory(2)
+		for i := 0; i < 3; i++ {
+			_, _ = s.AddDeadLetter(ctx, &model.DeadLetter{WebhookID: 1})
+		}
+
+		list, _ := s.GetDeadLetters(ctx)
+		if len(list) != 2 || list[0].ID != 2 || list[1].ID != 3 {
+			t.Fatalf("expected dead letters 2 and 3, got %+v", list)
+		}
+
+		if err := s.DeleteDeadLetter(ctx, 2); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if _, err := s.GetDeadLetterByID(ctx, 2); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		if d, err := s.GetDeadLetterByID(ctx, 3); err != nil || d.ID != 3 {
+			t.Errorf("unexpected dead letter %+v, %v", d, err)
+		}
+	})
+}
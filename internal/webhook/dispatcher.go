@@ -0,0 +1,383 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const (
+	HeaderEvent     = "X-Quotebook-Event"
+	HeaderDelivery  = "X-Quotebook-Delivery"
+	HeaderTimestamp = "X-Quotebook-Timestamp"
+	HeaderSignature = "X-Quotebook-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+const (
+	DefaultWorkers     = 4
+	DefaultQueueSize   = 256
+	DefaultMaxAttempts = 5
+	DefaultBackoff     = time.Second
+	DefaultTimeout     = 10 * time.Second
+
+	maxBackoff = time.Minute
+)
+
+var (
+	ErrQueueFull = errors.New("delivery queue is full")
+	ErrStopped   = errors.New("dispatcher is stopped")
+)
+
+// delivery is one payload addressed to one webhook.
+type delivery struct {
+	webhookID int
+	id        string
+	eventType string
+	payload   []byte
+}
+
+// Dispatcher delivers quote events to webhooks from a bounded queue served
+// by a fixed number of workers. Failed attempts are retried with exponential
+// backoff; deliveries that exhaust their attempts, overflow the queue or are
+// interrupted by shutdown go to the dead-letter list.
+type Dispatcher struct {
+	store       storage.WebhookStorage
+	logger      *logger.Logger
+	client      *http.Client
+	workers     int
+	maxAttempts int
+	backoff     time.Duration
+
+	queue chan delivery
+
+	// ctx is cancelled when shutdown runs out of time, aborting retries.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.RWMutex
+	stopped bool
+
+	consumer sync.WaitGroup
+	pool     sync.WaitGroup
+}
+
+type Option func(*Dispatcher)
+
+func WithWorkers(n int) Option {
+	return func(d *Dispatcher) {
+		d.workers = n
+	}
+}
+
+func WithQueueSize(n int) Option {
+	return func(d *Dispatcher) {
+		d.queue = make(chan delivery, n)
+	}
+}
+
+// WithRetry sets the number of delivery attempts and the delay before the
+// first retry, each further retry waits twice as long.
+func WithRetry(maxAttempts int, backoff time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.maxAttempts = maxAttempts
+		d.backoff = backoff
+	}
+}
+
+func WithHTTPClient(c *http.Client) Option {
+	return func(d *Dispatcher) {
+		d.client = c
+	}
+}
+
+func NewDispatcher(store storage.WebhookStorage, logger *logger.Logger, opts ...Option) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		store:       store,
+		logger:      logger,
+		client:      &http.Client{Timeout: DefaultTimeout},
+		workers:     DefaultWorkers,
+		maxAttempts: DefaultMaxAttempts,
+		backoff:     DefaultBackoff,
+		queue:       make(chan delivery, DefaultQueueSize),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.workers < 1 {
+		d.workers = 1
+	}
+	if d.maxAttempts < 1 {
+		d.maxAttempts = 1
+	}
+	return d
+}
+
+// Start runs the workers and fans every event from the channel out to the
+// subscribed webhooks until the channel is closed.
+func (d *Dispatcher) Start(ch <-chan events.Event) {
+	for i := 0; i < d.workers; i++ {
+		d.pool.Add(1)
+		go func() {
+			defer d.pool.Done()
+			for job := range d.queue {
+				d.deliver(job)
+			}
+		}()
+	}
+
+	d.consumer.Add(1)
+	go func() {
+		defer d.consumer.Done()
+		for e := range ch {
+			d.dispatch(e)
+		}
+	}()
+}
+
+// Replay queues a dead letter again under its original delivery ID.
+func (d *Dispatcher) Replay(dl *model.DeadLetter) error {
+	return d.enqueue(delivery{
+		webhookID: dl.WebhookID,
+		id:        dl.DeliveryID,
+		eventType: dl.EventType,
+		payload:   dl.Payload,
+	})
+}
+
+// Shutdown waits for the event channel to close and queued deliveries to
+// finish. When ctx ends first, pending retries are abandoned and their
+// deliveries dead-lettered.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.consumer.Wait()
+
+		d.mu.Lock()
+		d.stopped = true
+		close(d.queue)
+		d.mu.Unlock()
+
+		d.pool.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) dispatch(e events.Event) {
+	webhooks, err := d.store.GetWebhooks(d.ctx)
+	if err != nil {
+		d.logger.Error().Err(err).Msg("failed to load webhooks")
+		return
+	}
+
+	var payload []byte
+	for _, w := range webhooks {
+		if !w.Subscribed(string(e.Type)) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(e); err != nil {
+				d.logger.Error().Err(err).Msg("failed to encode webhook payload")
+				return
+			}
+		}
+
+		job := delivery{
+			webhookID: w.ID,
+			id:        fmt.Sprintf("%d-%d", e.ID, w.ID),
+			eventType: string(e.Type),
+			payload:   payload,
+		}
+		if err := d.enqueue(job); err != nil {
+			d.deadLetter(job, 0, 0, err)
+		}
+	}
+}
+
+func (d *Dispatcher) enqueue(job delivery) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.stopped {
+		return ErrStopped
+	}
+
+	select {
+	case d.queue <- job:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// deliver attempts job until it succeeds, fails permanently or runs out of
+// attempts.
+func (d *Dispatcher) deliver(job delivery) {
+	log := d.logger.With().Int("webhook_id", job.webhookID).Str("delivery_id", job.id).Logger()
+
+	var (
+		status int
+		err    error
+	)
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		if attempt > 1 {
+			if waitErr := d.wait(d.retryDelay(attempt - 1)); waitErr != nil {
+				d.deadLetter(job, attempt-1, status, err)
+				return
+			}
+		}
+
+		var w *model.Webhook
+		w, err = d.store.GetWebhookByID(d.ctx, job.webhookID)
+		if errors.Is(err, storage.ErrNotFound) {
+			log.Debug().Msg("webhook removed, delivery dropped")
+			return
+		}
+		if err != nil {
+			continue
+		}
+
+		status, err = d.send(w, job)
+		if err == nil {
+			log.Debug().Int("attempt", attempt).Int("status", status).Msg("webhook delivered")
+			return
+		}
+
+		log.Warn().Err(err).Int("attempt", attempt).Int("status", status).Msg("webhook delivery failed")
+		if !retryable(status) {
+			d.deadLetter(job, attempt, status, err)
+			return
+		}
+	}
+
+	d.deadLetter(job, d.maxAttempts, status, err)
+}
+
+// send posts the payload once. The signature covers "<timestamp>.<body>" so
+// receivers can reject replayed requests with stale timestamps.
+func (d *Dispatcher) send(w *model.Webhook, job delivery) (int, error) {
+	ctx, cancel := context.WithTimeout(d.ctx, DefaultTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(job.payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "quotebook-webhooks/1")
+	req.Header.Set(HeaderEvent, job.eventType)
+	req.Header.Set(HeaderDelivery, job.id)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(w.Secret, timestamp, job.payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header value for a payload sent at timestamp.
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature produced by Sign in constant time.
+func Verify(secret, timestamp string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, payload)), []byte(signature))
+}
+
+// retryable reports whether a failed attempt may succeed later: network
+// errors, server errors, timeouts and rate limiting.
+func retryable(status int) bool {
+	return status == 0 ||
+		status >= http.StatusInternalServerError ||
+		status == http.StatusRequestTimeout ||
+		status == http.StatusTooManyRequests
+}
+
+// retryDelay doubles the backoff per retry, capped and with up to 20% jitter
+// so failing receivers are not hit by synchronised bursts.
+func (d *Dispatcher) retryDelay(retry int) time.Duration {
+	delay := d.backoff << (retry - 1)
+	if delay <= 0 || delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+}
+
+func (d *Dispatcher) wait(delay time.Duration) error {
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-d.ctx.Done():
+		return d.ctx.Err()
+	}
+}
+
+func (d *Dispatcher) deadLetter(job delivery, attempts, status int, err error) {
+	dl := &model.DeadLetter{
+		WebhookID:  job.webhookID,
+		DeliveryID: job.id,
+		EventType:  job.eventType,
+		Payload:    job.payload,
+		Attempts:   attempts,
+		LastStatus: status,
+		FailedAt:   time.Now().UTC(),
+	}
+	if err != nil {
+		dl.LastError = err.Error()
+	}
+
+	// The dispatcher context may already be cancelled during shutdown.
+	if _, storeErr := d.store.AddDeadLetter(context.Background(), dl); storeErr != nil {
+		d.logger.Error().Err(storeErr).Str("delivery_id", job.id).Msg("failed to store dead letter")
+		return
+	}
+	d.logger.Warn().Int("webhook_id", job.webhookID).Str("delivery_id", job.id).Int("attempts", attempts).Msg("webhook delivery dead-lettered")
+}
@@ -0,0 +1,206 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
+	"github.com/zonder12120/brandscout-quotebook/internal/model"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const testSecret = "0123456789abcdef"
+
+// receiver is an in-process webhook endpoint answering with scripted
+// statuses and recording every request it accepted.
+type receiver struct {
+	t        *testing.T
+	mu       sync.Mutex
+	statuses []int
+	calls    int
+	received chan events.Event
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, *httptest.Server) {
+	rec := &receiver{t: t, statuses: statuses, received: make(chan events.Event, 10)}
+	srv := httptest.NewServer(rec)
+	t.Cleanup(srv.Close)
+	return rec, srv
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	timestamp := r.Header.Get(HeaderTimestamp)
+	if !Verify(testSecret, timestamp, body, r.Header.Get(HeaderSignature)) {
+		rc.t.Errorf("invalid signature %q", r.Header.Get(HeaderSignature))
+	}
+	if ts, err := strconv.ParseInt(timestamp, 10, 64); err != nil || time.Since(time.Unix(ts, 0)) > time.Minute {
+		rc.t.Errorf("unexpected timestamp %q", timestamp)
+	}
+
+	rc.mu.Lock()
+	status := http.StatusOK
+	if rc.calls < len(rc.statuses) {
+		status = rc.statuses[rc.calls]
+	}
+	rc.calls++
+	rc.mu.Unlock()
+
+	w.WriteHeader(status)
+	if status == http.StatusOK {
+		var e events.Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			rc.t.Errorf("decode payload: %v", err)
+		}
+		if r.Header.Get(HeaderEvent) != string(e.Type) {
+			rc.t.Errorf("event header %q does not match payload %q", r.Header.Get(HeaderEvent), e.Type)
+		}
+		rc.received <- e
+	}
+}
+
+func (rc *receiver) callCount() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.calls
+}
+
+func newTestDispatcher(t *testing.T, store storage.WebhookStorage, opts ...Option) (*Dispatcher, chan events.Event) {
+	t.Helper()
+
+	opts = append([]Option{WithRetry(3, time.Millisecond)}, opts...)
+	d := NewDispatcher(store, logger.New("error", "console"), opts...)
+	ch := make(chan events.Event)
+	d.Start(ch)
+	return d, ch
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+	quote := model.Quote{ID: 1, Author: "Seneca", Quote: "Q"}
+
+	t.Run("Delivers signed payloads after retrying server errors", func(t *testing.T) {
+		rc, srv := newReceiver(t, http.StatusInternalServerError, http.StatusTooManyRequests)
+		store := storage.NewWebhookInMemory(10)
+		_, _ = store.CreateWebhook(ctx, &model.Webhook{URL: srv.URL, Secret: testSecret})
+		_, _ = store.CreateWebhook(ctx, &model.Webhook{URL: srv.URL, Secret: testSecret, Events: []string{"deleted"}})
+
+		d, ch := newTestDispatcher(t, store)
+		ch <- events.Event{ID: 7, Type: events.QuoteCreated, QuoteID: 1, Quote: quote}
+		close(ch)
+
+		select {
+		case e := <-rc.received:
+			if e.ID != 7 || e.Quote.Author != "Seneca" {
+				t.Errorf("unexpected event %+v", e)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("event not delivered")
+		}
+
+		if err := d.Shutdown(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if rc.callCount() != 3 {
+			t.Errorf("expected 3 attempts, got %d", rc.callCount())
+		}
+		if dead, _ := store.GetDeadLetters(ctx); len(dead) != 0 {
+			t.Errorf("unexpected dead letters %+v", dead)
+		}
+	})
+
+	t.Run("Dead-letters failed deliveries and replays them", func(t *testing.T) {
+		rc, srv := newReceiver(t, http.StatusBadRequest)
+		store := storage.NewWebhookInMemory(10)
+		w, _ := store.CreateWebhook(ctx, &model.Webhook{URL: srv.URL, Secret: testSecret})
+
+		d, ch := newTestDispatcher(t, store)
+		ch <- events.Event{ID: 1, Type: events.QuoteEvicted, QuoteID: 1, Quote: quote}
+
+		var dead []*model.DeadLetter
+		waitFor(t, func() bool {
+			dead, _ = store.GetDeadLetters(ctx)
+			return len(dead) == 1
+		})
+
+		dl := dead[0]
+		if dl.WebhookID != w.ID || dl.Attempts != 1 || dl.LastStatus != http.StatusBadRequest || dl.EventType != "evicted" {
+			t.Errorf("unexpected dead letter %+v", dl)
+		}
+
+		if err := d.Replay(dl); err != nil {
+			t.Fatalf("replay: %v", err)
+		}
+		select {
+		case e := <-rc.received:
+			if e.Type != events.QuoteEvicted {
+				t.Errorf("unexpected replayed event %+v", e)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("replay not delivered")
+		}
+
+		close(ch)
+		if err := d.Shutdown(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Replay(dl); err != ErrStopped {
+			t.Errorf("expected ErrStopped after shutdown, got %v", err)
+		}
+	})
+
+	t.Run("Shutdown timeout dead-letters pending retries", func(t *testing.T) {
+		_, srv := newReceiver(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+		store := storage.NewWebhookInMemory(10)
+		_, _ = store.CreateWebhook(ctx, &model.Webhook{URL: srv.URL, Secret: testSecret})
+
+		d, ch := newTestDispatcher(t, store, WithRetry(3, time.Hour))
+		ch <- events.Event{ID: 1, Type: events.QuoteCreated, QuoteID: 1, Quote: quote}
+		close(ch)
+
+		shutdownCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		if err := d.Shutdown(shutdownCtx); err == nil {
+			t.Error("expected shutdown to time out")
+		}
+
+		dead, _ := store.GetDeadLetters(ctx)
+		if len(dead) != 1 || dead[0].Attempts != 1 {
+			t.Errorf("expected 1 dead letter after 1 attempt, got %+v", dead)
+		}
+	})
+}
+
+func TestSign(t *testing.T) {
+	sig := Sign("secret", "1700000000", []byte(`{"id":1}`))
+	if !Verify("secret", "1700000000", []byte(`{"id":1}`), sig) {
+		t.Error("expected signature to verify")
+	}
+	if Verify("secret", "1700000001", []byte(`{"id":1}`), sig) {
+		t.Error("expected a different timestamp to fail verification")
+	}
+	if Verify("other", "1700000000", []byte(`{"id":1}`), sig) {
+		t.Error("expected a different secret to fail verification")
+	}
+}
@@ -1,10 +1,16 @@
 package logger
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -14,20 +20,50 @@ const (
 	LevelError = "error"
 )
 
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+)
+
+const (
+	timeKey    = "time"
+	levelKey   = "level"
+	messageKey = "msg"
+)
+
 type Logger struct {
 	levelPriority int
 	levels        map[string]int
+	format        string
+	fields        []field
 	stdout        *log.Logger
 	stderr        *log.Logger
 }
 
+// Context accumulates fields bound to a child logger, see Logger.With.
+type Context struct {
+	logger *Logger
+	fields []field
+}
+
 type Event struct {
 	logger *Logger
 	level  string
-	fields map[string]interface{}
+	fields []field
+}
+
+type field struct {
+	key string
+	val interface{}
 }
 
-func New(level string) *Logger {
+// New creates a logger writing to stdout (stderr for errors). Unknown levels
+// fall back to info, unknown formats fall back to console.
+func New(level, format string) *Logger {
+	return newLogger(level, format, os.Stdout, os.Stderr)
+}
+
+func newLogger(level, format string, stdout, stderr io.Writer) *Logger {
 	levelOrder := []string{LevelDebug, LevelInfo, LevelWarn, LevelError}
 	levels := make(map[string]int, len(levelOrder))
 	for i, lvl := range levelOrder {
@@ -40,16 +76,28 @@ func New(level string) *Logger {
 		priority = levels[LevelInfo]
 	}
 
+	// JSON lines carry their own timestamp, console lines keep the stdlib prefix.
+	flags := log.LstdFlags
+	normalizedFormat := strings.ToLower(strings.TrimSpace(format))
+	if normalizedFormat == FormatJSON {
+		flags = 0
+	} else {
+		normalizedFormat = FormatConsole
+	}
+
 	return &Logger{
 		levelPriority: priority,
 		levels:        levels,
-		stdout:        log.New(os.Stdout, "", log.LstdFlags),
-		stderr:        log.New(os.Stderr, "", log.LstdFlags),
+		format:        normalizedFormat,
+		stdout:        log.New(stdout, "", flags),
+		stderr:        log.New(stderr, "", flags),
 	}
 }
 
+// isLevelEnabled reports false for a nil logger, so a missing logger from
+// FromContext(ctx, nil) silently discards events.
 func (l *Logger) isLevelEnabled(level string) bool {
-	return l.levelPriority <= l.levels[level]
+	return l != nil && l.levelPriority <= l.levels[level]
 }
 
 func (l *Logger) Debug() *Event {
@@ -75,27 +123,104 @@ func (l *Logger) newEvent(level string) *Event {
 	return &Event{
 		logger: l,
 		level:  level,
-		fields: make(map[string]interface{}),
+		fields: append([]field(nil), l.fields...),
 	}
 }
 
-func (e *Event) Str(key, val string) *Event {
-	if e != nil {
-		e.fields[key] = val
+// With starts a child logger whose fields are added to every event it emits.
+func (l *Logger) With() *Context {
+	if l == nil {
+		return &Context{}
+	}
+	return &Context{
+		logger: l,
+		fields: append([]field(nil), l.fields...),
 	}
-	return e
 }
 
-func (e *Event) Int(key string, val int) *Event {
+func (c *Context) add(key string, val interface{}) *Context {
+	c.fields = append(c.fields, field{key: key, val: val})
+	return c
+}
+
+func (c *Context) Str(key, val string) *Context {
+	return c.add(key, val)
+}
+
+func (c *Context) Int(key string, val int) *Context {
+	return c.add(key, val)
+}
+
+func (c *Context) Bool(key string, val bool) *Context {
+	return c.add(key, val)
+}
+
+func (c *Context) Any(key string, val interface{}) *Context {
+	return c.add(key, val)
+}
+
+func (c *Context) Logger() *Logger {
+	if c.logger == nil {
+		return nil
+	}
+	child := *c.logger
+	child.fields = c.fields
+	return &child
+}
+
+type ctxKey struct{}
+
+// WithContext returns a copy of ctx carrying l.
+func WithContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx or fallback if there is none.
+func FromContext(ctx context.Context, fallback *Logger) *Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*Logger); ok && l != nil {
+		return l
+	}
+	return fallback
+}
+
+func (e *Event) add(key string, val interface{}) *Event {
 	if e != nil {
-		e.fields[key] = val
+		e.fields = append(e.fields, field{key: key, val: val})
 	}
 	return e
 }
 
+func (e *Event) Str(key, val string) *Event {
+	return e.add(key, val)
+}
+
+func (e *Event) Int(key string, val int) *Event {
+	return e.add(key, val)
+}
+
+func (e *Event) Bool(key string, val bool) *Event {
+	return e.add(key, val)
+}
+
+func (e *Event) Float(key string, val float64) *Event {
+	return e.add(key, val)
+}
+
+func (e *Event) Dur(key string, val time.Duration) *Event {
+	return e.add(key, val)
+}
+
+func (e *Event) Time(key string, val time.Time) *Event {
+	return e.add(key, val)
+}
+
+func (e *Event) Any(key string, val interface{}) *Event {
+	return e.add(key, val)
+}
+
 func (e *Event) Err(err error) *Event {
-	if e != nil && err != nil {
-		e.fields[LevelError] = err.Error()
+	if err != nil {
+		return e.add(LevelError, err.Error())
 	}
 	return e
 }
@@ -105,9 +230,12 @@ func (e *Event) Msg(msg string) {
 		return
 	}
 
-	out := fmt.Sprintf("[%s] %s", strings.ToUpper(e.level), msg)
-	for k, v := range e.fields {
-		out += fmt.Sprintf(" | %s=%v", k, v)
+	var out string
+	switch e.logger.format {
+	case FormatJSON:
+		out = e.encodeJSON(time.Now(), msg)
+	default:
+		out = e.encodeConsole(msg)
 	}
 
 	switch e.level {
@@ -123,3 +251,78 @@ func (e *Event) Msgf(format string, args ...interface{}) {
 		e.Msg(fmt.Sprintf(format, args...))
 	}
 }
+
+func (e *Event) encodeConsole(msg string) string {
+	var b strings.Builder
+	b.WriteString("[")
+	b.WriteString(strings.ToUpper(e.level))
+	b.WriteString("] ")
+	b.WriteString(msg)
+
+	for _, f := range e.fields {
+		b.WriteString(" | ")
+		b.WriteString(f.key)
+		b.WriteString("=")
+		b.WriteString(consoleValue(f.val))
+	}
+
+	return b.String()
+}
+
+// encodeJSON writes time, level and msg first, then fields in the order they
+// were added, so lines are stable for log pipelines.
+func (e *Event) encodeJSON(ts time.Time, msg string) string {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	writeJSONField(&b, timeKey, ts.Format(time.RFC3339Nano))
+	b.WriteByte(',')
+	writeJSONField(&b, levelKey, e.level)
+	b.WriteByte(',')
+	writeJSONField(&b, messageKey, msg)
+
+	for _, f := range e.fields {
+		b.WriteByte(',')
+		writeJSONField(&b, f.key, jsonValue(f.val))
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+func writeJSONField(b *bytes.Buffer, key string, val interface{}) {
+	b.Write(marshal(key))
+	b.WriteByte(':')
+	b.Write(marshal(val))
+}
+
+func marshal(val interface{}) []byte {
+	data, err := json.Marshal(val)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprintf("%v", val))
+	}
+	return data
+}
+
+func jsonValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case time.Duration:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
+
+func consoleValue(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger(t *testing.T) {
+	t.Run("JSON format", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		l := newLogger(LevelDebug, FormatJSON, &stdout, &stderr)
+
+		l.Info().
+			Str("method", "GET").
+			Int("status", 200).
+			Bool("cached", true).
+			Float("ratio", 0.5).
+			Dur("duration", 1500*time.Millisecond).
+			Any("tags", []string{"a", "b"}).
+			Msg("request completed")
+
+		line := strings.TrimSpace(stdout.String())
+		if stderr.Len() != 0 {
+			t.Errorf("expected empty stderr, got %q", stderr.String())
+		}
+
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("expected valid JSON, got %v: %s", err, line)
+		}
+
+		if _, err := time.Parse(time.RFC3339Nano, entry["time"].(string)); err != nil {
+			t.Errorf("expected RFC 3339 time, got %v", entry["time"])
+		}
+		if entry["level"] != LevelInfo {
+			t.Errorf("expected level info, got %v", entry["level"])
+		}
+		if entry["status"] != float64(200) {
+			t.Errorf("expected status 200, got %v", entry["status"])
+		}
+		if entry["cached"] != true {
+			t.Errorf("expected cached true, got %v", entry["cached"])
+		}
+		if entry["duration"] != "1.5s" {
+			t.Errorf("expected duration 1.5s, got %v", entry["duration"])
+		}
+
+		order := []string{`"time"`, `"level"`, `"msg"`, `"method"`, `"status"`, `"cached"`, `"ratio"`, `"duration"`, `"tags"`}
+		last := -1
+		for _, key := range order {
+			idx := strings.Index(line, key)
+			if idx <= last {
+				t.Fatalf("expected stable field order %v, got %s", order, line)
+			}
+			last = idx
+		}
+	})
+
+	t.Run("Console format keeps field order", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		l := newLogger(LevelInfo, FormatConsole, &stdout, &stderr)
+
+		l.Error().Err(errors.New("boom")).Str("a", "1").Int("b", 2).Msg("failed")
+
+		out := stderr.String()
+		if !strings.HasSuffix(strings.TrimSpace(out), "[ERROR] failed | error=boom | a=1 | b=2") {
+			t.Errorf("unexpected console line: %q", out)
+		}
+	})
+
+	t.Run("Level filtering", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		l := newLogger(LevelWarn, FormatJSON, &stdout, &stderr)
+
+		l.Info().Str("k", "v").Msg("skipped")
+		if stdout.Len() != 0 {
+			t.Errorf("expected info to be filtered, got %q", stdout.String())
+		}
+	})
+
+	t.Run("Unknown format falls back to console", func(t *testing.T) {
+		l := newLogger(LevelInfo, "xml", &bytes.Buffer{}, &bytes.Buffer{})
+		if l.format != FormatConsole {
+			t.Errorf("expected console format, got %s", l.format)
+		}
+	})
+
+	t.Run("With binds fields to child logger", func(t *testing.T) {
+		var stdout bytes.Buffer
+		parent := newLogger(LevelInfo, FormatConsole, &stdout, &bytes.Buffer{})
+		child := parent.With().Str("request_id", "abc").Logger()
+
+		child.Info().Int("n", 1).Msg("child")
+		parent.Info().Msg("parent")
+
+		lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("expected 2 lines, got %d", len(lines))
+		}
+		if !strings.HasSuffix(lines[0], "[INFO] child | request_id=abc | n=1") {
+			t.Errorf("unexpected child line: %q", lines[0])
+		}
+		if strings.Contains(lines[1], "request_id") {
+			t.Errorf("parent logger must not inherit child fields: %q", lines[1])
+		}
+	})
+
+	t.Run("Context propagation", func(t *testing.T) {
+		fallback := newLogger(LevelInfo, FormatConsole, &bytes.Buffer{}, &bytes.Buffer{})
+		if FromContext(context.Background(), fallback) != fallback {
+			t.Error("expected fallback logger for empty context")
+		}
+
+		child := fallback.With().Str("k", "v").Logger()
+		ctx := WithContext(context.Background(), child)
+		if FromContext(ctx, fallback) != child {
+			t.Error("expected logger stored in context")
+		}
+	})
+}
@@ -0,0 +1,264 @@
+// Package quoteclient is a Go client for the quotebook REST API.
+//
+// Every call takes a context and is retried with exponential backoff when
+// the server answers 429 or 5xx, see WithRetry. Failed calls return *Error,
+// which matches the sentinel errors of this package with errors.Is.
+package quoteclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultUserAgent   = "quoteclient"
+	DefaultMaxAttempts = 3
+	DefaultRetryDelay  = 200 * time.Millisecond
+
+	// maxRetryDelay caps both the backoff and a server's Retry-After.
+	maxRetryDelay = 30 * time.Second
+
+	apiPrefix = "/v1"
+)
+
+type Client struct {
+	baseURL     *url.URL
+	httpClient  *http.Client
+	apiKey      string
+	userAgent   string
+	maxAttempts int
+	retryDelay  time.Duration
+
+	// quotesPath and moderationPath are where the quote and the review
+	// methods are served, see Collection.
+	quotesPath     string
+	moderationPath string
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces http.DefaultClient, e.g. to set a timeout or a
+// custom transport. A client timeout also ends event streams.
+func WithHTTPClient(c *http.Client) Option {
+	return func(cl *Client) {
+		cl.httpClient = c
+	}
+}
+
+// WithAPIKey sends key as a bearer token with every request.
+func WithAPIKey(key string) Option {
+	return func(cl *Client) {
+		cl.apiKey = key
+	}
+}
+
+func WithUserAgent(ua string) Option {
+	return func(cl *Client) {
+		cl.userAgent = ua
+	}
+}
+
+// WithRetry makes a call up to maxAttempts times, 1 disables retries. The
+// n-th retry waits about delay*2^(n-1) unless the server asks for longer
+// with Retry-After.
+func WithRetry(maxAttempts int, delay time.Duration) Option {
+	return func(cl *Client) {
+		cl.maxAttempts = max(maxAttempts, 1)
+		cl.retryDelay = delay
+	}
+}
+
+// New returns a client for the server at baseURL, e.g.
+// "http://localhost:8080". API paths are resolved under its /v1 prefix.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: expected http(s)://host", baseURL)
+	}
+
+	c := &Client{
+		baseURL:        u,
+		httpClient:     http.DefaultClient,
+		userAgent:      DefaultUserAgent,
+		maxAttempts:    DefaultMaxAttempts,
+		retryDelay:     DefaultRetryDelay,
+		quotesPath:     "/quotes",
+		moderationPath: "/moderation",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// request describes a call, it is rebuilt for every attempt.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   any
+	accept string
+}
+
+// do sends a JSON API call and decodes the response into out unless out is
+// nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) (*http.Response, error) {
+	resp, err := c.send(ctx, request{method: method, path: path, query: query, body: in, accept: "application/json"})
+	if err != nil {
+		return resp, err
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, fmt.Errorf("decode response: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+// fetch returns the raw body of a non-JSON resource such as a feed or an
+// image.
+func (c *Client) fetch(ctx context.Context, path string, query url.Values, accept string) ([]byte, error) {
+	resp, err := c.send(ctx, request{method: http.MethodGet, path: path, query: query, accept: accept})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+// send performs req with retries and returns the response of a 2xx status
+// with its body open. Other statuses are returned as *Error.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	var body []byte
+	if req.body != nil {
+		b, err := json.Marshal(req.body)
+		if err != nil {
+			return nil, fmt.Errorf("encode request: %w", err)
+		}
+		body = b
+	}
+
+	u := *c.baseURL
+	u.Path += apiPrefix + req.path
+	u.RawQuery = req.query.Encode()
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(ctx, req, u.String(), body)
+
+		var wait time.Duration
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return nil, ctx.Err()
+		case err != nil:
+			// The server may have processed a request lost on the way
+			// back, only idempotent ones are sent again.
+			if !idempotent(req.method) || attempt >= c.maxAttempts {
+				return nil, err
+			}
+			wait = c.backoff(attempt)
+		case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+			return resp, nil
+		default:
+			apiErr := newError(resp)
+			_ = resp.Body.Close()
+			if !retryable(req.method, resp.StatusCode) || attempt >= c.maxAttempts {
+				return resp, apiErr
+			}
+			wait = max(c.backoff(attempt), retryAfter(resp.Header.Get("Retry-After")))
+		}
+
+		timer := time.NewTimer(min(wait, maxRetryDelay))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, req request, target string, body []byte) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, r)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range req.header {
+		httpReq.Header[k] = v
+	}
+	httpReq.Header.Set("Accept", req.accept)
+	httpReq.Header.Set("User-Agent", c.userAgent)
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	return c.httpClient.Do(httpReq)
+}
+
+// backoff is the exponential delay before retry n with jitter, so clients
+// failing together do not retry together.
+func (c *Client) backoff(n int) time.Duration {
+	if c.retryDelay <= 0 {
+		return 0
+	}
+	d := c.retryDelay << (n - 1)
+	if d <= 0 || d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+func idempotent(method string) bool {
+	return method != http.MethodPost && method != http.MethodPatch
+}
+
+// retryable reports whether a failed call may succeed when repeated. 429
+// and 503 mean the request was not processed, so even a POST is safe to
+// repeat; other 5xx are retried for idempotent methods only.
+func retryable(method string, status int) bool {
+	switch {
+	case status == http.StatusTooManyRequests, status == http.StatusServiceUnavailable:
+		return true
+	case status >= 500:
+		return idempotent(method)
+	default:
+		return false
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds or as a date.
+func retryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(v); err == nil && s > 0 {
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
@@ -0,0 +1,524 @@
+package quoteclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zonder12120/brandscout-quotebook/internal/card"
+	"github.com/zonder12120/brandscout-quotebook/internal/events"
+	"github.com/zonder12120/brandscout-quotebook/internal/rest"
+	"github.com/zonder12120/brandscout-quotebook/internal/rest/handler"
+	"github.com/zonder12120/brandscout-quotebook/internal/service"
+	"github.com/zonder12120/brandscout-quotebook/internal/storage"
+	"github.com/zonder12120/brandscout-quotebook/pkg/logger"
+)
+
+const (
+	testAdminKey    = "admin-key"
+	testReviewerKey = "reviewer-key"
+)
+
+// newTestAPI serves the real router with every optional API enabled.
+func newTestAPI(t *testing.T) http.Handler {
+	t.Helper()
+
+	log := logger.New("error", "console")
+	bus := events.NewBus()
+	svc := service.NewQuoteService(storage.NewInMemory(100), service.WithPublisher(bus))
+	renderer, err := card.NewRenderer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rest.NewRouter(handler.New(svc, log), log,
+		rest.WithEvents(handler.NewEvents(bus, log)),
+		rest.WithWebhooks(handler.NewWebhooks(service.NewWebhookService(storage.NewWebhookInMemory(10), nil, service.WithWebhookAdminKey(testAdminKey)), log)),
+		rest.WithFeeds(handler.NewFeeds(svc, log)),
+		rest.WithImages(handler.NewImages(svc, renderer, log)),
+		rest.WithCollections(handler.NewCollections(service.NewCollectionService(storage.NewCollectionInMemory(),
+			service.WithAdminKey(testAdminKey),
+		), log)),
+	)
+}
+
+func newTestClient(t *testing.T, h http.Handler, opts ...Option) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	c, err := New(srv.URL, append([]Option{WithRetry(3, time.Millisecond)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestQuotes(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t, newTestAPI(t))
+
+	for i, author := range []string{"Seneca", "Seneca", "Marcus Aurelius", "Epictetus", "Seneca"} {
+		q, err := c.Create(ctx, NewQuote{Author: author, Quote: "Quote " + string(rune('A'+i)), Tags: []string{"stoic"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q.ID != i+1 || q.CreatedAt.IsZero() {
+			t.Fatalf("unexpected quote %+v", q)
+		}
+	}
+
+	t.Run("Get, random and by author", func(t *testing.T) {
+		q, err := c.Get(ctx, 3)
+		if err != nil || q.Author != "Marcus Aurelius" {
+			t.Errorf("expected quote 3, got %+v %v", q, err)
+		}
+		if q, err := c.Random(ctx); err != nil || q.ID == 0 {
+			t.Errorf("expected a random quote, got %+v %v", q, err)
+		}
+		if quotes, err := c.ByAuthor(ctx, "seneca"); err != nil || len(quotes) != 3 {
+			t.Errorf("expected 3 quotes of Seneca, got %d %v", len(quotes), err)
+		}
+	})
+
+	t.Run("Language", func(t *testing.T) {
+		if _, err := c.RandomIn(ctx, "la"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+
+		created, err := c.Create(ctx, NewQuote{Author: "Seneca", Quote: "Errare humanum est", Lang: "la"})
+		if err != nil || created.Lang != "la" {
+			t.Fatalf("expected a quote in la, got %+v %v", created, err)
+		}
+		t.Cleanup(func() { _ = c.Delete(ctx, created.ID) })
+
+		if q, err := c.RandomIn(ctx, "la"); err != nil || q.ID != created.ID {
+			t.Errorf("expected quote %d, got %+v %v", created.ID, q, err)
+		}
+		page, err := c.List(ctx, ListOptions{Lang: "la"})
+		if err != nil || len(page.Quotes) != 1 {
+			t.Errorf("expected 1 quote in la, got %+v %v", page, err)
+		}
+
+		if _, err := c.AddTranslation(ctx, created.ID, Translation{Lang: "fi", Quote: "Erehtyminen on inhimillistä", Translator: "Anon"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.AddTranslation(ctx, created.ID, Translation{Lang: "fi", Quote: "Q"}); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+		if q, err := c.RandomIn(ctx, "fi"); err != nil || q.Quote != "Erehtyminen on inhimillistä" || q.TranslatedFrom != "la" {
+			t.Errorf("expected the Finnish translation, got %+v %v", q, err)
+		}
+		if err := c.DeleteTranslation(ctx, created.ID, "fi"); err != nil {
+			t.Fatal(err)
+		}
+		if translations, err := c.Translations(ctx, created.ID); err != nil || len(translations) != 0 {
+			t.Errorf("expected no translations, got %v %v", translations, err)
+		}
+	})
+
+	t.Run("Pages", func(t *testing.T) {
+		page, err := c.List(ctx, ListOptions{Limit: 2})
+		if err != nil || len(page.Quotes) != 2 || page.Next == "" {
+			t.Fatalf("expected a first page with a cursor, got %+v %v", page, err)
+		}
+
+		var ids []int
+		for q, err := range c.All(ctx, ListOptions{Limit: 2}) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, q.ID)
+		}
+		if len(ids) != 5 || ids[4] != 5 {
+			t.Errorf("expected all 5 quotes in order, got %v", ids)
+		}
+
+		n := 0
+		for range c.All(ctx, ListOptions{Limit: 2, Query: "quote"}) {
+			if n++; n == 3 {
+				break
+			}
+		}
+		if n != 3 {
+			t.Errorf("expected iteration to stop after 3 quotes, got %d", n)
+		}
+	})
+
+	t.Run("Errors are typed", func(t *testing.T) {
+		_, err := c.Get(ctx, 42)
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected a not found *Error, got %v", err)
+		}
+		if apiErr.Code != CodeNotFound || apiErr.RequestID == "" {
+			t.Errorf("expected problem details, got %+v", apiErr)
+		}
+
+		_, err = c.Create(ctx, NewQuote{Author: "", Quote: "Q"})
+		if !errors.Is(err, ErrValidation) || !errors.Is(err, ErrBadRequest) || !errors.As(err, &apiErr) || apiErr.Errors[0].Field != "author" {
+			t.Errorf("expected a validation error on author, got %v", err)
+		}
+
+		if _, err := c.List(ctx, ListOptions{After: "bogus"}); !errors.Is(err, ErrBadRequest) {
+			t.Errorf("expected a bad request, got %v", err)
+		}
+	})
+
+	t.Run("Likes and ratings", func(t *testing.T) {
+		if _, err := c.Like(ctx, 3, "alice"); err != nil {
+			t.Fatal(err)
+		}
+		if p, err := c.Like(ctx, 3, "alice"); err != nil || p.Likes != 1 {
+			t.Errorf("expected one like per user, got %+v %v", p, err)
+		}
+		if p, err := c.Rate(ctx, 3, "bob", 4); err != nil || p.AverageRating != 4 {
+			t.Errorf("expected rating 4, got %+v %v", p, err)
+		}
+		if _, err := c.Rate(ctx, 3, "bob", 9); !errors.Is(err, ErrValidation) {
+			t.Errorf("expected a validation error, got %v", err)
+		}
+
+		ranked, err := c.Top(ctx, 5)
+		if err != nil || len(ranked) != 1 || ranked[0].Quote.ID != 3 || ranked[0].Popularity.Score == 0 {
+			t.Errorf("expected quote 3 on top, got %+v %v", ranked, err)
+		}
+		if q, err := c.RandomWeighted(ctx); err != nil || q.ID == 0 {
+			t.Errorf("expected a random quote, got %+v %v", q, err)
+		}
+
+		if _, err := c.Unlike(ctx, 3, "alice"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Unrate(ctx, 3, "bob"); err != nil {
+			t.Fatal(err)
+		}
+		if p, err := c.Popularity(ctx, 3); err != nil || p.Likes != 0 || p.Ratings != 0 {
+			t.Errorf("expected no votes left, got %+v %v", p, err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := c.Delete(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Delete(ctx, 1); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected not found, got %v", err)
+		}
+	})
+}
+
+func TestWebhooks(t *testing.T) {
+	ctx := context.Background()
+	api := newTestAPI(t)
+	c := newTestClient(t, api, WithAPIKey(testAdminKey))
+
+	if _, err := newTestClient(t, api).ListWebhooks(ctx); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected webhooks to require a key, got %v", err)
+	}
+
+	created, err := c.CreateWebhook(ctx, WebhookInput{URL: "https://example.com/hook", Events: []string{EventCreated}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == 0 || created.Secret == "" {
+		t.Fatalf("expected an ID and a generated secret, got %+v", created)
+	}
+
+	updated, err := c.UpdateWebhook(ctx, created.ID, WebhookInput{URL: "https://example.com/other"})
+	if err != nil || updated.URL != "https://example.com/other" || updated.Secret != "" {
+		t.Errorf("unexpected update %+v %v", updated, err)
+	}
+	if got, err := c.GetWebhook(ctx, created.ID); err != nil || got.URL != updated.URL {
+		t.Errorf("unexpected webhook %+v %v", got, err)
+	}
+	if list, err := c.ListWebhooks(ctx); err != nil || len(list) != 1 {
+		t.Errorf("expected 1 webhook, got %d %v", len(list), err)
+	}
+
+	if dead, err := c.ListDeadLetters(ctx); err != nil || len(dead) != 0 {
+		t.Errorf("expected no dead letters, got %d %v", len(dead), err)
+	}
+	if err := c.ReplayDeadLetter(ctx, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if err := c.DeleteDeadLetter(ctx, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	if err := c.DeleteWebhook(ctx, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetWebhook(ctx, created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestCollections(t *testing.T) {
+	ctx := context.Background()
+	api := newTestAPI(t)
+	admin := newTestClient(t, api, WithAPIKey(testAdminKey))
+
+	if _, err := admin.CreateCollection(ctx, NewCollection{Name: "team-a", QuotesLimit: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := admin.CreateCollection(ctx, NewCollection{Name: "team-a"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected conflict, got %v", err)
+	}
+	if list, err := admin.ListCollections(ctx); err != nil || len(list) != 1 || list[0].QuotesLimit != 10 {
+		t.Errorf("unexpected collections %+v %v", list, err)
+	}
+
+	key, err := admin.CreateAPIKey(ctx, "team-a", NewAPIKey{Name: "reader", Permissions: []string{PermissionRead}})
+	if err != nil || key.Key == "" {
+		t.Fatalf("expected an issued key, got %+v %v", key, err)
+	}
+	if keys, err := admin.ListAPIKeys(ctx, "team-a"); err != nil || len(keys) != 1 || keys[0].Key != "" {
+		t.Errorf("unexpected keys %+v %v", keys, err)
+	}
+
+	created, err := admin.Collection("team-a").Create(ctx, NewQuote{Author: "Seneca", Quote: "Begin at once to live."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := admin.Get(ctx, created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the quote to stay out of the default collection, got %v", err)
+	}
+	if _, err := admin.Collection("team-a").Submit(ctx, created.ID); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected the published quote of the collection to be refused, got %v", err)
+	}
+
+	reader := newTestClient(t, api, WithAPIKey(key.Key)).Collection("team-a")
+	if got, err := reader.Random(ctx); err != nil || got.ID != created.ID {
+		t.Errorf("unexpected quote %+v %v", got, err)
+	}
+	if _, err := reader.Create(ctx, NewQuote{Author: "A", Quote: "Q"}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected forbidden for a read-only key, got %v", err)
+	}
+	if _, err := newTestClient(t, api).Collection("team-a").Get(ctx, created.ID); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected unauthorized without a key, got %v", err)
+	}
+
+	if err := admin.DeleteAPIKey(ctx, "team-a", key.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.Get(ctx, created.ID); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected a revoked key to be refused, got %v", err)
+	}
+	if err := admin.DeleteCollection(ctx, "team-a"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestModeration(t *testing.T) {
+	ctx := context.Background()
+	log := logger.New("error", "console")
+	svc := service.NewQuoteService(storage.NewInMemory(10), service.WithModeration(testReviewerKey))
+	api := rest.NewRouter(handler.New(svc, log), log, rest.WithModeration(handler.NewModeration(svc, log)))
+	c := newTestClient(t, api)
+	reviewer := newTestClient(t, api, WithAPIKey(testReviewerKey))
+
+	pending, err := c.Create(ctx, NewQuote{Author: "Seneca", Quote: "Begin at once to live."})
+	if err != nil || pending.Status != StatusPending {
+		t.Fatalf("expected a pending quote, got %+v %v", pending, err)
+	}
+	draft, err := c.Create(ctx, NewQuote{Author: "Seneca", Quote: "Luck is preparation.", Status: StatusDraft})
+	if err != nil || draft.Status != StatusDraft {
+		t.Fatalf("expected a draft, got %+v %v", draft, err)
+	}
+	if _, err := c.Random(ctx); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected no published quotes, got %v", err)
+	}
+	if _, err := c.Pending(ctx, ListOptions{}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected unauthorized without a key, got %v", err)
+	}
+
+	if q, err := c.Submit(ctx, draft.ID); err != nil || q.Status != StatusPending {
+		t.Fatalf("unexpected submission %+v %v", q, err)
+	}
+	page, err := reviewer.Pending(ctx, ListOptions{Limit: 1})
+	if err != nil || len(page.Quotes) != 1 || page.Quotes[0].ID != pending.ID || page.Next == "" {
+		t.Fatalf("unexpected queue %+v %v", page, err)
+	}
+
+	if q, err := reviewer.Approve(ctx, pending.ID); err != nil || q.Status != StatusPublished || q.ReviewedAt.IsZero() {
+		t.Errorf("unexpected approval %+v %v", q, err)
+	}
+	if q, err := reviewer.Reject(ctx, draft.ID, "Misattributed."); err != nil || q.RejectReason != "Misattributed." {
+		t.Errorf("unexpected rejection %+v %v", q, err)
+	}
+	if _, err := reviewer.Approve(ctx, draft.ID); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected conflict for a rejected quote, got %v", err)
+	}
+	if _, err := c.Get(ctx, draft.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the rejected quote to be hidden, got %v", err)
+	}
+	if q, err := reviewer.GetForReview(ctx, draft.ID); err != nil || q.Status != StatusRejected {
+		t.Errorf("expected the rejected quote for review, got %+v %v", q, err)
+	}
+	if got, err := c.Random(ctx); err != nil || got.ID != pending.ID {
+		t.Errorf("expected the approved quote, got %+v %v", got, err)
+	}
+}
+
+func TestFeedsAndImages(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t, newTestAPI(t))
+
+	if _, err := c.Create(ctx, NewQuote{Author: "Сенека", Quote: "Пока мы учим, мы учимся."}); err != nil {
+		t.Fatal(err)
+	}
+
+	rss, err := c.Feed(ctx, FeedRSS, FeedOptions{Author: "Сенека"})
+	if err != nil || !bytes.Contains(rss, []byte("<rss")) || !bytes.Contains(rss, []byte("учимся")) {
+		t.Errorf("expected an RSS feed with the quote, got %v", err)
+	}
+	if atom, err := c.Feed(ctx, FeedAtom, FeedOptions{}); err != nil || !bytes.Contains(atom, []byte("<feed")) {
+		t.Errorf("expected an Atom feed, got %v", err)
+	}
+
+	png, err := c.Image(ctx, 1, ImagePNG, ImageOptions{Width: 400, Height: 400, Theme: "dark"})
+	if err != nil || !bytes.HasPrefix(png, []byte("\x89PNG")) {
+		t.Errorf("expected a PNG, got %v", err)
+	}
+	if svg, err := c.Image(ctx, 1, ImageSVG, ImageOptions{}); err != nil || !bytes.HasPrefix(svg, []byte("<svg")) {
+		t.Errorf("expected an SVG, got %v", err)
+	}
+	if _, err := c.Image(ctx, 1, ImagePNG, ImageOptions{Theme: "neon"}); !errors.Is(err, ErrBadRequest) {
+		t.Errorf("expected a bad request, got %v", err)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := newTestClient(t, newTestAPI(t))
+
+	stream, err := c.Events(ctx, EventOptions{Tag: "stoic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	if _, err := c.Create(ctx, NewQuote{Author: "A", Quote: "Untagged"}); err != nil {
+		t.Fatal(err)
+	}
+	created, err := c.Create(ctx, NewQuote{Author: "Seneca", Quote: "Tagged", Tags: []string{"stoic"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := stream.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Type != EventCreated || e.QuoteID != created.ID || e.Quote.Quote != "Tagged" || stream.LastEventID() != e.ID {
+		t.Errorf("unexpected event %+v", e)
+	}
+
+	if err := c.Delete(ctx, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	resumed, err := c.Events(ctx, EventOptions{LastEventID: e.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resumed.Close()
+	if e, err := resumed.Next(); err != nil || e.Type != EventDeleted || e.QuoteID != created.ID {
+		t.Errorf("expected the missed delete to be replayed, got %+v %v", e, err)
+	}
+}
+
+// flaky answers the first failures requests with status, then passes
+// requests on to next.
+type flaky struct {
+	next     http.Handler
+	status   int
+	failures int32
+	calls    atomic.Int32
+	apiKey   atomic.Value
+}
+
+func (f *flaky) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.apiKey.Store(r.Header.Get("Authorization"))
+	if f.calls.Add(1) <= f.failures {
+		w.Header().Set("Retry-After", "0")
+		http.Error(w, "try again", f.status)
+		return
+	}
+	f.next.ServeHTTP(w, r)
+}
+
+func TestRetries(t *testing.T) {
+	ctx := context.Background()
+
+	tt := []struct {
+		name     string
+		status   int
+		failures int32
+		create   bool
+		calls    int32
+		err      error
+	}{
+		{name: "GET is retried on 5xx", status: http.StatusInternalServerError, failures: 2, calls: 3},
+		{name: "Retries give up", status: http.StatusBadGateway, failures: 5, calls: 3, err: ErrServer},
+		{name: "POST is not retried on 500", status: http.StatusInternalServerError, failures: 1, create: true, calls: 1, err: ErrServer},
+		{name: "POST is retried on 429", status: http.StatusTooManyRequests, failures: 2, create: true, calls: 3},
+		{name: "Client errors are not retried", status: http.StatusBadRequest, failures: 1, calls: 1, err: ErrBadRequest},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &flaky{next: newTestAPI(t), status: tc.status, failures: tc.failures}
+			c := newTestClient(t, f, WithAPIKey("secret"))
+
+			var err error
+			if tc.create {
+				_, err = c.Create(ctx, NewQuote{Author: "A", Quote: "Q"})
+			} else {
+				_, err = c.List(ctx, ListOptions{})
+			}
+
+			if tc.err == nil && err != nil || tc.err != nil && !errors.Is(err, tc.err) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+			if n := f.calls.Load(); n != tc.calls {
+				t.Errorf("expected %d calls, got %d", tc.calls, n)
+			}
+			if auth := f.apiKey.Load(); auth != "Bearer secret" {
+				t.Errorf("expected the API key on every attempt, got %v", auth)
+			}
+		})
+	}
+
+	t.Run("Backoff stops with the context", func(t *testing.T) {
+		f := &flaky{next: newTestAPI(t), status: http.StatusServiceUnavailable, failures: 10}
+		c := newTestClient(t, f, WithRetry(5, time.Hour))
+
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		if _, err := c.Random(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected the deadline to end retries, got %v", err)
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	for _, u := range []string{"", "localhost:8080", "ftp://host", "http://"} {
+		if _, err := New(u); err == nil {
+			t.Errorf("expected %q to be rejected", u)
+		}
+	}
+
+	c, err := New("http://localhost:8080/")
+	if err != nil || !strings.HasSuffix(c.baseURL.String(), ":8080") {
+		t.Errorf("unexpected client %v %v", c, err)
+	}
+}
@@ -0,0 +1,105 @@
+package quoteclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Permissions an API key can be granted on its collection.
+const (
+	PermissionRead  = "read"
+	PermissionWrite = "write"
+)
+
+// Collection is an isolated set of quotes with its own IDs and limit.
+type Collection struct {
+	Name        string    `json:"name"`
+	QuotesLimit int       `json:"quotes_limit"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewCollection is the payload of CreateCollection, a zero QuotesLimit
+// takes the server default.
+type NewCollection struct {
+	Name        string `json:"name"`
+	QuotesLimit int    `json:"quotes_limit,omitempty"`
+}
+
+// APIKey grants access to one collection. Key is only set in the result
+// of CreateAPIKey.
+type APIKey struct {
+	ID          int       `json:"id"`
+	Collection  string    `json:"collection"`
+	Name        string    `json:"name,omitempty"`
+	Permissions []string  `json:"permissions"`
+	Key         string    `json:"key,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type NewAPIKey struct {
+	Name        string   `json:"name,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+// Collection returns a client whose quote methods (Create, Get, List, All,
+// ByAuthor, Random and Delete) and review methods (Submit, Pending, Approve
+// and Reject) work on the named collection. Events, feeds and images are
+// only served for the default collection.
+func (c *Client) Collection(name string) *Client {
+	scoped := *c
+	scoped.quotesPath = collectionPath(name) + "/quotes"
+	scoped.moderationPath = collectionPath(name) + "/moderation"
+	return &scoped
+}
+
+// CreateCollection and the other collection and API key methods require
+// the admin key, see WithAPIKey.
+func (c *Client) CreateCollection(ctx context.Context, in NewCollection) (*Collection, error) {
+	var created Collection
+	if _, err := c.do(ctx, http.MethodPost, "/collections", nil, in, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) ListCollections(ctx context.Context) ([]Collection, error) {
+	var collections []Collection
+	if _, err := c.do(ctx, http.MethodGet, "/collections", nil, nil, &collections); err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
+
+// DeleteCollection deletes the collection with its quotes and API keys.
+func (c *Client) DeleteCollection(ctx context.Context, name string) error {
+	_, err := c.do(ctx, http.MethodDelete, collectionPath(name), nil, nil, nil)
+	return err
+}
+
+func (c *Client) CreateAPIKey(ctx context.Context, collection string, in NewAPIKey) (*APIKey, error) {
+	var created APIKey
+	if _, err := c.do(ctx, http.MethodPost, collectionPath(collection)+"/keys", nil, in, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) ListAPIKeys(ctx context.Context, collection string) ([]APIKey, error) {
+	var keys []APIKey
+	if _, err := c.do(ctx, http.MethodGet, collectionPath(collection)+"/keys", nil, nil, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (c *Client) DeleteAPIKey(ctx context.Context, collection string, id int) error {
+	_, err := c.do(ctx, http.MethodDelete, collectionPath(collection)+"/keys/"+strconv.Itoa(id), nil, nil, nil)
+	return err
+}
+
+// collectionPath is unescaped, the request URL is built from url.URL.Path.
+func collectionPath(name string) string {
+	return "/collections/" + name
+}
@@ -0,0 +1,122 @@
+package quoteclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const maxErrorBody = 64 << 10
+
+// Stable problem codes sent by the server in Error.Code.
+const (
+	CodeInvalidPayload  = "invalid_payload"
+	CodeInvalidID       = "invalid_id"
+	CodeMissingParam    = "missing_parameter"
+	CodeInvalidParam    = "invalid_parameter"
+	CodePayloadTooLarge = "payload_too_large"
+	CodeValidation      = "validation_failed"
+	CodeNotFound        = "not_found"
+	CodeConflict        = "conflict"
+	CodeUnauthorized    = "unauthorized"
+	CodeForbidden       = "forbidden"
+	CodeTimeout         = "timeout"
+	CodeCanceled        = "canceled"
+	CodeUnavailable     = "unavailable"
+	CodeInternal        = "internal_error"
+)
+
+// Sentinels for errors.Is on *Error, matched by the response status.
+var (
+	ErrBadRequest      = errors.New("bad request")
+	ErrValidation      = errors.New("validation failed")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrForbidden       = errors.New("forbidden")
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrPayloadTooLarge = errors.New("payload too large")
+	ErrRateLimited     = errors.New("rate limited")
+	ErrServer          = errors.New("server error")
+	ErrUnavailable     = errors.New("service unavailable")
+	ErrTimeout         = errors.New("server timeout")
+)
+
+// Error is a non-2xx response. Fields mirror the RFC 7807 problem the
+// server sends, StatusCode and Title are filled from the status line when
+// the body is not a problem.
+type Error struct {
+	StatusCode int          `json:"status"`
+	Type       string       `json:"type"`
+	Title      string       `json:"title"`
+	Detail     string       `json:"detail"`
+	Code       string       `json:"code"`
+	RequestID  string       `json:"request_id"`
+	Errors     []FieldError `json:"errors"`
+}
+
+// FieldError describes why a single input field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func newError(resp *http.Response) *Error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+	e := &Error{}
+	if err := json.Unmarshal(body, e); err != nil || e.Code == "" {
+		e = &Error{Detail: strings.TrimSpace(string(body))}
+	}
+	e.StatusCode = resp.StatusCode
+	if e.Title == "" {
+		e.Title = http.StatusText(resp.StatusCode)
+	}
+	if e.RequestID == "" {
+		e.RequestID = resp.Header.Get("X-Request-ID")
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s", e.StatusCode, e.Title)
+	if e.Detail != "" {
+		b.WriteString(": " + e.Detail)
+	}
+	for _, f := range e.Errors {
+		fmt.Fprintf(&b, "; %s: %s", f.Field, f.Message)
+	}
+	return b.String()
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrValidation:
+		return e.Code == CodeValidation
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrPayloadTooLarge:
+		return e.StatusCode == http.StatusRequestEntityTooLarge
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= 500
+	case ErrUnavailable:
+		return e.StatusCode == http.StatusServiceUnavailable
+	case ErrTimeout:
+		return e.StatusCode == http.StatusGatewayTimeout
+	}
+	return false
+}
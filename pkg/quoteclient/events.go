@@ -0,0 +1,131 @@
+package quoteclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event types of the change feed. EventReset means events were missed
+// while disconnected and the collection should be read again.
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+	EventEvicted = "evicted"
+	// EventPublished and EventExpired mark the start and the end of the
+	// publication window of a scheduled quote.
+	EventPublished = "published"
+	EventExpired   = "expired"
+	EventReset     = "reset"
+)
+
+type Event struct {
+	ID      uint64    `json:"id"`
+	Type    string    `json:"type"`
+	QuoteID int       `json:"quote_id"`
+	Quote   Quote     `json:"quote"`
+	Time    time.Time `json:"time"`
+}
+
+// EventOptions filter the change feed. LastEventID resumes a previous
+// stream, the server replays the events after it.
+type EventOptions struct {
+	Author      string
+	Tag         string
+	LastEventID uint64
+}
+
+// EventStream reads the server-sent change feed, it is not safe for
+// concurrent use.
+type EventStream struct {
+	body   io.ReadCloser
+	reader *bufio.Reader
+	lastID uint64
+}
+
+// Events subscribes to quote changes. Only connecting is retried, when the
+// stream ends resume it with the LastEventID of the old one.
+func (c *Client) Events(ctx context.Context, opts EventOptions) (*EventStream, error) {
+	v := url.Values{}
+	if opts.Author != "" {
+		v.Set("author", opts.Author)
+	}
+	if opts.Tag != "" {
+		v.Set("tag", opts.Tag)
+	}
+
+	header := http.Header{}
+	if opts.LastEventID > 0 {
+		header.Set("Last-Event-ID", strconv.FormatUint(opts.LastEventID, 10))
+	}
+
+	resp, err := c.send(ctx, request{method: http.MethodGet, path: "/quotes/events", query: v, header: header, accept: "text/event-stream"})
+	if err != nil {
+		return nil, err
+	}
+	return &EventStream{body: resp.Body, reader: bufio.NewReader(resp.Body), lastID: opts.LastEventID}, nil
+}
+
+// Next blocks until the next event, it returns io.EOF when the server ends
+// the stream and the context error once the context of Events is done.
+func (s *EventStream) Next() (Event, error) {
+	var (
+		eventType string
+		data      strings.Builder
+	)
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return Event{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if data.Len() == 0 {
+				continue
+			}
+
+			var e Event
+			if err := json.Unmarshal([]byte(data.String()), &e); err != nil {
+				return Event{}, fmt.Errorf("decode event: %w", err)
+			}
+			if eventType != "" {
+				e.Type = eventType
+			}
+			if e.ID > 0 {
+				s.lastID = e.ID
+			}
+			return e, nil
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		}
+	}
+}
+
+// LastEventID is the ID of the last event read, pass it in EventOptions to
+// resume after a disconnect.
+func (s *EventStream) LastEventID() uint64 {
+	return s.lastID
+}
+
+func (s *EventStream) Close() error {
+	return s.body.Close()
+}
@@ -0,0 +1,77 @@
+package quoteclient
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+type FeedFormat string
+
+const (
+	FeedRSS  FeedFormat = "rss"
+	FeedAtom FeedFormat = "atom"
+)
+
+// FeedOptions filter the feed of recent quotes.
+type FeedOptions struct {
+	Author string
+	Tag    string
+}
+
+// Feed returns the XML feed of the most recently added quotes.
+func (c *Client) Feed(ctx context.Context, format FeedFormat, opts FeedOptions) ([]byte, error) {
+	v := url.Values{}
+	if opts.Author != "" {
+		v.Set("author", opts.Author)
+	}
+	if opts.Tag != "" {
+		v.Set("tag", opts.Tag)
+	}
+
+	accept := "application/rss+xml"
+	if format == FeedAtom {
+		accept = "application/atom+xml"
+	}
+	return c.fetch(ctx, "/quotes/feed."+string(format), v, accept)
+}
+
+type ImageFormat string
+
+const (
+	ImageSVG ImageFormat = "svg"
+	ImagePNG ImageFormat = "png"
+)
+
+// ImageOptions control a quote card, zero values use the server defaults.
+type ImageOptions struct {
+	Width  int
+	Height int
+	// Theme is light or dark.
+	Theme string
+	// Font is sans, italic or mono.
+	Font string
+}
+
+// Image renders the quote with the given ID as a card.
+func (c *Client) Image(ctx context.Context, id int, format ImageFormat, opts ImageOptions) ([]byte, error) {
+	v := url.Values{}
+	if opts.Width > 0 {
+		v.Set("width", strconv.Itoa(opts.Width))
+	}
+	if opts.Height > 0 {
+		v.Set("height", strconv.Itoa(opts.Height))
+	}
+	if opts.Theme != "" {
+		v.Set("theme", opts.Theme)
+	}
+	if opts.Font != "" {
+		v.Set("font", opts.Font)
+	}
+
+	accept := "image/png"
+	if format == ImageSVG {
+		accept = "image/svg+xml"
+	}
+	return c.fetch(ctx, "/quotes/"+strconv.Itoa(id)+"/image."+string(format), v, accept)
+}
@@ -0,0 +1,79 @@
+package quoteclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Moderation states of a quote.
+const (
+	StatusDraft     = "draft"
+	StatusPending   = "pending"
+	StatusPublished = "published"
+	StatusRejected  = "rejected"
+)
+
+// Submit sends a draft or rejected quote to review, ErrConflict for quotes
+// in any other state. Without moderation on the server it is published.
+func (c *Client) Submit(ctx context.Context, id int) (*Quote, error) {
+	var q Quote
+	if _, err := c.do(ctx, http.MethodPost, c.quotesPath+"/"+strconv.Itoa(id)+"/submit", nil, nil, &q); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// Pending returns one page of quotes awaiting review, oldest first. Only
+// Limit and After of opts apply. It needs the reviewer key, see
+// WithAPIKey, as do GetForReview, Approve and Reject.
+func (c *Client) Pending(ctx context.Context, opts ListOptions) (*Page, error) {
+	v := url.Values{}
+	if opts.Limit > 0 {
+		v.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.After != "" {
+		v.Set("after", opts.After)
+	}
+
+	var page Page
+	resp, err := c.do(ctx, http.MethodGet, c.moderationPath+"/queue", v, nil, &page.Quotes)
+	if err != nil {
+		return nil, err
+	}
+	page.Next = nextCursor(resp.Header.Get("Link"))
+	return &page, nil
+}
+
+// GetForReview returns a quote whatever its status, Get only finds
+// published ones.
+func (c *Client) GetForReview(ctx context.Context, id int) (*Quote, error) {
+	var q Quote
+	if _, err := c.do(ctx, http.MethodGet, c.moderationPath+"/quotes/"+strconv.Itoa(id), nil, nil, &q); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// Approve publishes a pending quote.
+func (c *Client) Approve(ctx context.Context, id int) (*Quote, error) {
+	var q Quote
+	if _, err := c.do(ctx, http.MethodPost, c.moderationPath+"/quotes/"+strconv.Itoa(id)+"/approve", nil, nil, &q); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// Reject returns a pending quote to its author with reason.
+func (c *Client) Reject(ctx context.Context, id int, reason string) (*Quote, error) {
+	in := struct {
+		Reason string `json:"reason"`
+	}{reason}
+
+	var q Quote
+	if _, err := c.do(ctx, http.MethodPost, c.moderationPath+"/quotes/"+strconv.Itoa(id)+"/reject", nil, in, &q); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
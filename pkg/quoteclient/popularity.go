@@ -0,0 +1,99 @@
+package quoteclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Popularity aggregates the likes and ratings of a quote.
+type Popularity struct {
+	QuoteID       int     `json:"quote_id"`
+	Likes         int     `json:"likes"`
+	Ratings       int     `json:"ratings"`
+	AverageRating float64 `json:"average_rating"`
+	// Score is the time-decayed popularity, only set by Top.
+	Score float64 `json:"score,omitempty"`
+}
+
+type RankedQuote struct {
+	Quote      Quote      `json:"quote"`
+	Popularity Popularity `json:"popularity"`
+}
+
+// Like likes the quote on behalf of user, repeated likes count once.
+func (c *Client) Like(ctx context.Context, id int, user string) (*Popularity, error) {
+	return c.vote(ctx, http.MethodPut, id, "/like", user, nil)
+}
+
+func (c *Client) Unlike(ctx context.Context, id int, user string) (*Popularity, error) {
+	return c.vote(ctx, http.MethodDelete, id, "/like", user, nil)
+}
+
+// Rate rates the quote from 1 to 5 on behalf of user, replacing the
+// previous rating.
+func (c *Client) Rate(ctx context.Context, id int, user string, rating int) (*Popularity, error) {
+	return c.vote(ctx, http.MethodPut, id, "/rating", user, struct {
+		Rating int `json:"rating"`
+	}{rating})
+}
+
+func (c *Client) Unrate(ctx context.Context, id int, user string) (*Popularity, error) {
+	return c.vote(ctx, http.MethodDelete, id, "/rating", user, nil)
+}
+
+func (c *Client) Popularity(ctx context.Context, id int) (*Popularity, error) {
+	var p Popularity
+	if _, err := c.do(ctx, http.MethodGet, "/quotes/"+strconv.Itoa(id)+"/popularity", nil, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Top returns up to limit of the most popular quotes, the server default
+// when limit is 0.
+func (c *Client) Top(ctx context.Context, limit int) ([]RankedQuote, error) {
+	v := url.Values{}
+	if limit > 0 {
+		v.Set("limit", strconv.Itoa(limit))
+	}
+
+	var ranked []RankedQuote
+	if _, err := c.do(ctx, http.MethodGet, "/quotes/top", v, nil, &ranked); err != nil {
+		return nil, err
+	}
+	return ranked, nil
+}
+
+// RandomWeighted returns a random quote, highly rated ones more likely.
+func (c *Client) RandomWeighted(ctx context.Context) (*Quote, error) {
+	var q Quote
+	if _, err := c.do(ctx, http.MethodGet, c.quotesPath+"/random", url.Values{"weighted": {"true"}}, nil, &q); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// vote sends a like or rating change, only served for the main collection.
+func (c *Client) vote(ctx context.Context, method string, id int, path, user string, in any) (*Popularity, error) {
+	resp, err := c.send(ctx, request{
+		method: method,
+		path:   "/quotes/" + strconv.Itoa(id) + path,
+		header: http.Header{"X-User-Id": {user}},
+		body:   in,
+		accept: "application/json",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var p Popularity
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return &p, nil
+}
@@ -0,0 +1,231 @@
+package quoteclient
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Quote struct {
+	ID        int       `json:"id"`
+	Author    string    `json:"author"`
+	Quote     string    `json:"quote"`
+	Tags      []string  `json:"tags,omitempty"`
+	Lang      string    `json:"lang,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+	PublishAt time.Time `json:"publish_at,omitzero"`
+	ExpireAt  time.Time `json:"expire_at,omitzero"`
+
+	Translations []Translation `json:"translations,omitempty"`
+	// TranslatedFrom and Translator are set when RandomIn serves a
+	// translation instead of the original text.
+	TranslatedFrom string `json:"translated_from,omitempty"`
+	Translator     string `json:"translator,omitempty"`
+
+	Status       string    `json:"status,omitempty"`
+	RejectReason string    `json:"reject_reason,omitempty"`
+	ReviewedAt   time.Time `json:"reviewed_at,omitzero"`
+	// Flags names the content filter rules that held the quote for review.
+	Flags []string `json:"flags,omitempty"`
+}
+
+type Translation struct {
+	Lang       string    `json:"lang"`
+	Quote      string    `json:"quote"`
+	Translator string    `json:"translator,omitempty"`
+	CreatedAt  time.Time `json:"created_at,omitzero"`
+}
+
+// NewQuote is the payload of Create, the server assigns the ID and times.
+type NewQuote struct {
+	Author string   `json:"author"`
+	Quote  string   `json:"quote"`
+	Tags   []string `json:"tags,omitempty"`
+	// Lang is an ISO 639-1 code, detected by the server when empty.
+	Lang string `json:"lang,omitempty"`
+	// Status is empty or StatusDraft, which keeps the quote unpublished
+	// until it is submitted.
+	Status string `json:"status,omitempty"`
+	// PublishAt and ExpireAt, when set, limit the time the quote is served.
+	PublishAt time.Time `json:"publish_at,omitzero"`
+	ExpireAt  time.Time `json:"expire_at,omitzero"`
+}
+
+// ListOptions select one page of quotes ordered by ID.
+type ListOptions struct {
+	// Limit is the page size, the server default when 0.
+	Limit int
+	// After is the cursor of the previous page, Page.Next.
+	After string
+	// Query matches a case-insensitive substring of the quote or author.
+	Query string
+	Tag   string
+	// Lang is an ISO 639-1 code.
+	Lang string
+}
+
+func (o ListOptions) values() url.Values {
+	// after is always sent, without paging parameters the server returns
+	// every quote at once.
+	v := url.Values{"after": {o.After}}
+	if o.Limit > 0 {
+		v.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Query != "" {
+		v.Set("q", o.Query)
+	}
+	if o.Tag != "" {
+		v.Set("tag", o.Tag)
+	}
+	if o.Lang != "" {
+		v.Set("lang", o.Lang)
+	}
+	return v
+}
+
+type Page struct {
+	Quotes []Quote
+	// Next is the cursor of the following page, empty on the last page.
+	Next string
+}
+
+func (c *Client) Create(ctx context.Context, q NewQuote) (*Quote, error) {
+	var created Quote
+	if _, err := c.do(ctx, http.MethodPost, c.quotesPath, nil, q, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) Get(ctx context.Context, id int) (*Quote, error) {
+	var q Quote
+	if _, err := c.do(ctx, http.MethodGet, c.quotesPath+"/"+strconv.Itoa(id), nil, nil, &q); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// List returns one page of quotes.
+func (c *Client) List(ctx context.Context, opts ListOptions) (*Page, error) {
+	var page Page
+	resp, err := c.do(ctx, http.MethodGet, c.quotesPath, opts.values(), nil, &page.Quotes)
+	if err != nil {
+		return nil, err
+	}
+	page.Next = nextCursor(resp.Header.Get("Link"))
+	return &page, nil
+}
+
+// All iterates over every quote matching opts, starting at opts.After.
+// Pages are fetched as the loop reaches them; an error ends the iteration
+// and is yielded with a zero Quote.
+func (c *Client) All(ctx context.Context, opts ListOptions) iter.Seq2[Quote, error] {
+	return func(yield func(Quote, error) bool) {
+		for {
+			page, err := c.List(ctx, opts)
+			if err != nil {
+				yield(Quote{}, err)
+				return
+			}
+			for _, q := range page.Quotes {
+				if !yield(q, nil) {
+					return
+				}
+			}
+			if page.Next == "" {
+				return
+			}
+			opts.After = page.Next
+		}
+	}
+}
+
+// ByAuthor returns all quotes of an author, matched case-insensitively.
+func (c *Client) ByAuthor(ctx context.Context, author string) ([]Quote, error) {
+	var quotes []Quote
+	if _, err := c.do(ctx, http.MethodGet, c.quotesPath, url.Values{"author": {author}}, nil, &quotes); err != nil {
+		return nil, err
+	}
+	return quotes, nil
+}
+
+func (c *Client) Random(ctx context.Context) (*Quote, error) {
+	var q Quote
+	if _, err := c.do(ctx, http.MethodGet, c.quotesPath+"/random", nil, nil, &q); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// RandomIn returns a random quote in the language with the given ISO 639-1
+// code, ErrNotFound when there is none. Quotes translated into the language
+// are returned with the translated text.
+func (c *Client) RandomIn(ctx context.Context, lang string) (*Quote, error) {
+	var q Quote
+	if _, err := c.do(ctx, http.MethodGet, c.quotesPath+"/random", url.Values{"lang": {lang}}, nil, &q); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+func (c *Client) Delete(ctx context.Context, id int) error {
+	_, err := c.do(ctx, http.MethodDelete, c.quotesPath+"/"+strconv.Itoa(id), nil, nil, nil)
+	return err
+}
+
+// AddTranslation attaches a translation to the quote with the given ID,
+// ErrConflict when it already has one into t.Lang.
+func (c *Client) AddTranslation(ctx context.Context, id int, t Translation) (*Translation, error) {
+	in := struct {
+		Lang       string `json:"lang"`
+		Quote      string `json:"quote"`
+		Translator string `json:"translator,omitempty"`
+	}{t.Lang, t.Quote, t.Translator}
+
+	var created Translation
+	if _, err := c.do(ctx, http.MethodPost, c.translationsPath(id), nil, in, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) Translations(ctx context.Context, id int) ([]Translation, error) {
+	var translations []Translation
+	if _, err := c.do(ctx, http.MethodGet, c.translationsPath(id), nil, nil, &translations); err != nil {
+		return nil, err
+	}
+	return translations, nil
+}
+
+func (c *Client) DeleteTranslation(ctx context.Context, id int, lang string) error {
+	_, err := c.do(ctx, http.MethodDelete, c.translationsPath(id)+"/"+lang, nil, nil, nil)
+	return err
+}
+
+// translationsPath is only served for the main collection.
+func (c *Client) translationsPath(id int) string {
+	return "/quotes/" + strconv.Itoa(id) + "/translations"
+}
+
+// nextCursor extracts the after parameter of the rel="next" target of a
+// Link header.
+func nextCursor(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+		if !ok || !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+
+		u, err := url.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+		if err != nil {
+			return ""
+		}
+		return u.Query().Get("after")
+	}
+	return ""
+}
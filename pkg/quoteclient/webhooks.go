@@ -0,0 +1,98 @@
+package quoteclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Webhook is a subscription to quote change events, see Event for the
+// delivered types.
+type Webhook struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events,omitempty"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookInput is the payload of CreateWebhook and UpdateWebhook. Empty
+// Events subscribes to every type. An empty Secret is generated on create
+// and kept on update, the secret is only returned by CreateWebhook.
+type WebhookInput struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+	Secret string   `json:"secret,omitempty"`
+}
+
+// DeadLetter is a delivery that failed after every retry.
+type DeadLetter struct {
+	ID         int             `json:"id"`
+	WebhookID  int             `json:"webhook_id"`
+	DeliveryID string          `json:"delivery_id"`
+	EventType  string          `json:"event_type"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempts   int             `json:"attempts"`
+	LastStatus int             `json:"last_status,omitempty"`
+	LastError  string          `json:"last_error"`
+	FailedAt   time.Time       `json:"failed_at"`
+}
+
+func (c *Client) CreateWebhook(ctx context.Context, in WebhookInput) (*Webhook, error) {
+	var w Webhook
+	if _, err := c.do(ctx, http.MethodPost, "/webhooks", nil, in, &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	if _, err := c.do(ctx, http.MethodGet, "/webhooks", nil, nil, &webhooks); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (c *Client) GetWebhook(ctx context.Context, id int) (*Webhook, error) {
+	var w Webhook
+	if _, err := c.do(ctx, http.MethodGet, "/webhooks/"+strconv.Itoa(id), nil, nil, &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// UpdateWebhook replaces the URL, events and secret of a webhook.
+func (c *Client) UpdateWebhook(ctx context.Context, id int, in WebhookInput) (*Webhook, error) {
+	var w Webhook
+	if _, err := c.do(ctx, http.MethodPut, "/webhooks/"+strconv.Itoa(id), nil, in, &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id int) error {
+	_, err := c.do(ctx, http.MethodDelete, "/webhooks/"+strconv.Itoa(id), nil, nil, nil)
+	return err
+}
+
+func (c *Client) ListDeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	var deadLetters []DeadLetter
+	if _, err := c.do(ctx, http.MethodGet, "/webhooks/dead-letters", nil, nil, &deadLetters); err != nil {
+		return nil, err
+	}
+	return deadLetters, nil
+}
+
+// ReplayDeadLetter queues the failed delivery again with the same payload.
+func (c *Client) ReplayDeadLetter(ctx context.Context, id int) error {
+	_, err := c.do(ctx, http.MethodPost, "/webhooks/dead-letters/"+strconv.Itoa(id)+"/replay", nil, nil, nil)
+	return err
+}
+
+func (c *Client) DeleteDeadLetter(ctx context.Context, id int) error {
+	_, err := c.do(ctx, http.MethodDelete, "/webhooks/dead-letters/"+strconv.Itoa(id), nil, nil, nil)
+	return err
+}